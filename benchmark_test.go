@@ -29,6 +29,17 @@ func BenchmarkGookitSlogNegative(b *testing.B) {
 	}
 }
 
+func BenchmarkNopLogger(b *testing.B) {
+	logger := slog.NewNopLogger()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("rate", "15", "low", 16, "high", 123.2, msg)
+	}
+}
+
 func TestLogger_Info_Negative(t *testing.T) {
 	logger := slog.NewWithHandlers(
 		handler.NewIOWriter(io.Discard, []slog.Level{slog.ErrorLevel}),
@@ -82,3 +93,103 @@ func TestLogger_Info_Positive(t *testing.T) {
 
 	logger.Info("rate", "15", "low", 16, "high", 123.2, msg)
 }
+
+// the records are pooled and reused after all handlers finished.
+//
+//	go test -run=none -bench=BenchmarkLogger_recordPool -benchmem
+//
+// before reuse the record maps:
+//
+//	Info        2445 ns/op   520 B/op   8 allocs/op
+//	WithFields  2916 ns/op  1168 B/op  14 allocs/op
+//	WithData    3216 ns/op  1240 B/op  17 allocs/op
+//
+// after:
+//
+//	Info        1543 ns/op   472 B/op   7 allocs/op
+//	WithFields  2070 ns/op  1024 B/op  11 allocs/op
+//	WithData    2147 ns/op  1048 B/op  13 allocs/op
+func BenchmarkLogger_recordPool(b *testing.B) {
+	logger := slog.NewWithHandlers(
+		handler.NewIOWriter(io.Discard, slog.NormalLevels),
+	)
+
+	b.Run("Info", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.Info(msg)
+		}
+	})
+
+	b.Run("WithFields", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.WithFields(slog.M{"key": "value"}).Info(msg)
+		}
+	})
+
+	b.Run("WithData", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.WithData(slog.M{"key": "value"}).Info(msg)
+		}
+	})
+}
+
+// go test -run=none -bench=BenchmarkRecord_chainFields -benchmem
+//
+//	WithField   2947 ns/op  2208 B/op  17 allocs/op
+//	Build       2256 ns/op  1120 B/op  12 allocs/op
+func BenchmarkRecord_chainFields(b *testing.B) {
+	logger := slog.NewWithHandlers(
+		handler.NewIOWriter(io.Discard, slog.NormalLevels),
+	)
+
+	b.Run("WithField", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.WithField("a", 1).WithField("b", 2).WithField("c", 3).Info(msg)
+		}
+	})
+
+	b.Run("Build", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.Build(slog.InfoLevel).Int("a", 1).Int("b", 2).Int("c", 3).Msg(msg)
+		}
+	})
+}
+
+// go test -run=none -bench=BenchmarkLogger_LogBatch -benchmem
+//
+// log 100 messages with same fields:
+//
+//	Loop    223955 ns/op  105611 B/op  1100 allocs/op
+//	LogAll  155826 ns/op   45381 B/op   603 allocs/op
+func BenchmarkLogger_LogBatch(b *testing.B) {
+	logger := slog.NewWithHandlers(
+		handler.NewIOWriter(io.Discard, slog.NormalLevels),
+	)
+
+	fields := slog.M{"batch": 1, "user": "inhere"}
+	msgs := make([]string, 100)
+	for i := range msgs {
+		msgs[i] = msg
+	}
+
+	b.Run("Loop", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, m := range msgs {
+				logger.WithFields(fields).Info(m)
+			}
+		}
+	})
+
+	b.Run("LogAll", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.WithFields(fields).LogAll(slog.InfoLevel, msgs)
+		}
+	})
+}
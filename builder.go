@@ -0,0 +1,97 @@
+package slog
+
+import "time"
+
+// Builder accumulate the typed fields, and materialize the record only on log.
+//
+// Unlike the chained Record.WithField(), it does not copy the record and fields map on each call.
+//
+// Usage:
+//
+//	logger.Build(slog.InfoLevel).Str("user", "inhere").Int("age", 23).Msg("message")
+//
+// NOTICE: the builder should not be reused after Msg() or Msgf().
+type Builder struct {
+	r     *Record
+	level Level
+	// the record is created for the builder, no need to copy on log
+	owned  bool
+	fields []Field
+}
+
+// Build new Builder with the level. the record will be copied on log, so it can be reused.
+func (r *Record) Build(level Level) *Builder {
+	return &Builder{r: r, level: level, fields: make([]Field, 0, 8)}
+}
+
+// Build new Builder with the level. see Builder
+func (l *Logger) Build(level Level) *Builder {
+	b := l.newRecord().Build(level)
+	b.owned = true
+	return b
+}
+
+// With add the typed fields
+func (b *Builder) With(fields ...Field) *Builder {
+	b.fields = append(b.fields, fields...)
+	return b
+}
+
+// Any add a field with any value
+func (b *Builder) Any(key string, val any) *Builder {
+	b.fields = append(b.fields, Field{Key: key, Value: val})
+	return b
+}
+
+// Str add a string field
+func (b *Builder) Str(key, val string) *Builder { return b.Any(key, val) }
+
+// Int add an int field
+func (b *Builder) Int(key string, val int) *Builder { return b.Any(key, val) }
+
+// Int64 add an int64 field
+func (b *Builder) Int64(key string, val int64) *Builder { return b.Any(key, val) }
+
+// Uint64 add an uint64 field
+func (b *Builder) Uint64(key string, val uint64) *Builder { return b.Any(key, val) }
+
+// Float64 add a float64 field
+func (b *Builder) Float64(key string, val float64) *Builder { return b.Any(key, val) }
+
+// Bool add a bool field
+func (b *Builder) Bool(key string, val bool) *Builder { return b.Any(key, val) }
+
+// Time add a time.Time field
+func (b *Builder) Time(key string, val time.Time) *Builder { return b.Any(key, val) }
+
+// Dur add a time.Duration field
+func (b *Builder) Dur(key string, val time.Duration) *Builder { return b.Any(key, val) }
+
+// Err add an error field, will be skipped on the err is nil.
+func (b *Builder) Err(err error) *Builder {
+	b.fields = append(b.fields, Err(err))
+	return b
+}
+
+// Msg log the message with the fields
+func (b *Builder) Msg(args ...any) {
+	b.record().log(b.level, args)
+}
+
+// Msgf log the formatted message with the fields
+func (b *Builder) Msgf(format string, args ...any) {
+	b.record().logf(b.level, format, args)
+}
+
+// materialize the record with the fields
+func (b *Builder) record() *Record {
+	r := b.r
+	if !b.owned {
+		r = r.Copy()
+	}
+
+	if len(b.fields) > 0 {
+		r.addTypedFields(b.fields)
+	}
+	return r
+}
@@ -2,6 +2,8 @@ package slog
 
 import (
 	"errors"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -124,6 +126,9 @@ const (
 	// CallerFlagFcName only report func name.
 	// eg: "TestLogger_ReportCaller"
 	CallerFlagFcName
+	// CallerFlagPkgFile report the parent dir name + filename + line.
+	// eg: "slog/logger_test.go:48"
+	CallerFlagPkgFile
 )
 
 var (
@@ -152,6 +157,18 @@ var (
 	FieldKeyError = "error"
 	// FieldKeyExtra key name
 	FieldKeyExtra = "extra"
+	// FieldKeyDuration key name for the elapsed time by Record.Timed
+	FieldKeyDuration = "duration"
+	// FieldKeySampleKey key name in Record.Extra for the sampling key. see Record.WithSampleKey
+	FieldKeySampleKey = "sample_key"
+	// FieldKeyFields the TextFormatter template key for render the Record.Fields that are not in the template.
+	FieldKeyFields = "fields"
+
+	// FieldKeyStacktrace key name for the AddStackTrace processor
+	FieldKeyStacktrace = "stacktrace"
+
+	// FieldKeyGID key name for the AddGID processor
+	FieldKeyGID = "gid"
 
 	// FieldKeyChannel name
 	FieldKeyChannel = "channel"
@@ -159,6 +176,16 @@ var (
 	FieldKeyMessage = "message"
 )
 
+// special time format values for the formatter TimeFormat, render the datetime as unix timestamp number.
+//
+// Other values are used as the Go time layout. eg: time.RFC3339, time.RFC3339Nano
+const (
+	// TimeFormatUnix unix seconds. eg: 1672531200
+	TimeFormatUnix = "unix"
+	// TimeFormatUnixMilli unix milliseconds. eg: 1672531200123
+	TimeFormatUnixMilli = "unixMilli"
+)
+
 var (
 	// DefaultChannelName for log record
 	DefaultChannelName = "application"
@@ -171,8 +198,13 @@ var (
 	// DoNothingOnPanic handle func. use for testing.
 	DoNothingOnPanic = func(v any) {}
 
-	// DefaultPanicFn handle func
+	// DefaultExitFn handle func
+	DefaultExitFn = os.Exit
+	// DefaultPanicFn handle func. will panic with the message on the v is *Record
 	DefaultPanicFn = func(v any) {
+		if r, ok := v.(*Record); ok {
+			panic(r.Message)
+		}
 		panic(v)
 	}
 	// DefaultClockFn create func
@@ -220,6 +252,79 @@ var (
 	emptyTime = time.Time{}
 )
 
+// the registered custom levels, lower name -> level. see RegisterLevel()
+var customLevels = make(map[string]Level)
+
+// RegisterLevel register a custom level with the name. eg: a "AUDIT" level between NoticeLevel and WarnLevel:
+//
+//	const AuditLevel slog.Level = 450
+//	slog.RegisterLevel(AuditLevel, "AUDIT")
+//
+// The level is added to LevelNames, AllLevels, and can be parsed by Name2Level(), the name is case-insensitive.
+// it is ordered by the value on ShouldHandling(), the smaller value is more severe.
+//
+// The built-in levels use the multiples of 100 in 100-800, please use the values between them(eg: 450),
+// or greater than 800 for the more verbose levels. the value 1-9 is the short numeric value on parse.
+//
+// NOTICE: it is not safe for concurrent use, should be called on init, before logging.
+// will panic on the level value or name is used by the built-in levels.
+func RegisterLevel(level Level, name string) {
+	name = strings.TrimSpace(name)
+	if level < 10 || name == "" {
+		panic("slog: invalid custom level " + strconv.Itoa(int(level)) + " " + name)
+	}
+	if level%100 == 0 && level <= TraceLevel {
+		panic("slog: the level value is used by the built-in level " + LevelName(level))
+	}
+
+	// the built-in names and aliases can not be used
+	lowerName := strings.ToLower(name)
+	if _, err := Name2Level(lowerName); err == nil {
+		if _, ok := customLevels[lowerName]; !ok {
+			panic("slog: the level name is used by the built-in level " + name)
+		}
+	}
+
+	// remove the old name on re-register
+	if old, ok := LevelNames[level]; ok {
+		delete(customLevels, strings.ToLower(old))
+	}
+	// remove the old value on re-register the name with new value
+	oldLv, ok := customLevels[lowerName]
+	if ok && oldLv != level {
+		delete(LevelNames, oldLv)
+		delete(lowerLevelNames, oldLv)
+	} else {
+		oldLv = level
+	}
+
+	customLevels[lowerName] = level
+	LevelNames[level] = strings.ToUpper(name)
+	lowerLevelNames[level] = lowerName
+
+	// build a new slice, the old AllLevels maybe in use. eg: the handler levels
+	all := make(Levels, 0, len(AllLevels)+1)
+	for _, lv := range AllLevels {
+		if lv != level && lv != oldLv {
+			all = append(all, lv)
+		}
+	}
+
+	// keep ordered by the value
+	idx := len(all)
+	for i, lv := range all {
+		if level < lv {
+			idx = i
+			break
+		}
+	}
+
+	all = append(all, 0)
+	copy(all[idx+1:], all[idx:])
+	all[idx] = level
+	AllLevels = all
+}
+
 // LevelName match
 func LevelName(l Level) string {
 	if n, ok := LevelNames[l]; ok {
@@ -237,12 +342,17 @@ func LevelByName(ln string) Level {
 	return l
 }
 
-// Name2Level convert name to level
+// Name2Level convert name to level. the name is case-insensitive.
+//
+// Support:
+//   - level names and aliases. eg: "warn", "WARNING", "err", "crit"
+//   - numeric level values. eg: "400", or the short value "4" (=400)
+//   - empty string will return InfoLevel
 func Name2Level(ln string) (Level, error) {
-	switch strings.ToLower(ln) {
+	switch strings.ToLower(strings.TrimSpace(ln)) {
 	case "panic":
 		return PanicLevel, nil
-	case "fatal":
+	case "fatal", "crit", "critical":
 		return FatalLevel, nil
 	case "err", "error":
 		return ErrorLevel, nil
@@ -257,6 +367,20 @@ func Name2Level(ln string) (Level, error) {
 	case "trace":
 		return TraceLevel, nil
 	}
+
+	if lv, ok := customLevels[strings.ToLower(strings.TrimSpace(ln))]; ok {
+		return lv, nil
+	}
+
+	// numeric level value
+	if n, err := strconv.ParseUint(strings.TrimSpace(ln), 10, 32); err == nil {
+		if n > 0 && n < 10 {
+			n *= 100
+		}
+		if _, ok := LevelNames[Level(n)]; ok {
+			return Level(n), nil
+		}
+	}
 	return 0, errors.New("invalid log level name: " + ln)
 }
 
@@ -299,6 +423,6 @@ func ResetExitHandlers(applyToStd bool) {
 	exitHandlers = make([]func(), 0)
 
 	if applyToStd {
-		std.ResetExitHandlers()
+		Std().ResetExitHandlers()
 	}
 }
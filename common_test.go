@@ -11,6 +11,7 @@ import (
 	"github.com/gookit/goutil/testutil/assert"
 	"github.com/gookit/gsr"
 	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
 )
 
 var (
@@ -76,6 +77,73 @@ func TestLevel_ShouldHandling(t *testing.T) {
 	assert.False(t, slog.DebugLevel.ShouldHandling(slog.TraceLevel))
 }
 
+func TestRegisterLevel(t *testing.T) {
+	t.Cleanup(slog.SnapshotLevels())
+	allNum := len(slog.AllLevels)
+
+	const auditLevel slog.Level = 440
+	slog.RegisterLevel(auditLevel, "Audit")
+
+	assert.Eq(t, "AUDIT", auditLevel.Name())
+	assert.Eq(t, "audit", auditLevel.LowerName())
+	for _, name := range []string{"audit", "AUDIT", "440"} {
+		level, err := slog.Name2Level(name)
+		assert.NoErr(t, err)
+		assert.Eq(t, auditLevel, level)
+	}
+
+	// ordered between the warn and notice
+	idx := -1
+	for i, lv := range slog.AllLevels {
+		if lv == auditLevel {
+			idx = i
+		}
+	}
+	assert.Eq(t, slog.WarnLevel, slog.AllLevels[idx-1])
+	assert.Eq(t, slog.NoticeLevel, slog.AllLevels[idx+1])
+
+	// re-register is allowed, the old AllLevels is not changed
+	oldAll := slog.AllLevels
+	slog.RegisterLevel(auditLevel, "audit")
+	assert.Len(t, slog.AllLevels, allNum+1)
+	assert.Eq(t, oldAll, slog.AllLevels)
+
+	const auditLevel2 slog.Level = 460
+	slog.RegisterLevel(auditLevel2, "audit")
+	assert.Len(t, slog.AllLevels, allNum+1)
+	assert.Len(t, oldAll, allNum+1)
+	assert.Contains(t, oldAll, auditLevel)
+	assert.NotContains(t, slog.AllLevels, auditLevel)
+	assert.Eq(t, "UNKNOWN", slog.LevelName(auditLevel))
+	lv, err := slog.Name2Level("audit")
+	assert.NoErr(t, err)
+	assert.Eq(t, auditLevel2, lv)
+	slog.RegisterLevel(auditLevel, "audit")
+
+	// filter by the level
+	buf := newBuffer()
+	l := slog.NewWithHandlers(handler.NewSimple(buf, slog.NoticeLevel))
+	l.Log(auditLevel, "audit message")
+	str := buf.StringReset()
+	assert.StrContains(t, str, "[AUDIT]")
+	assert.StrContains(t, str, "audit message")
+
+	l = slog.NewWithHandlers(handler.NewSimple(buf, slog.WarnLevel))
+	l.Log(auditLevel, "audit message")
+	assert.Empty(t, buf.StringReset())
+
+	// collision with the built-in levels
+	assert.Panics(t, func() {
+		slog.RegisterLevel(slog.InfoLevel, "my_info")
+	})
+	assert.Panics(t, func() {
+		slog.RegisterLevel(650, "warning")
+	})
+	assert.Panics(t, func() {
+		slog.RegisterLevel(5, "short")
+	})
+}
+
 func TestLevels_Contains(t *testing.T) {
 	assert.True(t, slog.DangerLevels.Contains(slog.ErrorLevel))
 	assert.False(t, slog.DangerLevels.Contains(slog.InfoLevel))
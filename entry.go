@@ -0,0 +1,133 @@
+package slog
+
+// Entry is a reusable child logger, it carries the fields across many log calls.
+//
+// The Logger.WithFields() returns a *Record, it is released after one log call.
+// The Entry is not, each log call creates a new record with the entry fields.
+//
+// Usage:
+//
+//	reqLog := logger.NewEntry(slog.M{"request_id": reqID})
+//	reqLog.Info("start handle")
+//	reqLog.WithField("user", uid).Info("user login")
+//	reqLog.Error("handle failed")
+type Entry struct {
+	logger *Logger
+	// the entry fields. it is read-only after created, the records take a copy of it.
+	fields M
+}
+
+// NewEntry create a reusable child logger with the fields. see Entry
+func (l *Logger) NewEntry(fields M) *Entry {
+	return &Entry{logger: l, fields: copyM(fields)}
+}
+
+// Logger get the logger of the entry
+func (e *Entry) Logger() *Logger { return e.logger }
+
+// Fields get a copy of the entry fields
+func (e *Entry) Fields() M { return copyM(e.fields) }
+
+// WithField new child entry with the field added
+func (e *Entry) WithField(name string, val any) *Entry {
+	return e.WithFields(M{name: val})
+}
+
+// WithFields new child entry with the fields merged, the new fields take precedence.
+func (e *Entry) WithFields(fields M) *Entry {
+	mp := make(M, len(e.fields)+len(fields))
+	for k, v := range e.fields {
+		mp[k] = v
+	}
+	for k, v := range fields {
+		mp[k] = v
+	}
+	return &Entry{logger: e.logger, fields: mp}
+}
+
+// Record new record with the entry fields. the record is released after log.
+func (e *Entry) Record() *Record {
+	r := e.logger.newRecord()
+	r.Fields = copyM(e.fields)
+	return r
+}
+
+func (e *Entry) log(level Level, args []any) {
+	if level > FatalLevel && !e.logger.shouldWrite(level) {
+		return
+	}
+
+	r := e.Record()
+	r.CallerSkip++
+	r.log(level, args)
+}
+
+func (e *Entry) logf(level Level, format string, args []any) {
+	if level > FatalLevel && !e.logger.shouldWrite(level) {
+		return
+	}
+
+	r := e.Record()
+	r.CallerSkip++
+	r.logf(level, format, args)
+}
+
+// Log a message with level
+func (e *Entry) Log(level Level, args ...any) { e.log(level, args) }
+
+// Logf a format message with level
+func (e *Entry) Logf(level Level, format string, args ...any) { e.logf(level, format, args) }
+
+// Print logs a message at level PrintLevel
+func (e *Entry) Print(args ...any) { e.log(PrintLevel, args) }
+
+// Printf logs a message at level PrintLevel
+func (e *Entry) Printf(format string, args ...any) { e.logf(PrintLevel, format, args) }
+
+// Trace logs a message at level Trace
+func (e *Entry) Trace(args ...any) { e.log(TraceLevel, args) }
+
+// Tracef logs a message at level Trace
+func (e *Entry) Tracef(format string, args ...any) { e.logf(TraceLevel, format, args) }
+
+// Debug logs a message at level Debug
+func (e *Entry) Debug(args ...any) { e.log(DebugLevel, args) }
+
+// Debugf logs a message at level Debug
+func (e *Entry) Debugf(format string, args ...any) { e.logf(DebugLevel, format, args) }
+
+// Info logs a message at level Info
+func (e *Entry) Info(args ...any) { e.log(InfoLevel, args) }
+
+// Infof logs a message at level Info
+func (e *Entry) Infof(format string, args ...any) { e.logf(InfoLevel, format, args) }
+
+// Notice logs a message at level Notice
+func (e *Entry) Notice(args ...any) { e.log(NoticeLevel, args) }
+
+// Noticef logs a message at level Notice
+func (e *Entry) Noticef(format string, args ...any) { e.logf(NoticeLevel, format, args) }
+
+// Warn logs a message at level Warn
+func (e *Entry) Warn(args ...any) { e.log(WarnLevel, args) }
+
+// Warnf logs a message at level Warn
+func (e *Entry) Warnf(format string, args ...any) { e.logf(WarnLevel, format, args) }
+
+// Error logs a message at level Error
+func (e *Entry) Error(args ...any) { e.log(ErrorLevel, args) }
+
+// Errorf logs a message at level Error
+func (e *Entry) Errorf(format string, args ...any) { e.logf(ErrorLevel, format, args) }
+
+// Fatal logs a message at level Fatal
+func (e *Entry) Fatal(args ...any) { e.log(FatalLevel, args) }
+
+// Fatalf logs a message at level Fatal
+func (e *Entry) Fatalf(format string, args ...any) { e.logf(FatalLevel, format, args) }
+
+// Panic logs a message at level Panic
+func (e *Entry) Panic(args ...any) { e.log(PanicLevel, args) }
+
+// Panicf logs a message at level Panic
+func (e *Entry) Panicf(format string, args ...any) { e.logf(PanicLevel, format, args) }
@@ -0,0 +1,79 @@
+package slog_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+func TestLogger_NewEntry(t *testing.T) {
+	w := newBuffer()
+	l := slog.NewWithConfig(func(l *slog.Logger) {
+		l.ReportCaller = true
+		l.CallerFlag = slog.CallerFlagFnLine
+		l.DoNothingOnPanicFatal()
+	})
+	h := handler.NewIOWriter(w, slog.AllLevels)
+	h.SetFormatter(slog.NewJSONFormatter(func(f *slog.JSONFormatter) {
+		f.Fields = []string{slog.FieldKeyMessage, slog.FieldKeyCaller}
+	}))
+	l.AddHandler(h)
+
+	fields := slog.M{"app": "order"}
+	e := l.NewEntry(fields)
+	assert.Eq(t, l, e.Logger())
+	fields["app"] = "changed"
+	assert.Eq(t, slog.M{"app": "order"}, e.Fields())
+
+	// reuse the entry
+	e.Info("message 1")
+	e.Warnf("message %d", 2)
+	e.WithField("user", "inhere").Error("message 3")
+	e.Log(slog.NoticeLevel, "message 4")
+
+	lines := strings.Split(strings.TrimSpace(w.StringReset()), "\n")
+	assert.Len(t, lines, 4)
+	for _, line := range lines {
+		assert.StrContains(t, line, `"app":"order"`)
+		assert.StrContains(t, line, `"caller":"entry_test.go:`)
+	}
+	assert.StrContains(t, lines[1], `"message":"message 2"`)
+	assert.StrContains(t, lines[2], `"user":"inhere"`)
+	assert.NotContains(t, lines[3], `"user"`)
+
+	// the processors modify the record fields, not the entry
+	l.AddProcessor(slog.ProcessorFunc(func(r *slog.Record) {
+		r.AddField("app", "by-processor")
+	}))
+	e.Info("message 5")
+	assert.StrContains(t, w.StringReset(), `"app":"by-processor"`)
+	assert.Eq(t, slog.M{"app": "order"}, e.Fields())
+
+	// child fields take precedence
+	child := e.WithFields(slog.M{"app": "child", "id": 1})
+	assert.Eq(t, slog.M{"app": "child", "id": 1}, child.Fields())
+	assert.Eq(t, slog.M{"app": "order"}, e.Fields())
+}
+
+func TestEntry_concurrent(t *testing.T) {
+	w := newBuffer()
+	l := slog.NewWithHandlers(handler.NewIOWriter(w, slog.AllLevels))
+	e := l.NewEntry(slog.M{"app": "order"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			e.WithField("idx", i).Info("message")
+			e.Info("message")
+		}(i)
+	}
+	wg.Wait()
+	s := w.String()
+	assert.Eq(t, 20, strings.Count(s, "message"), s)
+}
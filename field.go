@@ -0,0 +1,59 @@
+package slog
+
+import "time"
+
+// Field a typed key-value pair for the Record.Fields.
+//
+// Usage:
+//
+//	logger.With(slog.String("user", "inhere"), slog.Int("age", 23)).Info("message")
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Any create a field with any value
+func Any(key string, val any) Field { return Field{Key: key, Value: val} }
+
+// String create a string field
+func String(key, val string) Field { return Field{Key: key, Value: val} }
+
+// Int create an int field
+func Int(key string, val int) Field { return Field{Key: key, Value: val} }
+
+// Int64 create an int64 field
+func Int64(key string, val int64) Field { return Field{Key: key, Value: val} }
+
+// Uint64 create an uint64 field
+func Uint64(key string, val uint64) Field { return Field{Key: key, Value: val} }
+
+// Float64 create a float64 field
+func Float64(key string, val float64) Field { return Field{Key: key, Value: val} }
+
+// Bool create a bool field
+func Bool(key string, val bool) Field { return Field{Key: key, Value: val} }
+
+// Time create a time.Time field
+func Time(key string, val time.Time) Field { return Field{Key: key, Value: val} }
+
+// Duration create a time.Duration field
+func Duration(key string, val time.Duration) Field { return Field{Key: key, Value: val} }
+
+// Err create an error field, the key is FieldKeyError.
+// will be skipped on the err is nil.
+func Err(err error) Field {
+	if err == nil {
+		return Field{}
+	}
+	return Field{Key: FieldKeyError, Value: err}
+}
+
+// add the typed fields to the record fields. skip the field with empty key.
+func (r *Record) addTypedFields(fields []Field) {
+	mp := r.groupFields(len(fields))
+	for _, f := range fields {
+		if f.Key != "" {
+			mp[f.Key] = f.Value
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package slog
 
-import "runtime"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
 
 //
 // Formatter interface
@@ -28,6 +34,12 @@ type Formattable interface {
 	SetFormatter(Formatter)
 }
 
+// FormatterInheritor the handler can inherit the Logger default formatter. see Logger.SetFormatter()
+type FormatterInheritor interface {
+	// InheritFormatter use the formatter on the handler has no explicit formatter set.
+	InheritFormatter(Formatter)
+}
+
 // FormattableTrait alias of FormatterWrapper
 type FormattableTrait = FormatterWrapper
 
@@ -36,6 +48,8 @@ type FormattableTrait = FormatterWrapper
 type FormatterWrapper struct {
 	// if not set, default use the TextFormatter
 	formatter Formatter
+	// the formatter is set by SetFormatter(), will not be overridden by InheritFormatter()
+	explicit bool
 }
 
 // Formatter get formatter. if not set, will return TextFormatter
@@ -49,6 +63,14 @@ func (f *FormatterWrapper) Formatter() Formatter {
 // SetFormatter to handler
 func (f *FormatterWrapper) SetFormatter(formatter Formatter) {
 	f.formatter = formatter
+	f.explicit = formatter != nil
+}
+
+// InheritFormatter use the formatter on SetFormatter() is not called. see FormatterInheritor
+func (f *FormatterWrapper) InheritFormatter(formatter Formatter) {
+	if !f.explicit {
+		f.formatter = formatter
+	}
 }
 
 // Format log record to bytes
@@ -74,3 +96,229 @@ func AsJSONFormatter(f Formatter) *JSONFormatter {
 	}
 	panic("slog: cannot cast input as *JSONFormatter")
 }
+
+// DataMode how the formatters output the Record.Data and Record.Extra
+type DataMode uint8
+
+const (
+	// DataModeNamespace output the Record.Data, Record.Extra under the "data", "extra" keys. this is default mode.
+	DataModeNamespace DataMode = iota
+	// DataModeMerge merge the Record.Fields, Record.Data, Record.Extra to the top level.
+	//
+	// on key collision the precedence is: Fields > Data > Extra. the key collided with
+	// the standard fields will be prefixed by the source. eg: "data.level"
+	DataModeMerge
+)
+
+// ErrorDetail the verbosity of render the error values in Record.Fields
+type ErrorDetail uint8
+
+const (
+	// ErrorDetailMessage only render the error by Error() message. this is default.
+	ErrorDetailMessage ErrorDetail = iota
+	// ErrorDetailCause add the "<key>_cause" field, it is the messages of the wrapped errors chain by errors.Unwrap().
+	//
+	// eg: err = fmt.Errorf("load config: %w", err) will add: "error_cause": ["open app.yml: no such file"]
+	ErrorDetailCause
+	// ErrorDetailStack add the "<key>_stack" field on the error in the chain has stack trace(eg: github.com/pkg/errors),
+	// it is rendered by "%+v". also add the "<key>_cause" field.
+	ErrorDetailStack
+)
+
+// the suffixes of the error detail fields. eg: "error_cause", "error_stack"
+const (
+	errorCauseSuffix = "_cause"
+	errorStackSuffix = "_stack"
+)
+
+// add the error cause and stack fields to a new map by the detail. returns the fields if no field is added.
+// the causes are joined by "; " on joinCause=true, for render in text.
+func errorDetailFields(fields M, detail ErrorDetail, joinCause bool) M {
+	if detail == ErrorDetailMessage {
+		return fields
+	}
+
+	var nmp M
+	for k, v := range fields {
+		err, ok := v.(error)
+		if !ok || err == nil {
+			continue
+		}
+
+		causes := errorCauses(err)
+		var stack string
+		if detail >= ErrorDetailStack {
+			stack = errorStack(err)
+		}
+		if len(causes) == 0 && stack == "" {
+			continue
+		}
+
+		if nmp == nil {
+			nmp = make(M, len(fields)+2)
+			for k1, v1 := range fields {
+				nmp[k1] = v1
+			}
+		}
+
+		if len(causes) > 0 {
+			if joinCause {
+				nmp[k+errorCauseSuffix] = strings.Join(causes, "; ")
+			} else {
+				nmp[k+errorCauseSuffix] = causes
+			}
+		}
+		if stack != "" {
+			nmp[k+errorStackSuffix] = stack
+		}
+	}
+
+	if nmp == nil {
+		return fields
+	}
+	return nmp
+}
+
+// the messages of the wrapped errors chain, not include the err.
+func errorCauses(err error) []string {
+	var causes []string
+	for e := errors.Unwrap(err); e != nil; e = errors.Unwrap(e) {
+		causes = append(causes, e.Error())
+	}
+	return causes
+}
+
+// render the first error has stack trace in the chain by "%+v".
+//
+// check the StackTrace() method by name, it returns github.com/pkg/errors.StackTrace, avoid import the package.
+func errorStack(err error) string {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if reflect.ValueOf(e).MethodByName("StackTrace").IsValid() {
+			return fmt.Sprintf("%+v", e)
+		}
+	}
+	return ""
+}
+
+// mergeM merge the maps to a new map, the former has higher precedence on key collision.
+func mergeM(mps ...M) M {
+	var size int
+	for _, mp := range mps {
+		size += len(mp)
+	}
+	if size == 0 {
+		return nil
+	}
+
+	nmp := make(M, size)
+	for i := len(mps) - 1; i >= 0; i-- {
+		for k, v := range mps[i] {
+			nmp[k] = v
+		}
+	}
+	return nmp
+}
+
+// FieldKeys the output key names of the standard fields.
+//
+// The JSONFormatter use them as the output keys, the TextFormatter also
+// accepts them as the template field names. eg: "{{msg}}" on Message="msg"
+type FieldKeys struct {
+	Datetime  string
+	Timestamp string
+	Caller    string
+	Level     string
+	Channel   string
+	Message   string
+	Data      string
+	Extra     string
+	// Error the key for the error field added by WithError(), Err()
+	Error string
+}
+
+// DefaultFieldKeys the global standard field keys for formatters.
+// change it for rename the output keys in one place.
+//
+// Usage:
+//
+//	slog.DefaultFieldKeys.Message = "msg"
+//	slog.DefaultFieldKeys.Datetime = "@timestamp"
+var DefaultFieldKeys = NewFieldKeys()
+
+// NewFieldKeys create new FieldKeys with default key names
+func NewFieldKeys() *FieldKeys {
+	return &FieldKeys{
+		Datetime:  FieldKeyDatetime,
+		Timestamp: FieldKeyTimestamp,
+		Caller:    FieldKeyCaller,
+		Level:     FieldKeyLevel,
+		Channel:   FieldKeyChannel,
+		Message:   FieldKeyMessage,
+		Data:      FieldKeyData,
+		Extra:     FieldKeyExtra,
+		Error:     FieldKeyError,
+	}
+}
+
+// Key get the output key of the standard field. will return field on it is not a standard field.
+func (k *FieldKeys) Key(field string) string {
+	var key string
+	switch field {
+	case FieldKeyDatetime:
+		key = k.Datetime
+	case FieldKeyTimestamp:
+		key = k.Timestamp
+	case FieldKeyCaller:
+		key = k.Caller
+	case FieldKeyLevel:
+		key = k.Level
+	case FieldKeyChannel:
+		key = k.Channel
+	case FieldKeyMessage:
+		key = k.Message
+	case FieldKeyData:
+		key = k.Data
+	case FieldKeyExtra:
+		key = k.Extra
+	case FieldKeyError:
+		key = k.Error
+	}
+
+	if key == "" {
+		return field
+	}
+	return key
+}
+
+// Field get the standard field name by the output key. will return key on not found.
+func (k *FieldKeys) Field(key string) string {
+	switch key {
+	case k.Datetime:
+		return FieldKeyDatetime
+	case k.Timestamp:
+		return FieldKeyTimestamp
+	case k.Caller:
+		return FieldKeyCaller
+	case k.Level:
+		return FieldKeyLevel
+	case k.Channel:
+		return FieldKeyChannel
+	case k.Message:
+		return FieldKeyMessage
+	case k.Data:
+		return FieldKeyData
+	case k.Extra:
+		return FieldKeyExtra
+	case k.Error:
+		return FieldKeyError
+	}
+	return key
+}
+
+// get the formatter keys, fallback to DefaultFieldKeys
+func fieldKeysOr(keys *FieldKeys) *FieldKeys {
+	if keys != nil {
+		return keys
+	}
+	return DefaultFieldKeys
+}
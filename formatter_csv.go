@@ -0,0 +1,162 @@
+package slog
+
+import (
+	"bytes"
+	"encoding/csv"
+	"sync/atomic"
+)
+
+// DefaultCsvColumns default columns for the CsvFormatter
+var DefaultCsvColumns = []string{
+	FieldKeyDatetime,
+	FieldKeyLevel,
+	FieldKeyChannel,
+	FieldKeyMessage,
+}
+
+// CsvFormatter format the record as one CSV row, the values are quoted and escaped by RFC4180.
+//
+// The column can be a built-in field(eg: datetime, level, message) or a custom field name,
+// the custom field value will read from Record.Fields, then Record.Data. missing values are empty cells.
+type CsvFormatter struct {
+	// Columns the output columns in order. default is DefaultCsvColumns
+	Columns []string
+	// Aliases for the header names. eg: {"message": "msg"}
+	Aliases StringMap
+	// Header output the header row before the first record
+	Header bool
+	// Comma the field delimiter. default is ','
+	Comma rune
+	// UseCRLF use "\r\n" as the line terminator. default is "\n"
+	UseCRLF bool
+	// TimeFormat the time format layout. default is DefaultTimeFormat
+	//
+	// allow special values TimeFormatUnix, TimeFormatUnixMilli for render the unix timestamp.
+	TimeFormat string
+	// CallerFormatFunc the caller format layout. default is defined by CallerFlag
+	CallerFormatFunc CallerFormatFn
+	// mark the header has been written
+	headerDone uint32
+}
+
+// NewCsvFormatter create new CsvFormatter
+func NewCsvFormatter(fns ...func(f *CsvFormatter)) *CsvFormatter {
+	f := &CsvFormatter{
+		Columns:    DefaultCsvColumns,
+		Comma:      ',',
+		TimeFormat: DefaultTimeFormat,
+	}
+
+	for _, fn := range fns {
+		fn(f)
+	}
+	return f
+}
+
+// Configure current formatter
+func (f *CsvFormatter) Configure(fn func(*CsvFormatter)) *CsvFormatter {
+	fn(f)
+	return f
+}
+
+// SetColumns set the output columns
+func (f *CsvFormatter) SetColumns(columns []string) *CsvFormatter {
+	f.Columns = columns
+	return f
+}
+
+// ResetHeader will output the header row again before next record. eg: after rotate file
+func (f *CsvFormatter) ResetHeader() {
+	atomic.StoreUint32(&f.headerDone, 0)
+}
+
+// HeaderRow get the header row values
+func (f *CsvFormatter) HeaderRow() []string {
+	row := make([]string, len(f.Columns))
+	for i, col := range f.Columns {
+		if name, ok := f.Aliases[col]; ok {
+			row[i] = name
+		} else {
+			row[i] = col
+		}
+	}
+	return row
+}
+
+// Format a log record to CSV row
+func (f *CsvFormatter) Format(r *Record) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+	w.UseCRLF = f.UseCRLF
+	if f.Comma != 0 {
+		w.Comma = f.Comma
+	}
+
+	if f.Header && atomic.CompareAndSwapUint32(&f.headerDone, 0, 1) {
+		if err := w.Write(f.HeaderRow()); err != nil {
+			return nil, err
+		}
+	}
+
+	row := make([]string, len(f.Columns))
+	for i, col := range f.Columns {
+		row[i] = f.columnValue(r, col)
+	}
+
+	if err := w.Write(row); err != nil {
+		return nil, err
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func (f *CsvFormatter) columnValue(r *Record, col string) string {
+	switch col {
+	case FieldKeyDatetime:
+		return string(appendTime(nil, r.Time, f.TimeFormat))
+	case FieldKeyTimestamp:
+		return r.timestamp()
+	case FieldKeyCaller:
+		if r.Caller == nil {
+			return ""
+		}
+		if f.CallerFormatFunc != nil {
+			return f.CallerFormatFunc(r.Caller)
+		}
+		return formatCaller(r.Caller, r.CallerFlag)
+	case FieldKeyLevel:
+		return r.LevelName()
+	case FieldKeyChannel:
+		return r.Channel
+	case FieldKeyMessage:
+		return r.Message
+	case FieldKeyData:
+		if len(r.Data) == 0 {
+			return ""
+		}
+		return EncodeToString(r.Data)
+	case FieldKeyExtra:
+		if len(r.Extra) == 0 {
+			return ""
+		}
+		return EncodeToString(r.Extra)
+	}
+
+	if val, ok := r.Fields[col]; ok {
+		return csvValue(val)
+	}
+	if val, ok := r.Data[col]; ok {
+		return csvValue(val)
+	}
+	return ""
+}
+
+func csvValue(val any) string {
+	if val == nil {
+		return ""
+	}
+
+	val, _ = enumValue(val, false)
+	return EncodeToString(val)
+}
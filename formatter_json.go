@@ -1,7 +1,10 @@
 package slog
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"sort"
 
 	"github.com/valyala/bytebufferpool"
 )
@@ -37,13 +40,58 @@ type JSONFormatter struct {
 	// item: `"field" : "output name"`
 	// eg: {"message": "msg"} export field will display "msg"
 	Aliases StringMap
+	// FieldKeys the output keys of the standard fields, the Aliases takes precedence.
+	// default is DefaultFieldKeys
+	FieldKeys *FieldKeys
 
 	// PrettyPrint will indent all json logs
 	PrettyPrint bool
+	// NDJSON strict newline-delimited JSON mode, the PrettyPrint will be ignored.
+	//
+	// each record is exactly one compact JSON object followed by "\n",
+	// the newlines in values are always escaped.
+	NDJSON bool
+	// Indent string on PrettyPrint=true. default is two spaces
+	Indent string
+	// FieldOrder the output keys(after aliases) order. the listed keys will be
+	// output first in order, then the remaining keys sorted.
+	//
+	// default is sorted by encoding/json.
+	FieldOrder []string
 	// TimeFormat the time format layout. default is DefaultTimeFormat
+	//
+	// allow special values TimeFormatUnix, TimeFormatUnixMilli for render the unix timestamp.
 	TimeFormat string
 	// CallerFormatFunc the caller format layout. default is defined by CallerFlag
 	CallerFormatFunc CallerFormatFn
+	// EnumAsNumber render enum-like value(number kind and implements fmt.Stringer) as number.
+	//
+	// default will render it by the String() name, consistent with the TextFormatter.
+	EnumAsNumber bool
+	// LineEnding the line ending of each record. default is "\n", empty is also "\n".
+	//
+	// eg: "\r\n" for some Windows log consumers. use NoLineEnding for no trailing newline.
+	// NOTICE: the NDJSON mode always use "\n".
+	LineEnding string
+	// NoLineEnding don't add the trailing newline of each record. the LineEnding will be ignored.
+	//
+	// NOTICE: the NDJSON mode always use "\n".
+	NoLineEnding bool
+	// ErrorVerbose render the error value with type. eg: {"error": "msg", "type": "*errors.errorString"}
+	//
+	// default will render the error by the Error() message. the error implements json.Marshaler is not changed.
+	ErrorVerbose bool
+	// ErrorDetail add the cause chain and stack trace of the error values in Record.Fields.
+	// default is ErrorDetailMessage, not add them. see ErrorDetail
+	ErrorDetail ErrorDetail
+	// DataMode how to output the Record.Data, Record.Extra. default is DataModeNamespace
+	//
+	// on DataModeMerge, the FieldKeyData, FieldKeyExtra in Fields are ignored.
+	DataMode DataMode
+	// NoEscapeHTML don't escape the '<', '>', '&' in the JSON strings. default will escape them.
+	//
+	// eg: the URL "a?b=1&c=2" will not be rendered as "a?b=1\u0026c=2"
+	NoEscapeHTML bool
 }
 
 // NewJSONFormatter create new JSONFormatter
@@ -52,6 +100,7 @@ func NewJSONFormatter(fn ...func(f *JSONFormatter)) *JSONFormatter {
 		// Aliases: make(StringMap, 0),
 		Fields:     DefaultFields,
 		TimeFormat: DefaultTimeFormat,
+		LineEnding: "\n",
 	}
 
 	if len(fn) > 0 {
@@ -60,12 +109,44 @@ func NewJSONFormatter(fn ...func(f *JSONFormatter)) *JSONFormatter {
 	return f
 }
 
+// NewNDJSONFormatter create new JSONFormatter with strict NDJSON mode. see JSONFormatter.NDJSON
+func NewNDJSONFormatter(fn ...func(f *JSONFormatter)) *JSONFormatter {
+	f := NewJSONFormatter(fn...)
+	f.NDJSON = true
+	return f
+}
+
 // Configure current formatter
 func (f *JSONFormatter) Configure(fn func(*JSONFormatter)) *JSONFormatter {
 	fn(f)
 	return f
 }
 
+// SetFieldOrder set the output keys order. see JSONFormatter.FieldOrder
+func (f *JSONFormatter) SetFieldOrder(keys []string) *JSONFormatter {
+	f.FieldOrder = keys
+	return f
+}
+
+// SetTimeFormat set the time format layout. see JSONFormatter.TimeFormat
+func (f *JSONFormatter) SetTimeFormat(layout string) *JSONFormatter {
+	f.TimeFormat = layout
+	return f
+}
+
+// SetIndent enable PrettyPrint and set the indent string
+func (f *JSONFormatter) SetIndent(indent string) *JSONFormatter {
+	f.PrettyPrint = true
+	f.Indent = indent
+	return f
+}
+
+// SetEscapeHTML set whether to escape the '<', '>', '&' in the JSON strings. see JSONFormatter.NoEscapeHTML
+func (f *JSONFormatter) SetEscapeHTML(on bool) *JSONFormatter {
+	f.NoEscapeHTML = !on
+	return f
+}
+
 // AddField for export
 func (f *JSONFormatter) AddField(name string) *JSONFormatter {
 	f.Fields = append(f.Fields, name)
@@ -77,17 +158,18 @@ var jsonPool bytebufferpool.Pool
 // Format an log record
 func (f *JSONFormatter) Format(r *Record) ([]byte, error) {
 	logData := make(M, len(f.Fields))
+	keys := fieldKeysOr(f.FieldKeys)
 
 	// TODO perf: use buf write build JSON string.
 	for _, field := range f.Fields {
 		outName, ok := f.Aliases[field]
 		if !ok {
-			outName = field
+			outName = keys.Key(field)
 		}
 
 		switch {
 		case field == FieldKeyDatetime:
-			logData[outName] = r.Time.Format(f.TimeFormat)
+			logData[outName] = timeValue(r.Time, f.TimeFormat)
 		case field == FieldKeyTimestamp:
 			logData[outName] = r.timestamp()
 		case field == FieldKeyCaller && r.Caller != nil:
@@ -102,25 +184,34 @@ func (f *JSONFormatter) Format(r *Record) ([]byte, error) {
 			logData[outName] = r.Channel
 		case field == FieldKeyMessage:
 			logData[outName] = r.Message
-		case field == FieldKeyData:
-			logData[outName] = r.Data
-		case field == FieldKeyExtra:
-			logData[outName] = r.Extra
+		case field == FieldKeyData && f.DataMode == DataModeNamespace:
+			logData[outName] = f.encodeMap(orEmptyM(r.Data))
+		case field == FieldKeyExtra && f.DataMode == DataModeNamespace:
+			logData[outName] = f.encodeMap(orEmptyM(r.Extra))
 			// default:
 			// 	logData[outName] = r.Fields[field]
 		}
 	}
 
 	// exported custom fields
-	for field, value := range r.Fields {
+	for field, value := range errorDetailFields(r.Fields, f.ErrorDetail, false) {
 		fieldKey := field
-		if _, has := logData[field]; has {
-			fieldKey = "fields." + field
+		if field == FieldKeyError {
+			fieldKey = keys.Error
+		}
+		if _, has := logData[fieldKey]; has {
+			fieldKey = "fields." + fieldKey
 		}
 
+		value, _ = f.encodeValue(value)
 		logData[fieldKey] = value
 	}
 
+	if f.DataMode == DataModeMerge {
+		f.mergeData(logData, "data.", r.Data, r.Fields)
+		f.mergeData(logData, "extra.", r.Extra, r.Fields, r.Data)
+	}
+
 	// sort.Interface()
 	buf := jsonPool.Get()
 	// buf.Reset()
@@ -129,12 +220,213 @@ func (f *JSONFormatter) Format(r *Record) ([]byte, error) {
 	// buf.Reset()
 	// buf.Grow(256)
 
-	encoder := json.NewEncoder(buf)
-	if f.PrettyPrint {
-		encoder.SetIndent("", "  ")
+	var err error
+	if len(f.FieldOrder) > 0 {
+		err = f.encodeOrdered(buf, logData)
+	} else {
+		encoder := json.NewEncoder(buf)
+		encoder.SetEscapeHTML(!f.NoEscapeHTML)
+		if f.pretty() {
+			encoder.SetIndent("", f.indent())
+		}
+
+		// has been added newline in Encode().
+		err = encoder.Encode(logData)
+	}
+
+	if f.NDJSON {
+		return buf.Bytes(), err
+	}
+	return replaceLineEnding(buf.B, f.LineEnding, f.NoLineEnding), err
+}
+
+func (f *JSONFormatter) pretty() bool {
+	return f.PrettyPrint && !f.NDJSON
+}
+
+func (f *JSONFormatter) indent() string {
+	if f.Indent == "" {
+		return "  "
+	}
+	return f.Indent
+}
+
+// encode log data by FieldOrder, then the remaining keys sorted.
+func (f *JSONFormatter) encodeOrdered(buf *bytebufferpool.ByteBuffer, logData M) error {
+	keys := make([]string, 0, len(logData))
+	ordered := make(map[string]bool, len(f.FieldOrder))
+	for _, key := range f.FieldOrder {
+		if _, ok := logData[key]; ok && !ordered[key] {
+			ordered[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	others := make([]string, 0, len(logData)-len(keys))
+	for key := range logData {
+		if !ordered[key] {
+			others = append(others, key)
+		}
+	}
+	sort.Strings(others)
+	keys = append(keys, others...)
+
+	bs := make([]byte, 0, 256)
+	bs = append(bs, '{')
+	for i, key := range keys {
+		if i > 0 {
+			bs = append(bs, ',')
+		}
+
+		kb, _ := f.marshal(key)
+		vb, err := f.marshal(logData[key])
+		if err != nil {
+			return err
+		}
+
+		bs = append(bs, kb...)
+		bs = append(bs, ':')
+		bs = append(bs, vb...)
+	}
+	bs = append(bs, '}')
+
+	if f.pretty() {
+		var out bytes.Buffer
+		if err := json.Indent(&out, bs, "", f.indent()); err != nil {
+			return err
+		}
+		bs = out.Bytes()
+	}
+
+	_, _ = buf.Write(bs)
+	return buf.WriteByte('\n')
+}
+
+// marshal the value, same as json.Marshal but respect the NoEscapeHTML
+func (f *JSONFormatter) marshal(v any) ([]byte, error) {
+	if !f.NoEscapeHTML {
+		return json.Marshal(v)
+	}
+
+	var bb bytes.Buffer
+	encoder := json.NewEncoder(&bb)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	// remove the newline added by Encode()
+	return bytes.TrimSuffix(bb.Bytes(), []byte{'\n'}), nil
+}
+
+// merge the data to top level, skip the keys exists in the higher precedence maps.
+// the key collided with the standard fields will be prefixed.
+func (f *JSONFormatter) mergeData(logData M, prefix string, data M, highers ...M) {
+	for key, value := range data {
+		var skip bool
+		for _, mp := range highers {
+			if _, skip = mp[key]; skip {
+				break
+			}
+		}
+		if skip {
+			continue
+		}
+
+		if _, has := logData[key]; has {
+			key = prefix + key
+		}
+
+		value, _ = f.encodeValue(value)
+		logData[key] = value
+	}
+}
+
+// encodeMap render the error and enum-like values in the map. see encodeValue()
+func (f *JSONFormatter) encodeMap(mp M) M {
+	if nmp, ok := f.encodeStrMap(mp); ok {
+		return nmp
 	}
+	return mp
+}
 
-	// has been added newline in Encode().
-	err := encoder.Encode(logData)
-	return buf.Bytes(), err
+// encodeValue render the error value by Error() message, and enum-like value by the String() name.
+// the nested values in maps and slices are converted recursively. returns false if not changed.
+func (f *JSONFormatter) encodeValue(v any) (any, bool) {
+	switch tv := v.(type) {
+	case nil:
+		return v, false
+	case error:
+		// keep custom JSON marshal logic
+		if _, ok := v.(json.Marshaler); ok {
+			return v, false
+		}
+		if f.ErrorVerbose {
+			return map[string]any{"error": tv.Error(), "type": fmt.Sprintf("%T", v)}, true
+		}
+		return tv.Error(), true
+	case M:
+		nmp, ok := f.encodeStrMap(tv)
+		return M(nmp), ok
+	case map[string]any:
+		return f.encodeStrMap(tv)
+	case []any:
+		return f.encodeSlice(tv)
+	case []error:
+		ls := make([]any, len(tv))
+		for i, err := range tv {
+			ls[i], _ = f.encodeValue(err)
+		}
+		return ls, true
+	}
+
+	if f.EnumAsNumber {
+		return v, false
+	}
+	return enumValue(v, false)
+}
+
+// encode the values in the map. returns a new map if has changed.
+func (f *JSONFormatter) encodeStrMap(mp map[string]any) (map[string]any, bool) {
+	var nmp map[string]any
+	for k, v := range mp {
+		nv, changed := f.encodeValue(v)
+		if !changed {
+			continue
+		}
+
+		if nmp == nil {
+			nmp = make(map[string]any, len(mp))
+			for k1, v1 := range mp {
+				nmp[k1] = v1
+			}
+		}
+		nmp[k] = nv
+	}
+
+	if nmp == nil {
+		return mp, false
+	}
+	return nmp, true
+}
+
+// encode the values in the slice. returns a new slice if has changed.
+func (f *JSONFormatter) encodeSlice(ls []any) ([]any, bool) {
+	var nls []any
+	for i, v := range ls {
+		nv, changed := f.encodeValue(v)
+		if !changed {
+			continue
+		}
+
+		if nls == nil {
+			nls = make([]any, len(ls))
+			copy(nls, ls)
+		}
+		nls[i] = nv
+	}
+
+	if nls == nil {
+		return ls, false
+	}
+	return nls, true
 }
@@ -1,11 +1,16 @@
 package slog_test
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"runtime"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/gookit/color"
 	"github.com/gookit/goutil/byteutil"
 	"github.com/gookit/goutil/dump"
 	"github.com/gookit/goutil/testutil/assert"
@@ -142,3 +147,618 @@ func TestNewJSONFormatter(t *testing.T) {
 
 	})
 }
+
+type testEnum int
+
+func (e testEnum) String() string {
+	if e == 1 {
+		return "active"
+	}
+	return "unknown"
+}
+
+func TestFormatter_enumStringer(t *testing.T) {
+	r := newLogRecord("enum message")
+	r.Fields = slog.M{"status": testEnum(1)}
+	r.Data = slog.M{"state": testEnum(1), "sub": slog.M{"state": testEnum(1)}}
+
+	tf := slog.NewTextFormatter("{{status}} {{data}}\n")
+	bs, err := tf.Format(r)
+	assert.NoErr(t, err)
+	assert.Eq(t, "active", strings.Fields(string(bs))[0])
+	assert.StrContains(t, string(bs), "state:active")
+
+	jf := slog.NewJSONFormatter(func(f *slog.JSONFormatter) {
+		f.Fields = []string{slog.FieldKeyData}
+	})
+	bs, err = jf.Format(r)
+	assert.NoErr(t, err)
+	str := string(bs)
+	assert.StrContains(t, str, `"status":"active"`)
+	assert.StrContains(t, str, `"state":"active"`)
+	assert.NotContains(t, str, `:1`)
+	// record data is not changed
+	assert.Eq(t, testEnum(1), r.Data["state"])
+
+	t.Run("EnumAsNumber", func(t *testing.T) {
+		tf.EnumAsNumber = true
+		bs, err = tf.Format(r)
+		assert.NoErr(t, err)
+		assert.Eq(t, "1", strings.Fields(string(bs))[0])
+		assert.StrContains(t, string(bs), "state:1")
+
+		jf.EnumAsNumber = true
+		bs, err = jf.Format(r)
+		assert.NoErr(t, err)
+		assert.StrContains(t, string(bs), `"status":1`)
+		assert.StrContains(t, string(bs), `"state":1`)
+	})
+}
+
+type testUser struct {
+	name string
+}
+
+func (u testUser) String() string { return "user:" + u.name }
+
+type testPoint struct {
+	X, Y int
+}
+
+func (p testPoint) MarshalText() ([]byte, error) {
+	return []byte(strconv.Itoa(p.X) + "," + strconv.Itoa(p.Y)), nil
+}
+
+func TestFormatter_stringerTextMarshaler(t *testing.T) {
+	r := newLogRecord("marshal message")
+	r.Fields = slog.M{"user": testUser{name: "inhere"}, "point": testPoint{X: 1, Y: 2}, "nil_point": (*testPoint)(nil)}
+	r.Data = slog.M{"pos": testPoint{X: 3, Y: 4}}
+
+	tf := slog.NewTextFormatter("{{user}} {{point}} {{data}} {{fields}}")
+	bs, err := tf.Format(r)
+	assert.NoErr(t, err)
+	str := string(bs)
+	assert.True(t, strings.HasPrefix(str, "user:inhere 1,2 {pos:3,4}"))
+	assert.StrContains(t, str, "nil_point=<nil>")
+
+	// encoding/json use the MarshalText()
+	jf := slog.NewJSONFormatter(func(f *slog.JSONFormatter) {
+		f.Fields = []string{slog.FieldKeyData}
+	})
+	bs, err = jf.Format(r)
+	assert.NoErr(t, err)
+	str = string(bs)
+	assert.StrContains(t, str, `"point":"1,2"`)
+	assert.StrContains(t, str, `"pos":"3,4"`)
+}
+
+// a custom error type, the exported fields are empty
+type testCodeError struct {
+	code int
+}
+
+func (e *testCodeError) Error() string {
+	return fmt.Sprintf("code error: %d", e.code)
+}
+
+// a custom error with JSON marshal logic
+type testJSONError struct{}
+
+func (e testJSONError) Error() string { return "json error" }
+
+func (e testJSONError) MarshalJSON() ([]byte, error) {
+	return []byte(`{"reason":"custom"}`), nil
+}
+
+func TestJSONFormatter_errors(t *testing.T) {
+	r := newLogRecord("error message")
+	r.Fields = slog.M{
+		"error":  &testCodeError{code: 23},
+		"errs":   []error{&testCodeError{code: 1}, testJSONError{}},
+		"nested": slog.M{"list": []any{"a", &testCodeError{code: 2}}},
+	}
+	r.Data = slog.M{"cause": &testCodeError{code: 3}}
+
+	jf := slog.NewJSONFormatter(func(f *slog.JSONFormatter) {
+		f.Fields = []string{slog.FieldKeyData}
+	})
+	bs, err := jf.Format(r)
+	assert.NoErr(t, err)
+	str := string(bs)
+	assert.StrContains(t, str, `"error":"code error: 23"`)
+	assert.StrContains(t, str, `"errs":["code error: 1",{"reason":"custom"}]`)
+	assert.StrContains(t, str, `"nested":{"list":["a","code error: 2"]}`)
+	assert.StrContains(t, str, `"cause":"code error: 3"`)
+	// record data is not changed
+	assert.IsType(t, &testCodeError{}, r.Data["cause"])
+
+	t.Run("ErrorVerbose", func(t *testing.T) {
+		jf.ErrorVerbose = true
+		bs, err = jf.Format(r)
+		assert.NoErr(t, err)
+		str = string(bs)
+		assert.StrContains(t, str, `"error":{"error":"code error: 23","type":"*slog_test.testCodeError"}`)
+		assert.StrContains(t, str, `"cause":{"error":"code error: 3","type":"*slog_test.testCodeError"}`)
+		assert.StrContains(t, str, `{"reason":"custom"}`)
+	})
+}
+
+type testStackError struct{ msg string }
+
+func (e *testStackError) Error() string { return e.msg }
+
+func (e *testStackError) StackTrace() []uintptr { return nil }
+
+func (e *testStackError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		_, _ = fmt.Fprintf(s, "%s\nmain.load\n\t/app/main.go:12", e.msg)
+		return
+	}
+	_, _ = fmt.Fprint(s, e.msg)
+}
+
+func TestFormatter_errorDetail(t *testing.T) {
+	r := newLogRecord("error message")
+	r.Fields = slog.M{
+		"error": fmt.Errorf("load config: %w", fmt.Errorf("open file: %w", os.ErrNotExist)),
+		"other": "value",
+	}
+
+	// default: not add the detail fields
+	jf := slog.NewJSONFormatter()
+	bs, err := jf.Format(r)
+	assert.NoErr(t, err)
+	assert.NotContains(t, string(bs), "error_cause")
+
+	jf.ErrorDetail = slog.ErrorDetailCause
+	bs, err = jf.Format(r)
+	assert.NoErr(t, err)
+	str := string(bs)
+	assert.StrContains(t, str, `"error":"load config: open file: file does not exist"`)
+	assert.StrContains(t, str, `"error_cause":["open file: file does not exist","file does not exist"]`)
+	assert.NotContains(t, str, "error_stack")
+	// record fields is not changed
+	assert.Len(t, r.Fields, 2)
+
+	tf := slog.NewTextFormatter("{{message}} {{fields}}")
+	tf.ErrorDetail = slog.ErrorDetailCause
+	bs, err = tf.Format(r)
+	assert.NoErr(t, err)
+	assert.StrContains(t, string(bs), `error_cause="open file: file does not exist; file does not exist"`)
+
+	tf.SetTemplate("{{message}} cause: {{error_cause}}")
+	bs, err = tf.Format(r)
+	assert.NoErr(t, err)
+	assert.Eq(t, "error message cause: open file: file does not exist; file does not exist", string(bs))
+
+	t.Run("ErrorDetailStack", func(t *testing.T) {
+		r.Fields = slog.M{"error": fmt.Errorf("save user: %w", &testStackError{msg: "db closed"})}
+		jf.ErrorDetail = slog.ErrorDetailStack
+		bs, err = jf.Format(r)
+		assert.NoErr(t, err)
+		str = string(bs)
+		assert.StrContains(t, str, `"error_cause":["db closed"]`)
+		assert.StrContains(t, str, `"error_stack":"db closed\nmain.load\n\t/app/main.go:12"`)
+
+		// no stack in the chain
+		r.Fields = slog.M{"error": os.ErrNotExist}
+		bs, err = jf.Format(r)
+		assert.NoErr(t, err)
+		assert.NotContains(t, string(bs), "error_")
+	})
+}
+
+func TestJSONFormatter_FieldOrder(t *testing.T) {
+	r := newLogRecord("order message")
+	r.Fields = slog.M{"zoo": 1, "app": "order"}
+
+	f := slog.NewJSONFormatter(func(f *slog.JSONFormatter) {
+		f.Fields = slog.NoTimeFields
+		f.Aliases = slog.StringMap{"message": "msg"}
+	})
+	f.SetFieldOrder([]string{"level", "msg", "not-exists"})
+
+	bs, err := f.Format(r)
+	assert.NoErr(t, err)
+	str := string(bs)
+	assert.True(t, strings.HasPrefix(str, `{"level":"info","msg":"order message","app":"order","channel":`))
+	assert.True(t, strings.HasSuffix(str, `"zoo":1}`+"\n"))
+
+	// is deterministic
+	for i := 0; i < 5; i++ {
+		bs, err = f.Format(r)
+		assert.NoErr(t, err)
+		assert.Eq(t, str, string(bs))
+	}
+
+	// pretty print
+	f.SetIndent("\t")
+	bs, err = f.Format(r)
+	assert.NoErr(t, err)
+	assert.True(t, strings.HasPrefix(string(bs), "{\n\t\"level\": \"info\",\n\t\"msg\": \"order message\","))
+
+	f.FieldOrder = nil
+	bs, err = f.Format(r)
+	assert.NoErr(t, err)
+	assert.True(t, strings.HasPrefix(string(bs), "{\n\t\"app\": \"order\",\n"))
+}
+
+func TestJSONFormatter_SetEscapeHTML(t *testing.T) {
+	r := newLogRecord("see <html> page")
+	r.Fields = slog.M{"url": "https://example.com/?a=1&b=<html>"}
+
+	f := slog.NewJSONFormatter()
+	bs, err := f.Format(r)
+	assert.NoErr(t, err)
+	assert.StrContains(t, string(bs), `"url":"https://example.com/?a=1\u0026b=\u003chtml\u003e"`)
+
+	f.SetEscapeHTML(false)
+	bs, err = f.Format(r)
+	assert.NoErr(t, err)
+	str := string(bs)
+	assert.StrContains(t, str, `"message":"see <html> page"`)
+	assert.StrContains(t, str, `"url":"https://example.com/?a=1&b=<html>"`)
+
+	// with FieldOrder
+	f.SetFieldOrder([]string{"url"})
+	bs, err = f.Format(r)
+	assert.NoErr(t, err)
+	assert.True(t, strings.HasPrefix(string(bs), `{"url":"https://example.com/?a=1&b=<html>",`))
+}
+
+func newCollidedRecord() *slog.Record {
+	r := newLogRecord("collided message")
+	r.Fields = slog.M{"user": "from-fields", "level": "from-fields"}
+	r.Data = slog.M{"user": "from-data", "app": "from-data", "level": "from-data"}
+	r.Extra = slog.M{"user": "from-extra", "app": "from-extra", "host": "from-extra"}
+	return r
+}
+
+func TestJSONFormatter_DataMode(t *testing.T) {
+	r := newCollidedRecord()
+
+	// namespace mode
+	f := slog.NewJSONFormatter(func(f *slog.JSONFormatter) {
+		f.Fields = slog.NoTimeFields
+	})
+	bs, err := f.Format(r)
+	assert.NoErr(t, err)
+
+	mp := make(map[string]any)
+	assert.NoErr(t, json.Unmarshal(bs, &mp))
+	assert.Eq(t, "from-fields", mp["user"])
+	assert.Eq(t, "from-fields", mp["fields.level"])
+	assert.Eq(t, map[string]any{"user": "from-data", "app": "from-data", "level": "from-data"}, mp["data"])
+	assert.Eq(t, map[string]any{"user": "from-extra", "app": "from-extra", "host": "from-extra"}, mp["extra"])
+
+	// merge mode
+	f.DataMode = slog.DataModeMerge
+	bs, err = f.Format(r)
+	assert.NoErr(t, err)
+
+	mp = make(map[string]any)
+	assert.NoErr(t, json.Unmarshal(bs, &mp))
+	assert.Eq(t, map[string]any{
+		"channel":      "application",
+		"level":        "info",
+		"message":      "collided message",
+		"user":         "from-fields",
+		"app":          "from-data",
+		"host":         "from-extra",
+		"fields.level": "from-fields",
+	}, mp)
+
+	// the data level is collided with the standard field only
+	r.Fields = nil
+	bs, err = f.Format(r)
+	assert.NoErr(t, err)
+	assert.StrContains(t, string(bs), `"data.level":"from-data"`)
+	assert.StrContains(t, string(bs), `"user":"from-data"`)
+}
+
+func TestTextFormatter_DataMode(t *testing.T) {
+	r := newCollidedRecord()
+
+	f := slog.NewTextFormatter("{{user}} {{app}} {{host}} | {{data}} | {{extra}}\n")
+	bs, err := f.Format(r)
+	assert.NoErr(t, err)
+	str := string(bs)
+	assert.True(t, strings.HasPrefix(str, "from-fields app host | {"))
+	assert.StrContains(t, str, "user:from-data")
+	assert.StrContains(t, str, "host:from-extra")
+
+	f.DataMode = slog.DataModeMerge
+	bs, err = f.Format(r)
+	assert.NoErr(t, err)
+	str = string(bs)
+	assert.True(t, strings.HasPrefix(str, "from-fields from-data from-extra | {"))
+	assert.True(t, strings.HasSuffix(str, "} | \n"))
+	assert.StrContains(t, str, "user:from-data")
+	assert.StrContains(t, str, "app:from-data")
+	assert.StrContains(t, str, "host:from-extra")
+	assert.NotContains(t, str, "user:from-extra")
+}
+
+func TestTextFormatter_singleBraceTemplate(t *testing.T) {
+	r := newLogRecord("TEST_LOG_MESSAGE")
+	r.Fields = slog.M{"user": "inhere", "app": "order", "note": "has space", "empty": ""}
+
+	f := slog.NewTextFormatter("[{level}] {channel}: {message} {app} {unknown}{fields}")
+	assert.Eq(t, []string{"level", "channel", "message", "app", "unknown", "fields"}, f.Fields())
+
+	bs, err := f.Format(r)
+	assert.NoErr(t, err)
+	assert.Eq(t, `[info] application: TEST_LOG_MESSAGE order empty="" note="has space" user=inhere`, string(bs))
+
+	// no fields
+	r.Fields = nil
+	bs, err = f.Format(r)
+	assert.NoErr(t, err)
+	assert.Eq(t, "[info] application: TEST_LOG_MESSAGE  ", string(bs))
+
+	// the "{{fields}}" also works on the double brace template
+	r.Fields = slog.M{"user": "inhere"}
+	f.SetTemplate("{{message}} {{fields}} {{unknown}}")
+	bs, err = f.Format(r)
+	assert.NoErr(t, err)
+	assert.Eq(t, "TEST_LOG_MESSAGE user=inhere unknown", string(bs))
+}
+
+func TestTextFormatter_ColumnAlign(t *testing.T) {
+	f := slog.NewTextFormatter("[{{level}}] [{{channel}}] {{message}}|\n")
+	r := newLogRecord("message")
+
+	// default not changed
+	bs, err := f.Format(r)
+	assert.NoErr(t, err)
+	assert.Eq(t, "[info] [application] message|\n", string(bs))
+
+	f.WithColumnAlign(true)
+	bs, err = f.Format(r)
+	assert.NoErr(t, err)
+	assert.Eq(t, "[info  ] [application] message|\n", string(bs))
+
+	f.LevelWidth, f.ChannelWidth, f.MessageWidth = 7, 14, 10
+	bs, err = f.Format(r)
+	assert.NoErr(t, err)
+	assert.Eq(t, "[info   ] [application   ] message   |\n", string(bs))
+
+	// the padding is outside the color codes
+	f.EnableColor = true
+	f.ColorTheme = map[slog.Level]color.Color{slog.InfoLevel: color.FgBlue}
+	bs, err = f.Format(r)
+	assert.NoErr(t, err)
+	assert.StrContains(t, string(bs), "["+color.FgBlue.Render("info")+"   ]")
+}
+
+func TestTextFormatter_SetColorMode(t *testing.T) {
+	f := slog.NewTextFormatter("{{level}}")
+	r := newLogRecord("message")
+
+	f.SetColorMode(slog.ColorModeOn, nil)
+	assert.True(t, f.EnableColor)
+	bs, err := f.Format(r)
+	assert.NoErr(t, err)
+	assert.StrContains(t, string(bs), "\x1b[")
+
+	// custom color theme
+	f.ColorTheme = map[slog.Level]color.Color{slog.InfoLevel: color.FgBlue}
+	bs, err = f.Format(r)
+	assert.NoErr(t, err)
+	assert.Eq(t, color.FgBlue.Render(r.LevelName()), string(bs))
+
+	f.SetColorMode(slog.ColorModeOff, nil)
+	assert.False(t, f.EnableColor)
+
+	// auto: non-terminal writers
+	buf := new(byteutil.Buffer)
+	f.SetColorMode(slog.ColorModeOn, nil).SetColorMode(slog.ColorModeAuto, buf)
+	assert.False(t, f.EnableColor)
+
+	file, err := os.CreateTemp("", "slog-color-*.log")
+	assert.NoErr(t, err)
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	h := handler.NewIOWriter(file, slog.AllLevels)
+	h.SetFormatter(f.WithEnableColor(true))
+	h.SetColorMode(slog.ColorModeAuto)
+	assert.False(t, f.EnableColor)
+	assert.NoErr(t, h.Handle(r))
+
+	bs, err = os.ReadFile(file.Name())
+	assert.NoErr(t, err)
+	assert.NotContains(t, string(bs), "\x1b[")
+}
+
+func TestCsvFormatter_Format(t *testing.T) {
+	r := newLogRecord("csv message, with \"quotes\"")
+	r.Time = time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	r.Fields = slog.M{"user_id": 23, "tags": "a\nb"}
+
+	f := slog.NewCsvFormatter(func(f *slog.CsvFormatter) {
+		f.Columns = []string{"datetime", "level", "message", "user_id", "username", "tags", "not-exists"}
+		f.Aliases = slog.StringMap{"message": "msg"}
+		f.Header = true
+		f.TimeFormat = time.RFC3339
+	})
+
+	bs, err := f.Format(r)
+	assert.NoErr(t, err)
+	assert.Eq(t, "datetime,level,msg,user_id,username,tags,not-exists\n"+
+		`2023-01-02T03:04:05Z,info,"csv message, with ""quotes""",23,inhere,"a`+"\nb\",\n", string(bs))
+
+	// header only output once
+	r.Message = "message2"
+	bs, err = f.Format(r)
+	assert.NoErr(t, err)
+	assert.Eq(t, "2023-01-02T03:04:05Z,info,message2,23,inhere,\"a\nb\",\n", string(bs))
+
+	f.ResetHeader()
+	f.Comma = ';'
+	f.UseCRLF = true
+	f.SetColumns([]string{"level", "channel"})
+	bs, err = f.Format(r)
+	assert.NoErr(t, err)
+	assert.Eq(t, "level;channel\r\ninfo;application\r\n", string(bs))
+}
+
+func TestFieldKeys(t *testing.T) {
+	r := newLogRecord("keys message")
+	r.Fields = slog.M{slog.FieldKeyError: "an error"}
+
+	keys := slog.NewFieldKeys()
+	keys.Message = "msg"
+	keys.Level = "severity"
+	keys.Datetime = "@timestamp"
+	keys.Error = "err"
+	assert.Eq(t, "msg", keys.Key(slog.FieldKeyMessage))
+	assert.Eq(t, "not-exists", keys.Key("not-exists"))
+	assert.Eq(t, slog.FieldKeyMessage, keys.Field("msg"))
+
+	jf := slog.NewJSONFormatter(func(f *slog.JSONFormatter) {
+		f.Fields = []string{slog.FieldKeyDatetime, slog.FieldKeyLevel, slog.FieldKeyMessage}
+		f.Aliases = slog.StringMap{slog.FieldKeyLevel: "lvl"}
+		f.FieldKeys = keys
+	})
+	bs, err := jf.Format(r)
+	assert.NoErr(t, err)
+	str := string(bs)
+	assert.StrContains(t, str, `"@timestamp":`)
+	assert.StrContains(t, str, `"msg":"keys message"`)
+	assert.StrContains(t, str, `"err":"an error"`)
+	// aliases takes precedence
+	assert.StrContains(t, str, `"lvl":"info"`)
+	assert.NotContains(t, str, `"message"`)
+
+	tf := slog.NewTextFormatter("{{severity}} {{msg}} {{level}} {{err}}")
+	tf.FieldKeys = keys
+	bs, err = tf.Format(r)
+	assert.NoErr(t, err)
+	assert.Eq(t, "info keys message info an error", string(bs))
+
+	// use the global keys
+	old := slog.DefaultFieldKeys
+	defer func() { slog.DefaultFieldKeys = old }()
+	slog.DefaultFieldKeys = keys
+
+	jf.FieldKeys = nil
+	bs, err = jf.Format(r)
+	assert.NoErr(t, err)
+	assert.StrContains(t, string(bs), `"msg":"keys message"`)
+
+	// the renamed error key collides with the standard field
+	keys.Error = "msg"
+	bs, err = jf.Format(r)
+	assert.NoErr(t, err)
+	str = string(bs)
+	assert.StrContains(t, str, `"msg":"keys message"`)
+	assert.StrContains(t, str, `"fields.msg":"an error"`)
+}
+
+type multilineJSON struct{}
+
+func (multilineJSON) MarshalJSON() ([]byte, error) {
+	return []byte("{\n  \"a\": 1\n}"), nil
+}
+
+func TestNewNDJSONFormatter(t *testing.T) {
+	buf := byteutil.NewBuffer()
+	h := handler.IOWriterWithMaxLevel(buf, slog.DebugLevel)
+	h.SetFormatter(slog.NewNDJSONFormatter(func(f *slog.JSONFormatter) {
+		f.PrettyPrint = true // will be ignored
+		f.SetFieldOrder([]string{"level"})
+	}))
+
+	l := slog.NewWithHandlers(h)
+	l.WithField("stack", "line1\nline2\r\n").Info("multi\nline\nmessage")
+	l.WithData(slog.M{"raw": multilineJSON{}}).Warn("with marshaler\n")
+	l.Debug("single line")
+
+	lines := strings.Split(buf.String(), "\n")
+	assert.Len(t, lines, 4)
+	assert.Empty(t, lines[3])
+	for _, line := range lines[:3] {
+		assert.True(t, json.Valid([]byte(line)), line)
+	}
+	assert.StrContains(t, lines[0], `"message":"multi\nline\nmessage"`)
+	assert.StrContains(t, lines[1], `"raw":{"a":1}`)
+}
+
+func TestFormatter_LineEnding(t *testing.T) {
+	tests := []struct {
+		name string
+		f    slog.Formatter
+	}{
+		{"text", slog.NewTextFormatter()},
+		{"json", slog.NewJSONFormatter()},
+		{"json-ordered", slog.NewJSONFormatter().SetFieldOrder([]string{"level"})},
+	}
+
+	for _, tt := range tests {
+		// "-" for NoLineEnding
+		for _, ending := range []string{"\r\n", "", "\n", "-"} {
+			noEnding := ending == "-"
+			switch f := tt.f.(type) {
+			case *slog.TextFormatter:
+				f.LineEnding, f.NoLineEnding = ending, noEnding
+			case *slog.JSONFormatter:
+				f.LineEnding, f.NoLineEnding = ending, noEnding
+			}
+
+			bs, err := tt.f.Format(newLogRecord("line ending message"))
+			assert.NoErr(t, err)
+
+			want := ending
+			if ending == "" {
+				want = "\n"
+			} else if noEnding {
+				want = ""
+			}
+
+			str := string(bs)
+			body := strings.TrimSuffix(str, want)
+			assert.True(t, strings.HasSuffix(str, want), tt.name)
+			assert.NotContains(t, body, "\n", tt.name)
+			assert.NotContains(t, body, "\r", tt.name)
+		}
+	}
+
+	// the struct literal formatter keep the default "\n"
+	jf := &slog.JSONFormatter{Fields: slog.DefaultFields}
+	bs, err := jf.Format(newLogRecord("line ending message"))
+	assert.NoErr(t, err)
+	assert.True(t, strings.HasSuffix(string(bs), "}\n"))
+}
+
+func TestFormatter_SetTimeFormat(t *testing.T) {
+	tm := time.Date(2023, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	tests := []struct {
+		layout string
+		text   string
+		json   string
+	}{
+		{slog.DefaultTimeFormat, "2023/01/02T03:04:05.123", `"2023/01/02T03:04:05.123"`},
+		{time.RFC3339, "2023-01-02T03:04:05Z", `"2023-01-02T03:04:05Z"`},
+		{time.RFC3339Nano, "2023-01-02T03:04:05.123456789Z", `"2023-01-02T03:04:05.123456789Z"`},
+		{slog.TimeFormatUnix, "1672628645", `1672628645`},
+		{slog.TimeFormatUnixMilli, "1672628645123", `1672628645123`},
+		{"15:04", "03:04", `"03:04"`},
+	}
+
+	r := newLogRecord("time format")
+	r.Time = tm
+	for _, tt := range tests {
+		tf := slog.NewTextFormatter("{{datetime}}").SetTimeFormat(tt.layout)
+		bs, err := tf.Format(r)
+		assert.NoErr(t, err)
+		assert.Eq(t, tt.text, string(bs), tt.layout)
+
+		jf := slog.NewJSONFormatter(func(f *slog.JSONFormatter) {
+			f.Fields = []string{slog.FieldKeyDatetime}
+		}).SetTimeFormat(tt.layout)
+		bs, err = jf.Format(r)
+		assert.NoErr(t, err)
+		assert.Eq(t, `{"datetime":`+tt.json+"}\n", string(bs), tt.layout)
+	}
+}
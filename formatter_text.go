@@ -1,6 +1,13 @@
 package slog
 
 import (
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/gookit/color"
 	"github.com/valyala/bytebufferpool"
 )
@@ -11,6 +18,18 @@ const (
 	NamedTemplate   = "{{datetime}} channel={{channel}} level={{level}} [file={{caller}}] message={{message}} data={{data}}\n"
 )
 
+// ColorMode for the TextFormatter color output
+type ColorMode uint8
+
+const (
+	// ColorModeAuto enable color only when the output is a terminal and support color
+	ColorModeAuto ColorMode = iota
+	// ColorModeOn always enable color
+	ColorModeOn
+	// ColorModeOff always disable color
+	ColorModeOff
+)
+
 // ColorTheme for format log to console
 var ColorTheme = map[Level]color.Color{
 	PanicLevel:  color.FgRed,
@@ -24,6 +43,15 @@ var ColorTheme = map[Level]color.Color{
 }
 
 // TextFormatter definition
+//
+// The template placeholders can be "{{name}}" or the single brace "{name}" style:
+//
+//	"[{{datetime}}] [{{level}}] {{message}} {{data}}\n"
+//	"{datetime} [{level}] {channel}: {message} {fields}\n"
+//
+// The name must start with a lowercase letter. On the single brace style, the unknown
+// placeholder renders empty, the "{{name}}" style renders the name as is.
+// The special "{fields}" renders the Record.Fields that are not in the template as "k=v".
 type TextFormatter struct {
 	// template text template for render output log messages
 	template string
@@ -31,8 +59,14 @@ type TextFormatter struct {
 	// NOTICE: fields contains no-field items.
 	// eg: ["level", "}}"}
 	fields []string
+	// the field names in template, skip them on render "{fields}"
+	tplKeys map[string]bool
+	// the unknown placeholder renders empty. true on the single brace template
+	emptyUnknown bool
 
 	// TimeFormat the time format layout. default is DefaultTimeFormat
+	//
+	// allow special values TimeFormatUnix, TimeFormatUnixMilli for render the unix timestamp.
 	TimeFormat string
 	// Enable color on print log to terminal
 	EnableColor bool
@@ -45,6 +79,38 @@ type TextFormatter struct {
 	EncodeFunc func(v any) string
 	// CallerFormatFunc the caller format layout. default is defined by CallerFlag
 	CallerFormatFunc CallerFormatFn
+	// EnumAsNumber render enum-like value(number kind and implements fmt.Stringer) as number.
+	// default will render it by the String() name.
+	EnumAsNumber bool
+	// FieldKeys the template can use the renamed keys of the standard fields.
+	// default is DefaultFieldKeys
+	FieldKeys *FieldKeys
+	// LineEnding replace the trailing newline of the template. default is "\n", empty is also "\n".
+	//
+	// eg: "\r\n" for some Windows log consumers. use NoLineEnding for no trailing newline.
+	LineEnding string
+	// NoLineEnding remove the trailing newline of the template. the LineEnding will be ignored.
+	NoLineEnding bool
+	// DataMode how to render the Record.Data, Record.Extra. default is DataModeNamespace
+	//
+	// on DataModeMerge, the "{{data}}" renders the merged Data and Extra, the "{{extra}}" renders nothing,
+	// and the custom field in template is looked up from Fields, Data, then Extra.
+	DataMode DataMode
+	// ErrorDetail add the cause chain and stack trace of the error values in Record.Fields.
+	// the template can use them by the "{{error_cause}}", "{{error_stack}}". the causes are joined by "; "
+	//
+	// default is ErrorDetailMessage, not add them. see ErrorDetail
+	ErrorDetail ErrorDetail
+
+	// ColumnAlign right-pads the level, channel and message to fixed width, the logs read like a table.
+	// default is false, the output is not changed.
+	ColumnAlign bool
+	// LevelWidth the level column width on ColumnAlign=true. default is the longest name in LevelNames
+	LevelWidth int
+	// ChannelWidth the channel column width on ColumnAlign=true. default is 0, not padding
+	ChannelWidth int
+	// MessageWidth the message column width on ColumnAlign=true. default is 0, not padding
+	MessageWidth int
 }
 
 // NewTextFormatter create new TextFormatter
@@ -60,6 +126,7 @@ func NewTextFormatter(template ...string) *TextFormatter {
 		// default options
 		TimeFormat: DefaultTimeFormat,
 		ColorTheme: ColorTheme,
+		LineEnding: "\n",
 		// EnableColor: color.SupportColor(),
 		// EncodeFunc: func(v any) string {
 		// 	return fmt.Sprint(v)
@@ -77,10 +144,24 @@ func (f *TextFormatter) Configure(fn func(*TextFormatter)) *TextFormatter {
 	return f
 }
 
+// match the single brace placeholder. eg: "{message}"
+var singleBraceVar = regexp.MustCompile(`\{([a-z][\w.-]*)\}`)
+
 // SetTemplate set the log format template and update field-map
 func (f *TextFormatter) SetTemplate(fmtTpl string) {
 	f.template = fmtTpl
+
+	// single brace style, convert to "{{name}}"
+	f.emptyUnknown = !strings.Contains(fmtTpl, "{{") && singleBraceVar.MatchString(fmtTpl)
+	if f.emptyUnknown {
+		fmtTpl = singleBraceVar.ReplaceAllString(fmtTpl, "{{$1}}")
+	}
 	f.fields = parseTemplateToFields(fmtTpl)
+
+	f.tplKeys = make(map[string]bool, len(f.fields)/2)
+	for _, name := range f.Fields() {
+		f.tplKeys[name] = true
+	}
 }
 
 // Template get
@@ -88,12 +169,40 @@ func (f *TextFormatter) Template() string {
 	return f.template
 }
 
+// SetTimeFormat set the time format layout. see TextFormatter.TimeFormat
+func (f *TextFormatter) SetTimeFormat(layout string) *TextFormatter {
+	f.TimeFormat = layout
+	return f
+}
+
 // WithEnableColor enable color on print log to terminal
 func (f *TextFormatter) WithEnableColor(enable bool) *TextFormatter {
 	f.EnableColor = enable
 	return f
 }
 
+// WithColumnAlign enable align the level, channel and message columns. see TextFormatter.ColumnAlign
+func (f *TextFormatter) WithColumnAlign(enable bool) *TextFormatter {
+	f.ColumnAlign = enable
+	return f
+}
+
+// SetColorMode set EnableColor by the color mode.
+//
+// On ColorModeAuto, will enable color only when the out is a terminal.
+// so the non-terminal writers(eg: file, buffer) never get the color codes.
+func (f *TextFormatter) SetColorMode(mode ColorMode, out io.Writer) *TextFormatter {
+	switch mode {
+	case ColorModeOn:
+		f.EnableColor = true
+	case ColorModeOff:
+		f.EnableColor = false
+	default:
+		f.EnableColor = isTerminal(out) && color.SupportColor()
+	}
+	return f
+}
+
 // Fields get export field list
 func (f *TextFormatter) Fields() []string {
 	ss := make([]string, 0, len(f.fields)/2)
@@ -113,6 +222,8 @@ var textPool bytebufferpool.Pool
 func (f *TextFormatter) Format(r *Record) ([]byte, error) {
 	buf := textPool.Get()
 	defer textPool.Put(buf)
+	keys := fieldKeysOr(f.FieldKeys)
+	fields := errorDetailFields(r.Fields, f.ErrorDetail, true)
 
 	for _, field := range f.fields {
 		// is not field name. eg: "}}] "
@@ -126,12 +237,13 @@ func (f *TextFormatter) Format(r *Record) ([]byte, error) {
 			continue
 		}
 
+		name := keys.Field(field)
 		switch {
-		case field == FieldKeyDatetime:
-			buf.B = r.Time.AppendFormat(buf.B, f.TimeFormat)
-		case field == FieldKeyTimestamp:
+		case name == FieldKeyDatetime:
+			buf.B = appendTime(buf.B, r.Time, f.TimeFormat)
+		case name == FieldKeyTimestamp:
 			buf.WriteString(r.timestamp())
-		case field == FieldKeyCaller && r.Caller != nil:
+		case name == FieldKeyCaller && r.Caller != nil:
 			var callerLog string
 			if f.CallerFormatFunc != nil {
 				callerLog = f.CallerFormatFunc(r.Caller)
@@ -139,41 +251,136 @@ func (f *TextFormatter) Format(r *Record) ([]byte, error) {
 				callerLog = formatCaller(r.Caller, r.CallerFlag)
 			}
 			buf.WriteString(callerLog)
-		case field == FieldKeyLevel:
+		case name == FieldKeyLevel:
 			// output colored logs for console
 			if f.EnableColor {
 				buf.WriteString(f.renderColorByLevel(r.LevelName(), r.Level))
 			} else {
 				buf.WriteString(r.LevelName())
 			}
-		case field == FieldKeyChannel:
+			if f.ColumnAlign {
+				writePadding(buf, r.LevelName(), f.levelWidth())
+			}
+		case name == FieldKeyChannel:
 			buf.WriteString(r.Channel)
-		case field == FieldKeyMessage:
+			if f.ColumnAlign {
+				writePadding(buf, r.Channel, f.ChannelWidth)
+			}
+		case name == FieldKeyMessage:
 			// output colored logs for console
 			if f.EnableColor {
 				buf.WriteString(f.renderColorByLevel(r.Message, r.Level))
 			} else {
 				buf.WriteString(r.Message)
 			}
-		case field == FieldKeyData:
-			if f.FullDisplay || len(r.Data) > 0 {
-				buf.WriteString(f.EncodeFunc(r.Data))
+			if f.ColumnAlign {
+				writePadding(buf, r.Message, f.MessageWidth)
 			}
-		case field == FieldKeyExtra:
-			if f.FullDisplay || len(r.Extra) > 0 {
-				buf.WriteString(f.EncodeFunc(r.Extra))
+		case name == FieldKeyData:
+			data := r.Data
+			if f.DataMode == DataModeMerge {
+				data = mergeM(r.Data, r.Extra)
 			}
+			if f.FullDisplay || len(data) > 0 {
+				buf.WriteString(f.EncodeFunc(f.encodeEnums(data)))
+			}
+		case name == FieldKeyExtra:
+			if f.DataMode == DataModeNamespace && (f.FullDisplay || len(r.Extra) > 0) {
+				buf.WriteString(f.EncodeFunc(f.encodeEnums(r.Extra)))
+			}
+		case name == FieldKeyFields:
+			f.writeFields(buf, fields)
 		default:
-			if _, ok := r.Fields[field]; ok {
-				buf.WriteString(f.EncodeFunc(r.Fields[field]))
-			} else {
+			if val, ok := f.fieldValue(r, fields, name); ok {
+				if f.EnumAsNumber {
+					val, _ = enumValue(val, true)
+				}
+				buf.WriteString(f.EncodeFunc(val))
+			} else if !f.emptyUnknown {
 				buf.WriteString(field)
 			}
 		}
 	}
 
 	// return buf.Bytes(), nil
-	return buf.B, nil
+	return replaceLineEnding(buf.B, f.LineEnding, f.NoLineEnding), nil
+}
+
+// get the custom field value from fields. on DataModeMerge, will also look up from Record.Data, Record.Extra
+func (f *TextFormatter) fieldValue(r *Record, fields M, name string) (any, bool) {
+	if val, ok := fields[name]; ok || f.DataMode == DataModeNamespace {
+		return val, ok
+	}
+
+	if val, ok := r.Data[name]; ok {
+		return val, true
+	}
+	val, ok := r.Extra[name]
+	return val, ok
+}
+
+// write the fields that are not in the template as "k=v", sorted by the key.
+// the value will be quoted if it contains space, quote or "=".
+func (f *TextFormatter) writeFields(buf *bytebufferpool.ByteBuffer, fields M) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if !f.tplKeys[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for i, k := range keys {
+		val := fields[k]
+		if f.EnumAsNumber {
+			val, _ = enumValue(val, true)
+		}
+
+		if i > 0 {
+			_ = buf.WriteByte(' ')
+		}
+		buf.WriteString(k)
+		_ = buf.WriteByte('=')
+
+		str := f.EncodeFunc(val)
+		if str == "" || strings.ContainsAny(str, " \t\r\n\"=") {
+			str = strconv.Quote(str)
+		}
+		buf.WriteString(str)
+	}
+}
+
+// encodeEnums render enum-like values as number on EnumAsNumber=true
+func (f *TextFormatter) encodeEnums(mp M) M {
+	if !f.EnumAsNumber {
+		return mp
+	}
+
+	if nmp, ok := convEnums(mp, true); ok {
+		return nmp
+	}
+	return mp
+}
+
+func (f *TextFormatter) levelWidth() int {
+	if f.LevelWidth > 0 {
+		return f.LevelWidth
+	}
+
+	var width int
+	for _, name := range LevelNames {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	return width
+}
+
+// write spaces after the column text to the width. the padding is outside the color codes.
+func writePadding(buf *bytebufferpool.ByteBuffer, text string, width int) {
+	for n := utf8.RuneCountInString(text); n < width; n++ {
+		_ = buf.WriteByte(' ')
+	}
 }
 
 func (f *TextFormatter) renderColorByLevel(text string, level Level) string {
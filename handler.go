@@ -1,6 +1,9 @@
 package slog
 
-import "io"
+import (
+	"io"
+	"sync/atomic"
+)
 
 //
 // Handler interface
@@ -20,6 +23,10 @@ type Handler interface {
 	//
 	// All records may be passed to this method, and the handler should discard
 	// those that it does not want to handle.
+	//
+	// NOTICE: the record is pooled and will be reused after Handle() returned.
+	// if the handler retains it(eg: async handle), must take a copy by Record.Clone().
+	// the record is shared by all handlers, must not modify it. see Record.Frozen()
 	Handle(*Record) error
 }
 
@@ -42,11 +49,15 @@ type FormattableHandler interface {
 // LevelWithFormatter struct definition
 //
 // - support set log formatter
-// - only support set max log level
+// - support set max log level, or reset to a level list by SetLevels()
 type LevelWithFormatter struct {
 	FormattableTrait
 	// Level max for log message. if current level <= Level will log message
+	//
+	// NOTICE: please use SetLevel() for change it on runtime.
 	Level Level
+	// limit levels, set by SetLevels(). will use Level on it is empty.
+	levels atomic.Value
 }
 
 // NewLvFormatter create new LevelWithFormatter instance
@@ -54,14 +65,46 @@ func NewLvFormatter(maxLv Level) *LevelWithFormatter {
 	return &LevelWithFormatter{Level: maxLv}
 }
 
-// SetMaxLevel set max level for log message
+// SetMaxLevel set max level for log message. alias of SetLevel()
 func (h *LevelWithFormatter) SetMaxLevel(maxLv Level) {
-	h.Level = maxLv
+	h.SetLevel(maxLv)
+}
+
+// SetMinLevel set the minimum severity for log message, the level and more severe levels will be handled.
+//
+// eg: SetMinLevel(slog.WarnLevel) will handle: panic, fatal, error, warn. it is same as SetLevel()
+func (h *LevelWithFormatter) SetMinLevel(minLv Level) {
+	h.SetLevel(minLv)
+}
+
+// SetLevel set max level for log message. it is safe for concurrent use.
+//
+// will clear the levels set by SetLevels().
+func (h *LevelWithFormatter) SetLevel(maxLv Level) {
+	atomic.StoreUint32((*uint32)(&h.Level), uint32(maxLv))
+	if h.levels.Load() != nil {
+		h.levels.Store(Levels(nil))
+	}
+}
+
+// SetLevels set limit levels for log message, instead of the max level. it is safe for concurrent use.
+//
+// set empty levels will reset to use the max level.
+func (h *LevelWithFormatter) SetLevels(levels []Level) {
+	h.levels.Store(copyLevels(levels))
+}
+
+// GetLevel get max level for log message. it is safe for concurrent use.
+func (h *LevelWithFormatter) GetLevel() Level {
+	return Level(atomic.LoadUint32((*uint32)(&h.Level)))
 }
 
 // IsHandling Check if the current level can be handling
 func (h *LevelWithFormatter) IsHandling(level Level) bool {
-	return h.Level.ShouldHandling(level)
+	if ls, _ := h.levels.Load().(Levels); len(ls) > 0 {
+		return ls.Contains(level)
+	}
+	return h.GetLevel().ShouldHandling(level)
 }
 
 // LevelsWithFormatter struct definition
@@ -71,7 +114,11 @@ func (h *LevelWithFormatter) IsHandling(level Level) bool {
 type LevelsWithFormatter struct {
 	FormattableTrait
 	// Levels for log message
+	//
+	// NOTICE: please use SetLevels() for change it on runtime.
 	Levels []Level
+	// levels set on runtime, will use it instead of Levels.
+	levels atomic.Value
 }
 
 // NewLvsFormatter create new instance
@@ -79,14 +126,41 @@ func NewLvsFormatter(levels []Level) *LevelsWithFormatter {
 	return &LevelsWithFormatter{Levels: levels}
 }
 
-// SetLimitLevels set limit levels for log message
+// SetLimitLevels set limit levels for log message. alias of SetLevels()
 func (h *LevelsWithFormatter) SetLimitLevels(levels []Level) {
-	h.Levels = levels
+	h.SetLevels(levels)
+}
+
+// SetLevels set limit levels for log message. it is safe for concurrent use.
+func (h *LevelsWithFormatter) SetLevels(levels []Level) {
+	h.levels.Store(copyLevels(levels))
+}
+
+// SetMinLevel set the minimum severity for log message, the level and more severe levels will be handled.
+// it is safe for concurrent use.
+//
+// eg: SetMinLevel(slog.WarnLevel) will handle: panic, fatal, error, warn.
+func (h *LevelsWithFormatter) SetMinLevel(minLv Level) {
+	levels := make(Levels, 0, len(AllLevels))
+	for _, lv := range AllLevels {
+		if minLv.ShouldHandling(lv) {
+			levels = append(levels, lv)
+		}
+	}
+	h.levels.Store(levels)
+}
+
+// GetLevels get the current limit levels. it is safe for concurrent use.
+func (h *LevelsWithFormatter) GetLevels() []Level {
+	if ls, ok := h.levels.Load().(Levels); ok {
+		return ls
+	}
+	return h.Levels
 }
 
 // IsHandling Check if the current level can be handling
 func (h *LevelsWithFormatter) IsHandling(level Level) bool {
-	for _, l := range h.Levels {
+	for _, l := range h.GetLevels() {
 		if l == level {
 			return true
 		}
@@ -94,6 +168,13 @@ func (h *LevelsWithFormatter) IsHandling(level Level) bool {
 	return false
 }
 
+// copy the levels, avoid the input slice being changed after set.
+func copyLevels(levels []Level) Levels {
+	ls := make(Levels, len(levels))
+	copy(ls, levels)
+	return ls
+}
+
 // LevelMode define level mode
 type LevelMode uint8
 
@@ -0,0 +1,288 @@
+package handler
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gookit/slog"
+)
+
+// OverflowPolicy for the AsyncHandler, on write to a full queue
+type OverflowPolicy uint8
+
+// String get policy name
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowDropNewest:
+		return "drop_newest"
+	case OverflowDropOldest:
+		return "drop_oldest"
+	case OverflowSample:
+		return "sample"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	// OverflowBlock wait until the queue has free space. will not lose records.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drop the record being written
+	OverflowDropNewest
+	// OverflowDropOldest drop the oldest record in the queue, then write the new one
+	OverflowDropOldest
+	// OverflowSample keep every Nth record when overflowing(will block for write it), drop others.
+	OverflowSample
+)
+
+// ErrAsyncClosed error on write to a closed AsyncHandler
+var ErrAsyncClosed = errors.New("slog: the async handler has been closed")
+
+// AsyncConfig struct for the AsyncHandler
+type AsyncConfig struct {
+	// QueueSize the max pending records number. default is 1024
+	QueueSize int `json:"queue_size" yaml:"queue_size"`
+	// Overflow policy on the queue is full. default is OverflowBlock
+	Overflow OverflowPolicy `json:"overflow" yaml:"overflow"`
+	// SampleN keep every Nth record on OverflowSample. default is 10
+	SampleN uint64 `json:"sample_n" yaml:"sample_n"`
+}
+
+// AsyncStats counters of the AsyncHandler
+type AsyncStats struct {
+	// Handled records number by the inner handler
+	Handled uint64
+	// Blocked times of the write waiting for free space
+	Blocked uint64
+	// DroppedNewest records number on OverflowDropNewest
+	DroppedNewest uint64
+	// DroppedOldest records number on OverflowDropOldest
+	DroppedOldest uint64
+	// SampleKept records number on OverflowSample
+	SampleKept uint64
+	// SampleDropped records number on OverflowSample
+	SampleDropped uint64
+}
+
+// Dropped total dropped records number
+func (s AsyncStats) Dropped() uint64 {
+	return s.DroppedNewest + s.DroppedOldest + s.SampleDropped
+}
+
+// AsyncHandler wrap a handler, write log records to it on a background goroutine.
+//
+// The record is cloned before push to queue, so the logger can safely reuse it.
+type AsyncHandler struct {
+	cfg   AsyncConfig
+	inner slog.Handler
+	queue chan *slog.Record
+
+	// lock for close the queue
+	rw     sync.RWMutex
+	closed bool
+	done   chan struct{}
+
+	// pending records number, for wait on Flush()
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending int
+	lastErr error
+
+	// counters
+	overflowNum   uint64
+	handled       uint64
+	blocked       uint64
+	droppedNewest uint64
+	droppedOldest uint64
+	sampleKept    uint64
+	sampleDropped uint64
+}
+
+// NewAsyncHandler create new AsyncHandler
+//
+// Usage:
+//
+//	h := handler.NewAsyncHandler(fileHandler, func(c *handler.AsyncConfig) {
+//		c.QueueSize = 4096
+//		c.Overflow = handler.OverflowDropOldest
+//	})
+func NewAsyncHandler(inner slog.Handler, fns ...func(c *AsyncConfig)) *AsyncHandler {
+	cfg := AsyncConfig{
+		QueueSize: 1024,
+		SampleN:   10,
+	}
+	for _, fn := range fns {
+		fn(&cfg)
+	}
+
+	if cfg.QueueSize < 1 {
+		cfg.QueueSize = 1
+	}
+	if cfg.SampleN < 1 {
+		cfg.SampleN = 1
+	}
+
+	h := &AsyncHandler{
+		cfg:   cfg,
+		inner: inner,
+		queue: make(chan *slog.Record, cfg.QueueSize),
+		done:  make(chan struct{}),
+	}
+	h.cond = sync.NewCond(&h.mu)
+
+	go h.consume()
+	return h
+}
+
+// Handler get the inner handler
+func (h *AsyncHandler) Handler() slog.Handler {
+	return h.inner
+}
+
+// IsHandling Check if the current level can be handling
+func (h *AsyncHandler) IsHandling(level slog.Level) bool {
+	return h.inner.IsHandling(level)
+}
+
+// Handle push a copy of the record to queue
+func (h *AsyncHandler) Handle(r *slog.Record) error {
+	h.rw.RLock()
+	defer h.rw.RUnlock()
+	if h.closed {
+		return ErrAsyncClosed
+	}
+
+	h.addPending(1)
+	h.push(r.Clone())
+	return nil
+}
+
+func (h *AsyncHandler) push(r *slog.Record) {
+	select {
+	case h.queue <- r:
+		return
+	default: // queue is full
+	}
+
+	switch h.cfg.Overflow {
+	case OverflowDropNewest:
+		atomic.AddUint64(&h.droppedNewest, 1)
+		h.addPending(-1)
+	case OverflowDropOldest:
+		for {
+			select {
+			case <-h.queue:
+				atomic.AddUint64(&h.droppedOldest, 1)
+				h.addPending(-1)
+			default:
+			}
+
+			select {
+			case h.queue <- r:
+				return
+			default:
+			}
+		}
+	case OverflowSample:
+		if atomic.AddUint64(&h.overflowNum, 1)%h.cfg.SampleN != 0 {
+			atomic.AddUint64(&h.sampleDropped, 1)
+			h.addPending(-1)
+			return
+		}
+
+		atomic.AddUint64(&h.sampleKept, 1)
+		h.queue <- r
+	default: // OverflowBlock
+		atomic.AddUint64(&h.blocked, 1)
+		h.queue <- r
+	}
+}
+
+func (h *AsyncHandler) consume() {
+	defer close(h.done)
+
+	for r := range h.queue {
+		err := h.inner.Handle(r)
+		atomic.AddUint64(&h.handled, 1)
+
+		if err != nil {
+			printErrln("slog: async handler handle record error:", err)
+		}
+
+		h.mu.Lock()
+		if err != nil {
+			h.lastErr = err
+		}
+		h.pending--
+		if h.pending == 0 {
+			h.cond.Broadcast()
+		}
+		h.mu.Unlock()
+	}
+}
+
+func (h *AsyncHandler) addPending(n int) {
+	h.mu.Lock()
+	h.pending += n
+	if h.pending == 0 {
+		h.cond.Broadcast()
+	}
+	h.mu.Unlock()
+}
+
+// wait all queued records handled.
+func (h *AsyncHandler) wait() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for h.pending > 0 {
+		h.cond.Wait()
+	}
+
+	err := h.lastErr
+	h.lastErr = nil
+	return err
+}
+
+// Flush wait all queued records handled, then flush the inner handler
+func (h *AsyncHandler) Flush() error {
+	if err := h.wait(); err != nil {
+		return err
+	}
+	return h.inner.Flush()
+}
+
+// Close stop receive new records, wait all queued records handled, then close the inner handler.
+func (h *AsyncHandler) Close() error {
+	h.rw.Lock()
+	if h.closed {
+		h.rw.Unlock()
+		return nil
+	}
+
+	h.closed = true
+	close(h.queue)
+	h.rw.Unlock()
+
+	<-h.done
+	err := h.wait()
+	if cErr := h.inner.Close(); cErr != nil {
+		return cErr
+	}
+	return err
+}
+
+// Stats get counters of the handler
+func (h *AsyncHandler) Stats() AsyncStats {
+	return AsyncStats{
+		Handled:       atomic.LoadUint64(&h.handled),
+		Blocked:       atomic.LoadUint64(&h.blocked),
+		DroppedNewest: atomic.LoadUint64(&h.droppedNewest),
+		DroppedOldest: atomic.LoadUint64(&h.droppedOldest),
+		SampleKept:    atomic.LoadUint64(&h.sampleKept),
+		SampleDropped: atomic.LoadUint64(&h.sampleDropped),
+	}
+}
@@ -0,0 +1,179 @@
+package handler_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+// gatedHandler will block on Handle() until the gate opened.
+type gatedHandler struct {
+	handler.NopFlushClose
+	mu      sync.Mutex
+	msgs    []string
+	gate    chan struct{}
+	started chan struct{}
+}
+
+func newGatedHandler() *gatedHandler {
+	return &gatedHandler{
+		gate:    make(chan struct{}),
+		started: make(chan struct{}, 64),
+	}
+}
+
+func (h *gatedHandler) IsHandling(_ slog.Level) bool { return true }
+
+func (h *gatedHandler) Handle(r *slog.Record) error {
+	h.started <- struct{}{}
+	<-h.gate
+
+	h.mu.Lock()
+	h.msgs = append(h.msgs, r.Message)
+	h.mu.Unlock()
+	return nil
+}
+
+func (h *gatedHandler) Messages() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.msgs...)
+}
+
+// write msg1 and wait it in handling, then fill the queue by msg2, msg3
+func fillAsyncQueue(t *testing.T, ah *handler.AsyncHandler, gh *gatedHandler) {
+	assert.NoErr(t, ah.Handle(newLogRecord("msg1")))
+	<-gh.started
+	assert.NoErr(t, ah.Handle(newLogRecord("msg2")))
+	assert.NoErr(t, ah.Handle(newLogRecord("msg3")))
+}
+
+func openGateAfter(gh *gatedHandler, dur time.Duration) {
+	go func() {
+		time.Sleep(dur)
+		close(gh.gate)
+	}()
+}
+
+func TestAsyncHandler_OverflowBlock(t *testing.T) {
+	gh := newGatedHandler()
+	ah := handler.NewAsyncHandler(gh, func(c *handler.AsyncConfig) {
+		c.QueueSize = 2
+	})
+	assert.True(t, ah.IsHandling(slog.InfoLevel))
+	assert.Eq(t, "block", handler.OverflowBlock.String())
+
+	fillAsyncQueue(t, ah, gh)
+	openGateAfter(gh, 30*time.Millisecond)
+	assert.NoErr(t, ah.Handle(newLogRecord("msg4")))
+
+	assert.NoErr(t, ah.Close())
+	assert.Eq(t, []string{"msg1", "msg2", "msg3", "msg4"}, gh.Messages())
+
+	st := ah.Stats()
+	assert.Eq(t, uint64(1), st.Blocked)
+	assert.Eq(t, uint64(4), st.Handled)
+	assert.Eq(t, uint64(0), st.Dropped())
+
+	// write after closed
+	assert.Err(t, ah.Handle(newLogRecord("msg5")))
+	assert.NoErr(t, ah.Close())
+}
+
+func TestAsyncHandler_OverflowDropNewest(t *testing.T) {
+	gh := newGatedHandler()
+	ah := handler.NewAsyncHandler(gh, func(c *handler.AsyncConfig) {
+		c.QueueSize = 2
+		c.Overflow = handler.OverflowDropNewest
+	})
+
+	fillAsyncQueue(t, ah, gh)
+	assert.NoErr(t, ah.Handle(newLogRecord("msg4")))
+	assert.NoErr(t, ah.Handle(newLogRecord("msg5")))
+
+	close(gh.gate)
+	assert.NoErr(t, ah.Flush())
+	assert.Eq(t, []string{"msg1", "msg2", "msg3"}, gh.Messages())
+	assert.Eq(t, uint64(2), ah.Stats().DroppedNewest)
+	assert.NoErr(t, ah.Close())
+}
+
+func TestAsyncHandler_OverflowDropOldest(t *testing.T) {
+	gh := newGatedHandler()
+	ah := handler.NewAsyncHandler(gh, func(c *handler.AsyncConfig) {
+		c.QueueSize = 2
+		c.Overflow = handler.OverflowDropOldest
+	})
+
+	fillAsyncQueue(t, ah, gh)
+	assert.NoErr(t, ah.Handle(newLogRecord("msg4")))
+	assert.NoErr(t, ah.Handle(newLogRecord("msg5")))
+
+	close(gh.gate)
+	assert.NoErr(t, ah.Flush())
+	assert.Eq(t, []string{"msg1", "msg4", "msg5"}, gh.Messages())
+	assert.Eq(t, uint64(2), ah.Stats().DroppedOldest)
+	assert.NoErr(t, ah.Close())
+}
+
+func TestAsyncHandler_OverflowSample(t *testing.T) {
+	gh := newGatedHandler()
+	ah := handler.NewAsyncHandler(gh, func(c *handler.AsyncConfig) {
+		c.QueueSize = 2
+		c.Overflow = handler.OverflowSample
+		c.SampleN = 2
+	})
+
+	fillAsyncQueue(t, ah, gh)
+	// overflow 1: dropped
+	assert.NoErr(t, ah.Handle(newLogRecord("msg4")))
+	// overflow 2: kept, will wait for free space
+	openGateAfter(gh, 30*time.Millisecond)
+	assert.NoErr(t, ah.Handle(newLogRecord("msg5")))
+
+	assert.NoErr(t, ah.Close())
+	assert.Eq(t, []string{"msg1", "msg2", "msg3", "msg5"}, gh.Messages())
+
+	st := ah.Stats()
+	assert.Eq(t, uint64(1), st.SampleKept)
+	assert.Eq(t, uint64(1), st.SampleDropped)
+}
+
+func TestAsyncHandler_cloneRecord(t *testing.T) {
+	w := new(syncMsgs)
+	ah := handler.NewAsyncHandler(w)
+
+	r := newLogRecord("original message")
+	assert.NoErr(t, ah.Handle(r))
+	// modify after handle
+	r.Message = "modified message"
+
+	assert.NoErr(t, ah.Flush())
+	assert.Eq(t, []string{"original message"}, w.Messages())
+	assert.NoErr(t, ah.Close())
+}
+
+type syncMsgs struct {
+	handler.NopFlushClose
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (h *syncMsgs) IsHandling(_ slog.Level) bool { return true }
+
+func (h *syncMsgs) Handle(r *slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.msgs = append(h.msgs, r.Message)
+	return nil
+}
+
+func (h *syncMsgs) Messages() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.msgs...)
+}
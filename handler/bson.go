@@ -0,0 +1,289 @@
+package handler
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gookit/slog"
+)
+
+// a minimal BSON encoder and decoder for the MongoHandler.
+//
+// refer: https://bsonspec.org/spec.html
+
+// bsonElem an element of the ordered BSON document
+type bsonElem struct {
+	Key string
+	Val any
+}
+
+// bsonDoc an ordered BSON document. eg: the command document, the command name must be first.
+type bsonDoc []bsonElem
+
+// append the ordered document
+func appendBSONDoc(b []byte, doc bsonDoc) []byte {
+	start := len(b)
+	b = append(b, 0, 0, 0, 0) // placeholder for the length
+	for _, el := range doc {
+		b = appendBSONElem(b, el.Key, el.Val)
+	}
+	b = append(b, 0x00)
+
+	binary.LittleEndian.PutUint32(b[start:], uint32(len(b)-start))
+	return b
+}
+
+// append the map as document, the keys are sorted.
+func appendBSONMap(b []byte, mp map[string]any) []byte {
+	keys := make([]string, 0, len(mp))
+	for k := range mp {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	doc := make(bsonDoc, 0, len(keys))
+	for _, k := range keys {
+		doc = append(doc, bsonElem{Key: k, Val: mp[k]})
+	}
+	return appendBSONDoc(b, doc)
+}
+
+func appendBSONArray(b []byte, n int, item func(i int) any) []byte {
+	doc := make(bsonDoc, n)
+	for i := 0; i < n; i++ {
+		doc[i] = bsonElem{Key: strconv.Itoa(i), Val: item(i)}
+	}
+	return appendBSONDoc(b, doc)
+}
+
+func appendBSONCString(b []byte, s string) []byte {
+	return append(append(b, s...), 0x00)
+}
+
+func appendBSONKey(b []byte, typ byte, key string) []byte {
+	return appendBSONCString(append(b, typ), key)
+}
+
+func appendBSONStr(b []byte, key, s string) []byte {
+	b = appendBSONKey(b, 0x02, key)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(s)+1))
+	return appendBSONCString(b, s)
+}
+
+func appendBSONInt(b []byte, key string, v int64) []byte {
+	if v >= math.MinInt32 && v <= math.MaxInt32 {
+		return binary.LittleEndian.AppendUint32(appendBSONKey(b, 0x10, key), uint32(int32(v)))
+	}
+	return binary.LittleEndian.AppendUint64(appendBSONKey(b, 0x12, key), uint64(v))
+}
+
+func appendBSONUint(b []byte, key string, v uint64) []byte {
+	if v <= math.MaxInt64 {
+		return appendBSONInt(b, key, int64(v))
+	}
+	// out of the int64 range
+	return appendBSONFloat(b, key, float64(v))
+}
+
+func appendBSONFloat(b []byte, key string, v float64) []byte {
+	return binary.LittleEndian.AppendUint64(appendBSONKey(b, 0x01, key), math.Float64bits(v))
+}
+
+// append the time as BSON UTC datetime, the milliseconds since the Unix epoch.
+func appendBSONTime(b []byte, key string, t time.Time) []byte {
+	return binary.LittleEndian.AppendUint64(appendBSONKey(b, 0x09, key), uint64(t.UnixMilli()))
+}
+
+// append any value with key. the unsupported value will be encoded as string by fmt.
+func appendBSONElem(b []byte, key string, v any) []byte {
+	switch tv := v.(type) {
+	case nil:
+		return appendBSONKey(b, 0x0a, key)
+	case bool:
+		b = appendBSONKey(b, 0x08, key)
+		if tv {
+			return append(b, 0x01)
+		}
+		return append(b, 0x00)
+	case string:
+		return appendBSONStr(b, key, tv)
+	case []byte:
+		b = appendBSONKey(b, 0x05, key)
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(tv)))
+		return append(append(b, 0x00), tv...) // subtype: generic binary
+	case int:
+		return appendBSONInt(b, key, int64(tv))
+	case int8:
+		return appendBSONInt(b, key, int64(tv))
+	case int16:
+		return appendBSONInt(b, key, int64(tv))
+	case int32:
+		return appendBSONInt(b, key, int64(tv))
+	case int64:
+		return appendBSONInt(b, key, tv)
+	case uint:
+		return appendBSONUint(b, key, uint64(tv))
+	case uint8:
+		return appendBSONUint(b, key, uint64(tv))
+	case uint16:
+		return appendBSONUint(b, key, uint64(tv))
+	case uint32:
+		return appendBSONUint(b, key, uint64(tv))
+	case uint64:
+		return appendBSONUint(b, key, tv)
+	case float32:
+		return appendBSONFloat(b, key, float64(tv))
+	case float64:
+		return appendBSONFloat(b, key, tv)
+	case time.Time:
+		return appendBSONTime(b, key, tv)
+	case error:
+		return appendBSONStr(b, key, tv.Error())
+	case bsonDoc:
+		return appendBSONDoc(appendBSONKey(b, 0x03, key), tv)
+	case map[string]any:
+		return appendBSONMap(appendBSONKey(b, 0x03, key), tv)
+	case slog.M:
+		return appendBSONMap(appendBSONKey(b, 0x03, key), tv)
+	case []any:
+		return appendBSONArray(appendBSONKey(b, 0x04, key), len(tv), func(i int) any { return tv[i] })
+	case fmt.Stringer:
+		return appendBSONStr(b, key, tv.String())
+	}
+
+	// other slice and map types
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		b = appendBSONKey(b, 0x04, key)
+		return appendBSONArray(b, rv.Len(), func(i int) any { return rv.Index(i).Interface() })
+	case reflect.Map:
+		mp := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			mp[fmt.Sprint(iter.Key().Interface())] = iter.Value().Interface()
+		}
+		return appendBSONMap(appendBSONKey(b, 0x03, key), mp)
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return appendBSONKey(b, 0x0a, key)
+		}
+		return appendBSONElem(b, key, rv.Elem().Interface())
+	}
+	return appendBSONStr(b, key, fmt.Sprint(v))
+}
+
+var errBSONFormat = errors.New("slog: invalid bson document")
+
+// read the BSON document to map. only the common types are supported, others will be skipped as nil.
+// the array will be decoded as []any.
+func readBSONDoc(bs []byte) (map[string]any, error) {
+	if len(bs) < 5 || int(binary.LittleEndian.Uint32(bs)) != len(bs) {
+		return nil, errBSONFormat
+	}
+
+	mp := make(map[string]any)
+	pos := 4
+	for pos < len(bs)-1 {
+		typ := bs[pos]
+		pos++
+
+		end := pos
+		for end < len(bs) && bs[end] != 0x00 {
+			end++
+		}
+		if end >= len(bs) {
+			return nil, errBSONFormat
+		}
+		key := string(bs[pos:end])
+		pos = end + 1
+
+		val, n, err := readBSONValue(typ, bs[pos:])
+		if err != nil {
+			return nil, err
+		}
+		mp[key] = val
+		pos += n
+	}
+	return mp, nil
+}
+
+// read a value by type, returns the value and the read bytes length.
+func readBSONValue(typ byte, bs []byte) (val any, n int, err error) {
+	need := func(size int) bool { return len(bs) >= size }
+
+	switch typ {
+	case 0x01: // double
+		if !need(8) {
+			break
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(bs)), 8, nil
+	case 0x02: // string
+		if !need(4) {
+			break
+		}
+		size := int(binary.LittleEndian.Uint32(bs))
+		if size < 1 || !need(4+size) {
+			break
+		}
+		return string(bs[4 : 4+size-1]), 4 + size, nil
+	case 0x03, 0x04: // document, array
+		if !need(4) {
+			break
+		}
+		size := int(binary.LittleEndian.Uint32(bs))
+		if !need(size) {
+			break
+		}
+		mp, err := readBSONDoc(bs[:size])
+		if err != nil || typ == 0x03 {
+			return mp, size, err
+		}
+
+		arr := make([]any, len(mp))
+		for i := range arr {
+			arr[i] = mp[strconv.Itoa(i)]
+		}
+		return arr, size, nil
+	case 0x05: // binary
+		if !need(5) {
+			break
+		}
+		size := int(binary.LittleEndian.Uint32(bs))
+		if !need(5 + size) {
+			break
+		}
+		return bs[5 : 5+size], 5 + size, nil
+	case 0x07: // ObjectId
+		if need(12) {
+			return nil, 12, nil
+		}
+	case 0x08: // bool
+		if need(1) {
+			return bs[0] == 0x01, 1, nil
+		}
+	case 0x09: // UTC datetime
+		if need(8) {
+			return time.UnixMilli(int64(binary.LittleEndian.Uint64(bs))), 8, nil
+		}
+	case 0x0a: // null
+		return nil, 0, nil
+	case 0x10: // int32
+		if need(4) {
+			return int32(binary.LittleEndian.Uint32(bs)), 4, nil
+		}
+	case 0x11, 0x12: // timestamp, int64
+		if need(8) {
+			return int64(binary.LittleEndian.Uint64(bs)), 8, nil
+		}
+	default:
+		return nil, 0, fmt.Errorf("slog: unsupported bson type 0x%02x", typ)
+	}
+	return nil, 0, errBSONFormat
+}
@@ -3,6 +3,8 @@ package handler
 import (
 	"io"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/gookit/slog"
 	"github.com/gookit/slog/bufwrite"
@@ -71,3 +73,162 @@ type FormatWriterHandler interface {
 	// Writer the output writer
 	Writer() io.Writer
 }
+
+// BufferedHandler wrap a FormatWriterHandler, collect the formatted records into a buffer,
+// then write to the inner handler writer when the buffer is full or on each flush interval.
+//
+// Only whole records are written, the record will never be split to multiple writes.
+// if the inner handler implements sync.Locker(eg: SyncCloseHandler, handlers embed LockWrapper),
+// the buffer will be written by its lock.
+type BufferedHandler struct {
+	inner    FormatWriterHandler
+	bufSize  int
+	interval time.Duration
+
+	mu     sync.Mutex
+	buf    []byte
+	closed bool
+	// for stop the flush ticker
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// BufferWrapper create new BufferedHandler, wrap the handler with buffer.
+//
+//   - bufSize the buffer size in bytes, default is DefaultBufferSize
+//   - interval write the buffer to inner handler on each interval, set 0 to disable
+//
+// Usage:
+//
+//	fh := handler.MustFileHandler("testdata/app.log", handler.WithBuffSize(0))
+//	h := handler.BufferWrapper(fh, 64*1024, 3*time.Second)
+func BufferWrapper(h FormatWriterHandler, bufSize int, interval time.Duration) *BufferedHandler {
+	if bufSize <= 0 {
+		bufSize = DefaultBufferSize
+	}
+
+	bh := &BufferedHandler{
+		inner:    h,
+		bufSize:  bufSize,
+		interval: interval,
+		buf:      make([]byte, 0, bufSize),
+	}
+
+	if interval > 0 {
+		bh.stopCh = make(chan struct{})
+		bh.wg.Add(1)
+		go bh.flushDaemon()
+	}
+	return bh
+}
+
+// Handler get the inner handler
+func (h *BufferedHandler) Handler() slog.Handler {
+	return h.inner
+}
+
+// BufSize get the buffer size
+func (h *BufferedHandler) BufSize() int {
+	return h.bufSize
+}
+
+// Buffered get the buffered bytes number
+func (h *BufferedHandler) Buffered() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.buf)
+}
+
+// IsHandling Check if the current level can be handling
+func (h *BufferedHandler) IsHandling(level slog.Level) bool {
+	return h.inner.IsHandling(level)
+}
+
+// Handle a log record. format it by the inner handler formatter, then add to buffer.
+func (h *BufferedHandler) Handle(r *slog.Record) error {
+	bts, err := h.inner.Formatter().Format(r)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// write buffered records first, keep the record boundaries
+	if len(h.buf) > 0 && len(h.buf)+len(bts) > h.bufSize {
+		if err = h.writeBuf(); err != nil {
+			return err
+		}
+	}
+
+	h.buf = append(h.buf, bts...)
+	if len(h.buf) >= h.bufSize {
+		return h.writeBuf()
+	}
+	return nil
+}
+
+// write the buffer to inner writer. should be in lock
+func (h *BufferedHandler) writeBuf() error {
+	if len(h.buf) == 0 {
+		return nil
+	}
+
+	// keep the writes of the inner handler serialized
+	if lk, ok := h.inner.(sync.Locker); ok {
+		lk.Lock()
+		defer lk.Unlock()
+	}
+
+	_, err := h.inner.Writer().Write(h.buf)
+	h.buf = h.buf[:0]
+	return err
+}
+
+// Flush write the buffer to inner handler, then flush the inner handler
+func (h *BufferedHandler) Flush() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.writeBuf(); err != nil {
+		return err
+	}
+	return h.inner.Flush()
+}
+
+// Close the handler. will flush the buffer, then stop the flush ticker and close the inner handler.
+func (h *BufferedHandler) Close() error {
+	if err := h.Flush(); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+
+	h.closed = true
+	if h.stopCh != nil {
+		close(h.stopCh)
+	}
+	h.mu.Unlock()
+
+	h.wg.Wait()
+	return h.inner.Close()
+}
+
+func (h *BufferedHandler) flushDaemon() {
+	defer h.wg.Done()
+	tk := time.NewTicker(h.interval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			printErrln("slog: buffered handler flush error:", h.Flush())
+		case <-h.stopCh:
+			return
+		}
+	}
+}
@@ -2,7 +2,10 @@ package handler_test
 
 import (
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gookit/goutil/fsutil"
 	"github.com/gookit/goutil/testutil/assert"
@@ -115,3 +118,112 @@ func TestLineBuffWriter(t *testing.T) {
 		handler.LineBuffOsFile(nil, 12, slog.AllLevels)
 	})
 }
+
+type countWriter struct {
+	mu     sync.Mutex
+	writes []string
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func (w *countWriter) Writes() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writes
+}
+
+type countSyncWriter struct {
+	*countWriter
+}
+
+func (w countSyncWriter) Sync() error  { return nil }
+func (w countSyncWriter) Close() error { return nil }
+
+func TestBufferWrapper(t *testing.T) {
+	w := &countWriter{}
+	ih := handler.NewIOWriter(w, slog.AllLevels)
+	ih.SetFormatter(newTestFormatter())
+
+	h := handler.BufferWrapper(ih, 20, 0)
+	assert.Eq(t, 20, h.BufSize())
+	assert.True(t, h.IsHandling(slog.InfoLevel))
+
+	assert.NoErr(t, h.Handle(newLogRecord("message1\n")))
+	assert.NoErr(t, h.Handle(newLogRecord("message2\n")))
+	assert.Empty(t, w.Writes())
+	assert.Eq(t, 18, h.Buffered())
+
+	// exceeds the buffer size, write the buffered records first
+	assert.NoErr(t, h.Handle(newLogRecord("message3\n")))
+	assert.Eq(t, []string{"message1\nmessage2\n"}, w.Writes())
+	assert.Eq(t, 9, h.Buffered())
+
+	// large record
+	assert.NoErr(t, h.Handle(newLogRecord("a long message, exceeds buffer size\n")))
+	assert.Eq(t, []string{
+		"message1\nmessage2\n",
+		"message3\n",
+		"a long message, exceeds buffer size\n",
+	}, w.Writes())
+
+	assert.NoErr(t, h.Handle(newLogRecord("message4\n")))
+	assert.NoErr(t, h.Close())
+	assert.Len(t, w.Writes(), 4)
+	assert.Eq(t, 0, h.Buffered())
+	assert.NoErr(t, h.Close())
+}
+
+func TestBufferWrapper_innerLock(t *testing.T) {
+	w := &countWriter{}
+	ih := handler.NewSyncCloser(countSyncWriter{w}, slog.AllLevels)
+	ih.SetFormatter(newTestFormatter())
+
+	h := handler.BufferWrapper(ih, 0, 0)
+	assert.Eq(t, ih, h.Handler())
+	assert.NoErr(t, h.Handle(newLogRecord("message\n")))
+
+	// write the buffer by the inner handler lock
+	ih.Lock()
+	done := make(chan error)
+	go func() { done <- h.Flush() }()
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, w.Writes())
+	ih.Unlock()
+
+	assert.NoErr(t, <-done)
+	assert.Eq(t, []string{"message\n"}, w.Writes())
+	assert.NoErr(t, h.Close())
+}
+
+func TestBufferWrapper_interval(t *testing.T) {
+	w := &countWriter{}
+	ih := handler.NewIOWriter(w, slog.AllLevels)
+	ih.SetFormatter(newTestFormatter())
+
+	h := handler.BufferWrapper(ih, 0, 20*time.Millisecond)
+	assert.Eq(t, handler.DefaultBufferSize, h.BufSize())
+
+	l := slog.NewWithHandlers(h)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Info("message\n")
+		}()
+	}
+	wg.Wait()
+	assert.Empty(t, w.Writes())
+
+	time.Sleep(60 * time.Millisecond)
+	writes := w.Writes()
+	assert.Len(t, writes, 1)
+	assert.Eq(t, strings.Repeat("message\n", 10), writes[0])
+	assert.NoErr(t, h.Close())
+}
@@ -133,7 +133,9 @@ func (b *Builder) buildFromWriter(w io.Writer) (h slog.FormattableHandler) {
 			scw = b.wrapBuffer(scw)
 		}
 
-		h = NewSyncCloserWithLF(scw, lf)
+		sh := NewSyncCloserWithLF(scw, lf)
+		sh.StartFlusher(b.FlushInterval)
+		h = sh
 	} else if fcw, ok := w.(FlushCloseWriter); ok {
 		if bufSize > 0 {
 			fcw = b.wrapBuffer(fcw)
@@ -3,6 +3,7 @@ package handler
 import (
 	"io"
 	"io/fs"
+	"time"
 
 	"github.com/gookit/goutil/errorx"
 	"github.com/gookit/goutil/fsutil"
@@ -53,6 +54,17 @@ type Config struct {
 	// BuffSize for enable buffer, unit is bytes. set 0 to disable buffer
 	BuffSize int `json:"buff_size" yaml:"buff_size"`
 
+	// FlushInterval flush the handler in a background goroutine on each interval, it is stopped on Close().
+	// it bounds the data loss on crash to the interval. set 0 to disable, default is 0.
+	//
+	// see SyncCloseHandler.StartFlusher()
+	FlushInterval time.Duration `json:"flush_interval" yaml:"flush_interval"`
+
+	// WriteTimeout for write to the logfile. set 0 to disable timeout
+	//
+	// see TimeoutWriter for the abandoned write goroutine risk.
+	WriteTimeout time.Duration `json:"write_timeout" yaml:"write_timeout"`
+
 	// RotateTime for rotate file, unit is seconds.
 	RotateTime rotatefile.RotateTime `json:"rotate_time" yaml:"rotate_time"`
 
@@ -66,6 +78,12 @@ type Config struct {
 	// The default is not to perform compression.
 	Compress bool `json:"compress" yaml:"compress"`
 
+	// CompressLevel the gzip compression level. 0 is use gzip.DefaultCompression
+	CompressLevel int `json:"compress_level" yaml:"compress_level"`
+
+	// AsyncCompress compress the rotated file in background. see rotatefile.Config.AsyncCompress
+	AsyncCompress bool `json:"async_compress" yaml:"async_compress"`
+
 	// BackupNum max number for keep old files.
 	//
 	// 0 is not limit, default is 20.
@@ -76,6 +94,19 @@ type Config struct {
 	// 0 is not limit, default is a week.
 	BackupTime uint `json:"backup_time" yaml:"backup_time"`
 
+	// MaxTotalSize max total size for keep old files, unit is MB.
+	//
+	// 0 is not limit.
+	MaxTotalSize uint `json:"max_total_size" yaml:"max_total_size"`
+
+	// SymlinkPath create a symlink to the current active log file. see rotatefile.Config.SymlinkPath
+	SymlinkPath string `json:"symlink_path" yaml:"symlink_path"`
+
+	// TimeLocation for format the rotate file suffix. default is nil, use local time.
+	//
+	// Tip: should be same as the Logger.TimeLocation
+	TimeLocation *time.Location `json:"-" yaml:"-"`
+
 	// RenameFunc build filename for rotate file
 	RenameFunc func(filepath string, rotateNum uint) string
 
@@ -142,6 +173,8 @@ func (c *Config) CreateHandler() (*SyncCloseHandler, error) {
 	if c.UseJSON {
 		h.SetFormatter(slog.NewJSONFormatter())
 	}
+
+	h.StartFlusher(c.FlushInterval)
 	return h, nil
 }
 
@@ -179,11 +212,18 @@ func (c *Config) CreateWriter() (output SyncCloseWriter, err error) {
 		rc.RotateMode = c.RotateMode
 		rc.BackupNum = c.BackupNum
 		rc.BackupTime = c.BackupTime
+		rc.MaxTotalSize = c.MaxTotalSize
+		rc.SymlinkPath = c.SymlinkPath
 		rc.Compress = c.Compress
+		rc.CompressLevel = c.CompressLevel
+		rc.AsyncCompress = c.AsyncCompress
 
 		if c.RenameFunc != nil {
 			rc.RenameFunc = c.RenameFunc
 		}
+		if c.TimeLocation != nil {
+			rc.TimeClock = rotatefile.ClockInLocation(c.TimeLocation)
+		}
 
 		// create a rotating writer
 		output, err = rc.Create()
@@ -195,6 +235,11 @@ func (c *Config) CreateWriter() (output SyncCloseWriter, err error) {
 		return nil, err
 	}
 
+	// wrap timeout
+	if c.WriteTimeout > 0 {
+		output = NewTimeoutWriter(output, c.WriteTimeout)
+	}
+
 	// wrap buffer
 	if c.BuffSize > 0 {
 		output = c.wrapBuffer(output)
@@ -294,6 +339,16 @@ func WithBuffSize(buffSize int) ConfigFn {
 	return func(c *Config) { c.BuffSize = buffSize }
 }
 
+// WithWriteTimeout setting
+func WithWriteTimeout(timeout time.Duration) ConfigFn {
+	return func(c *Config) { c.WriteTimeout = timeout }
+}
+
+// WithFlushInterval setting
+func WithFlushInterval(interval time.Duration) ConfigFn {
+	return func(c *Config) { c.FlushInterval = interval }
+}
+
 // WithMaxSize setting
 func WithMaxSize(maxSize uint64) ConfigFn {
 	return func(c *Config) { c.MaxSize = maxSize }
@@ -304,6 +359,11 @@ func WithCompress(compress bool) ConfigFn {
 	return func(c *Config) { c.Compress = compress }
 }
 
+// WithTimeLocation setting
+func WithTimeLocation(loc *time.Location) ConfigFn {
+	return func(c *Config) { c.TimeLocation = loc }
+}
+
 // WithUseJSON setting
 func WithUseJSON(useJSON bool) ConfigFn {
 	return func(c *Config) { c.UseJSON = useJSON }
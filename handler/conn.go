@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// the common timeout helpers for the network handlers. the remote stalls will
+// return a timeout error, rather than blocking the logging goroutine forever.
+//
+// NOTICE: the timeout <= 0 means no timeout.
+
+// dial the address with connect timeout. will connect by TLS on the tlsCfg is not nil.
+func dialTimeout(network, addr string, timeout time.Duration, tlsCfg *tls.Config) (net.Conn, error) {
+	// the net.Dialer treat negative timeout as a deadline in the past
+	if timeout < 0 {
+		timeout = 0
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	if tlsCfg != nil {
+		return tls.DialWithDialer(dialer, network, addr, tlsCfg)
+	}
+	return dialer.Dial(network, addr)
+}
+
+// set the read and write deadline for one request-response round on the conn.
+func setConnDeadline(conn net.Conn, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
+	return conn.SetDeadline(time.Now().Add(timeout))
+}
+
+// write the data to conn with write deadline.
+func writeTimeout(conn net.Conn, data []byte, timeout time.Duration) (int, error) {
+	if timeout > 0 {
+		if err := conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+			return 0, err
+		}
+	}
+	return conn.Write(data)
+}
+
+// get the custom http client, or create new client with the request timeout.
+func httpClientOr(c *http.Client, timeout time.Duration) *http.Client {
+	if c != nil {
+		return c
+	}
+	return &http.Client{Timeout: timeout}
+}
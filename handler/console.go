@@ -3,7 +3,6 @@ package handler
 import (
 	"os"
 
-	"github.com/gookit/color"
 	"github.com/gookit/slog"
 )
 
@@ -18,12 +17,11 @@ type ConsoleHandler = IOWriterHandler
 func NewConsoleWithLF(lf slog.LevelFormattable) *ConsoleHandler {
 	h := NewIOWriterWithLF(os.Stdout, lf)
 
-	// default use text formatter
-	f := slog.NewTextFormatter()
-	// default enable color on console
-	f.WithEnableColor(color.SupportColor())
-
-	h.SetFormatter(f)
+	// default use text formatter, it can be replaced by the logger default formatter.
+	// default enable color on console is a terminal
+	if f, ok := h.Formatter().(*slog.TextFormatter); ok {
+		f.SetColorMode(slog.ColorModeAuto, os.Stdout)
+	}
 	return h
 }
 
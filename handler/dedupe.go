@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gookit/slog"
+)
+
+// DedupeKeyFunc build the dedupe key for a record.
+// return empty string will not dedupe the record.
+type DedupeKeyFunc func(r *slog.Record) string
+
+// DedupeByCaller use the caller PC + level as the dedupe key.
+// so messages that only differ in interpolated values will collapse.
+//
+// NOTICE: requires caller capture to be enabled(Logger.ReportCaller=true)
+func DedupeByCaller(r *slog.Record) string {
+	if r.Caller == nil || r.Caller.PC == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(r.Caller.PC), 16) + ":" + strconv.Itoa(int(r.Level))
+}
+
+// DedupeByMessage use the message as the dedupe key.
+func DedupeByMessage(r *slog.Record) string {
+	return r.Message
+}
+
+// DedupeByMessageLevelChannel use the message + level + channel as the dedupe key.
+func DedupeByMessageLevelChannel(r *slog.Record) string {
+	return r.Channel + ":" + strconv.Itoa(int(r.Level)) + ":" + r.Message
+}
+
+// DedupeConfig for the DedupeHandler
+type DedupeConfig struct {
+	// Key func for build dedupe key. default is DedupeByCaller
+	Key DedupeKeyFunc `json:"-" yaml:"-"`
+	// Window the max duration for suppress same key records, start from the first forwarded record.
+	// the record after window closed will emit the summary and be forwarded.
+	//
+	// default is 0, not limit.
+	Window time.Duration `json:"window" yaml:"window"`
+}
+
+// DedupeHandler wrap a handler, collapse the consecutive records with same dedupe key.
+//
+// The first record will be forwarded, the following same key records are suppressed.
+// When the key changed, the Window closed or on Close(), will emit a summary record like:
+//
+//	processing item 5 (repeated 4 times)
+//
+// NOTICE: Flush() does not emit the summary and end the dedupe run, because the Logger
+// flushes the handlers after each error record.
+type DedupeHandler struct {
+	cfg   DedupeConfig
+	inner slog.Handler
+
+	mu      sync.Mutex
+	lastKey string
+	// start time of the current window
+	startAt time.Time
+	// last suppressed record and count
+	last  *slog.Record
+	count int
+}
+
+// NewDedupeHandler create new DedupeHandler
+//
+// Usage:
+//
+//	h := handler.NewDedupeHandler(fileHandler)
+//
+//	// dedupe by message in 10 seconds window
+//	h := handler.NewDedupeHandler(fileHandler, func(c *handler.DedupeConfig) {
+//		c.Key = handler.DedupeByMessage
+//		c.Window = 10 * time.Second
+//	})
+func NewDedupeHandler(inner slog.Handler, fns ...func(c *DedupeConfig)) *DedupeHandler {
+	cfg := DedupeConfig{
+		Key: DedupeByCaller,
+	}
+	for _, fn := range fns {
+		fn(&cfg)
+	}
+
+	return &DedupeHandler{cfg: cfg, inner: inner}
+}
+
+// Handler get the inner handler
+func (h *DedupeHandler) Handler() slog.Handler {
+	return h.inner
+}
+
+// IsHandling Check if the current level can be handling
+func (h *DedupeHandler) IsHandling(level slog.Level) bool {
+	return h.inner.IsHandling(level)
+}
+
+// Handle a log record
+func (h *DedupeHandler) Handle(r *slog.Record) error {
+	key := h.cfg.Key(r)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if key != "" && key == h.lastKey && h.inWindow(r.Time) {
+		// the record will be released after handled, so must copy it.
+		h.last = r.Clone()
+		h.count++
+		return nil
+	}
+
+	if err := h.emitSummary(); err != nil {
+		return err
+	}
+
+	h.lastKey, h.startAt = key, r.Time
+	return h.inner.Handle(r)
+}
+
+func (h *DedupeHandler) inWindow(t time.Time) bool {
+	return h.cfg.Window <= 0 || t.Sub(h.startAt) < h.cfg.Window
+}
+
+// emit the summary of the suppressed records. should be in lock.
+func (h *DedupeHandler) emitSummary() error {
+	if h.count == 0 {
+		return nil
+	}
+
+	r := h.last
+	if h.count == 1 {
+		r.Message += " (repeated 1 time)"
+	} else {
+		r.Message += " (repeated " + strconv.Itoa(h.count) + " times)"
+	}
+
+	h.last, h.count = nil, 0
+	return h.inner.Handle(r)
+}
+
+// Flush the inner handler. the pending summary is kept, see DedupeHandler
+func (h *DedupeHandler) Flush() error {
+	return h.inner.Flush()
+}
+
+// Close emit the pending summary, then close the inner handler
+func (h *DedupeHandler) Close() error {
+	h.mu.Lock()
+	err := h.emitSummary()
+	h.lastKey = ""
+	h.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	return h.inner.Close()
+}
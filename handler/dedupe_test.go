@@ -0,0 +1,155 @@
+package handler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+func TestDedupeHandler_byCaller(t *testing.T) {
+	w := new(syncMsgs)
+	h := handler.NewDedupeHandler(w)
+	assert.True(t, h.IsHandling(slog.InfoLevel))
+
+	l := slog.NewWithHandlers(h)
+	for i := 1; i <= 5; i++ {
+		l.Infof("processing item %d", i)
+	}
+	l.Info("done")
+	// same site, but different level
+	for i := 0; i < 2; i++ {
+		l.Log(slog.Level(400+i*100), "level changed")
+	}
+	assert.NoErr(t, h.Flush())
+
+	assert.Eq(t, []string{
+		"processing item 1",
+		"processing item 5 (repeated 4 times)",
+		"done",
+		"level changed",
+		"level changed",
+	}, w.Messages())
+
+	// summary on close
+	for i := 0; i < 3; i++ {
+		l.Info("close message")
+	}
+	assert.NoErr(t, h.Close())
+	assert.Eq(t, "close message (repeated 2 times)", w.Messages()[6])
+}
+
+func TestDedupeHandler_byCallerError(t *testing.T) {
+	w := new(syncMsgs)
+	h := handler.NewDedupeHandler(w)
+
+	// the logger flush the handlers after each error record
+	l := slog.NewWithHandlers(h)
+	for i := 1; i <= 5; i++ {
+		l.Errorf("item %d failed", i)
+	}
+	l.Error("done")
+	assert.NoErr(t, l.Close())
+
+	assert.Eq(t, []string{
+		"item 1 failed",
+		"item 5 failed (repeated 4 times)",
+		"done",
+	}, w.Messages())
+}
+
+func TestDedupeHandler_noCaller(t *testing.T) {
+	w := new(syncMsgs)
+	h := handler.NewDedupeHandler(w)
+
+	l := slog.NewWithHandlers(h)
+	l.ReportCaller = false
+	for i := 1; i <= 3; i++ {
+		l.Infof("processing item %d", i)
+	}
+
+	assert.NoErr(t, h.Flush())
+	assert.Len(t, w.Messages(), 3)
+}
+
+func TestDedupeHandler_byMessageWindow(t *testing.T) {
+	w := new(syncMsgs)
+	h := handler.NewDedupeHandler(w, func(c *handler.DedupeConfig) {
+		c.Key = handler.DedupeByMessage
+		c.Window = time.Second
+	})
+
+	now := time.Now()
+	handle := func(msg string, level slog.Level, offset time.Duration) {
+		r := newLogRecord(msg)
+		r.Level = level
+		r.Time = now.Add(offset)
+		assert.NoErr(t, h.Handle(r))
+	}
+
+	handle("db error", slog.ErrorLevel, 0)
+	handle("db error", slog.WarnLevel, 100*time.Millisecond)
+	handle("db error", slog.ErrorLevel, 200*time.Millisecond)
+	// window closed
+	handle("db error", slog.ErrorLevel, 1100*time.Millisecond)
+	handle("db error", slog.ErrorLevel, 1200*time.Millisecond)
+	// flush does not emit the summary
+	assert.NoErr(t, h.Flush())
+	assert.Len(t, w.Messages(), 3)
+	assert.NoErr(t, h.Close())
+
+	assert.Eq(t, []string{
+		"db error",
+		"db error (repeated 2 times)",
+		"db error",
+		"db error (repeated 1 time)",
+	}, w.Messages())
+}
+
+func TestDedupeHandler_errorByLogger(t *testing.T) {
+	w := new(syncMsgs)
+	h := handler.NewDedupeHandler(w, func(c *handler.DedupeConfig) {
+		c.Key = handler.DedupeByMessage
+	})
+
+	// the logger flush the handlers after each error record
+	l := slog.NewWithHandlers(h)
+	for i := 0; i < 5; i++ {
+		l.Error("db down")
+	}
+	l.Error("db up")
+	assert.NoErr(t, l.Close())
+
+	assert.Eq(t, []string{
+		"db down",
+		"db down (repeated 4 times)",
+		"db up",
+	}, w.Messages())
+}
+
+func TestDedupeByMessageLevelChannel(t *testing.T) {
+	w := new(syncMsgs)
+	h := handler.NewDedupeHandler(w, func(c *handler.DedupeConfig) {
+		c.Key = handler.DedupeByMessageLevelChannel
+	})
+
+	r1 := newLogRecord("some message")
+	assert.NoErr(t, h.Handle(r1))
+	assert.NoErr(t, h.Handle(r1))
+
+	r2 := newLogRecord("some message")
+	r2.Channel = "other"
+	assert.NoErr(t, h.Handle(r2))
+	r2.Level = slog.WarnLevel
+	assert.NoErr(t, h.Handle(r2))
+	assert.NoErr(t, h.Close())
+
+	assert.Eq(t, []string{
+		"some message",
+		"some message (repeated 1 time)",
+		"some message",
+		"some message",
+	}, w.Messages())
+}
@@ -3,6 +3,7 @@ package handler_test
 import (
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/gookit/goutil/fsutil"
 	"github.com/gookit/goutil/testutil/assert"
@@ -97,6 +98,26 @@ func TestNewBuffFileHandler(t *testing.T) {
 	assert.Contains(t, str, `test file buff handler`)
 }
 
+func TestSyncCloseHandler_StartFlusher(t *testing.T) {
+	testFile := "testdata/file-flusher.log"
+	assert.NoErr(t, fsutil.DeleteIfFileExist(testFile))
+
+	h, err := handler.NewFileHandler(testFile, handler.WithBuffSize(1024), handler.WithFlushInterval(10*time.Millisecond))
+	assert.NoErr(t, err)
+
+	assert.NoErr(t, h.Handle(newLogRecord("flushed by the flusher")))
+	// in the buffer
+	assert.Empty(t, fsutil.ReadString(testFile))
+
+	// flushed in background
+	time.Sleep(50 * time.Millisecond)
+	assert.Contains(t, fsutil.ReadString(testFile), "flushed by the flusher")
+
+	// calls again is no-op, the flusher is stopped on close
+	h.StartFlusher(time.Millisecond)
+	assert.NoErr(t, h.Close())
+}
+
 func TestJSONFileHandler(t *testing.T) {
 	testFile := "testdata/file-json.log"
 	assert.NoErr(t, fsutil.DeleteIfFileExist(testFile))
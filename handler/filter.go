@@ -0,0 +1,66 @@
+package handler
+
+import "github.com/gookit/slog"
+
+// FilterFunc check the record should be handled or not
+type FilterFunc func(r *slog.Record) bool
+
+// FilterHandler wrap a handler, only forward the records that matched the filter func.
+//
+// Usage:
+//
+//	h := handler.NewFilterHandler(func(r *slog.Record) bool {
+//		return r.Field("tenant") == "acme"
+//	}, fileHandler)
+type FilterHandler struct {
+	filter FilterFunc
+	inner  slog.Handler
+}
+
+// NewFilterHandler create new FilterHandler
+func NewFilterHandler(fn FilterFunc, inner slog.Handler) *FilterHandler {
+	return &FilterHandler{filter: fn, inner: inner}
+}
+
+// Handler get the inner handler
+func (h *FilterHandler) Handler() slog.Handler {
+	return h.inner
+}
+
+// IsHandling Check if the current level can be handling
+func (h *FilterHandler) IsHandling(level slog.Level) bool {
+	return h.inner.IsHandling(level)
+}
+
+// Handle a log record, skip it if not matched the filter func
+func (h *FilterHandler) Handle(r *slog.Record) error {
+	if !h.filter(r) {
+		return nil
+	}
+	return h.inner.Handle(r)
+}
+
+// Flush the inner handler
+func (h *FilterHandler) Flush() error {
+	return h.inner.Flush()
+}
+
+// Close the inner handler
+func (h *FilterHandler) Close() error {
+	return h.inner.Close()
+}
+
+// CtxNotDone filter func, matched the record without Ctx or the Record.Ctx is not canceled/timeout.
+func CtxNotDone(r *slog.Record) bool {
+	return r.Ctx == nil || r.Ctx.Err() == nil
+}
+
+// SkipCanceled wrap the handler, skip the records that the Record.Ctx is canceled or timeout.
+// useful for shed the load of the expensive sinks on client disconnects.
+//
+// Usage:
+//
+//	h := handler.SkipCanceled(httpHandler)
+func SkipCanceled(inner slog.Handler) *FilterHandler {
+	return NewFilterHandler(CtxNotDone, inner)
+}
@@ -0,0 +1,42 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+func TestFilterHandler(t *testing.T) {
+	w := new(syncMsgs)
+	h := handler.NewFilterHandler(func(r *slog.Record) bool {
+		return r.Field("tenant") == "acme"
+	}, w)
+	assert.True(t, h.IsHandling(slog.InfoLevel))
+	assert.Eq(t, w, h.Handler())
+
+	l := slog.NewWithHandlers(h)
+	l.WithField("tenant", "acme").Info("acme message")
+	l.WithField("tenant", "other").Info("other message")
+	l.Info("no tenant message")
+
+	assert.Eq(t, []string{"acme message"}, w.Messages())
+	assert.NoErr(t, h.Flush())
+	assert.NoErr(t, h.Close())
+}
+
+func TestSkipCanceled(t *testing.T) {
+	w := new(syncMsgs)
+	h := handler.SkipCanceled(w)
+	l := slog.NewWithHandlers(h)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l.Info("no ctx message")
+	l.WithCtx(ctx).Info("alive message")
+	cancel()
+	l.WithCtx(ctx).Info("canceled message")
+
+	assert.Eq(t, []string{"no ctx message", "alive message"}, w.Messages())
+}
@@ -0,0 +1,288 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gookit/slog"
+)
+
+// FluentConfig struct for the FluentHandler
+type FluentConfig struct {
+	// Network allow: tcp, unix. default is tcp
+	Network string `json:"network" yaml:"network"`
+	// Addr the fluentd forward input address. default is "127.0.0.1:24224"
+	Addr string `json:"addr" yaml:"addr"`
+	// Tag for the events. default is "slog"
+	Tag string `json:"tag" yaml:"tag"`
+	// BatchSize send the batch when pending records reach the number. default is 100
+	BatchSize int `json:"batch_size" yaml:"batch_size"`
+	// FlushInterval send the pending batch on each interval. default is 3s, set 0 to disable
+	FlushInterval time.Duration `json:"flush_interval" yaml:"flush_interval"`
+	// RequireAck wait the ack response from server for each batch, for reliability.
+	RequireAck bool `json:"require_ack" yaml:"require_ack"`
+	// Timeout for connect, write and wait ack. default is 3s
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// MaxRetry max retry times on send failed, will reconnect before retry. default is 3
+	MaxRetry int `json:"max_retry" yaml:"max_retry"`
+	// RetryWait the first wait time before retry, will double it on each retry. default is 500ms
+	RetryWait time.Duration `json:"retry_wait" yaml:"retry_wait"`
+}
+
+// FluentHandler send log records to Fluentd/Fluent Bit by the Fluent Forward protocol, support batching.
+//
+// Each record will be encoded as an event with Record.Time and a map includes:
+// message, level, channel, the Record.Fields, and Record.Data, Record.Extra on they are not empty.
+//
+// NOTICE: the formatter is not used, the record is sent as structured data.
+//
+// refer: https://github.com/fluent/fluentd/wiki/Forward-Protocol-Specification-v1
+type FluentHandler struct {
+	slog.LevelHandling
+	cfg *FluentConfig
+
+	mu   sync.Mutex
+	conn net.Conn
+	// pending encoded entries
+	batch  [][]byte
+	closed bool
+	// for stop the flush ticker
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewFluentHandler create new FluentHandler. the connection will be created on first send.
+//
+// Usage:
+//
+//	h := handler.NewFluentHandler("127.0.0.1:24224", func(c *handler.FluentConfig) {
+//		c.Tag = "myapp.logs"
+//		c.RequireAck = true
+//	})
+func NewFluentHandler(addr string, fns ...func(c *FluentConfig)) *FluentHandler {
+	cfg := &FluentConfig{
+		Network:       "tcp",
+		Addr:          addr,
+		Tag:           "slog",
+		BatchSize:     100,
+		FlushInterval: 3 * time.Second,
+		Timeout:       3 * time.Second,
+		MaxRetry:      3,
+		RetryWait:     500 * time.Millisecond,
+	}
+	for _, fn := range fns {
+		fn(cfg)
+	}
+
+	if cfg.Addr == "" {
+		cfg.Addr = "127.0.0.1:24224"
+	}
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = 1
+	}
+
+	h := &FluentHandler{cfg: cfg}
+	// init default log level
+	h.SetMaxLevel(slog.InfoLevel)
+
+	if cfg.FlushInterval > 0 {
+		h.stopCh = make(chan struct{})
+		h.wg.Add(1)
+		go h.flushDaemon()
+	}
+	return h
+}
+
+// Config get the handler config
+func (h *FluentHandler) Config() *FluentConfig {
+	return h.cfg
+}
+
+// Handle a log record. will send the batch on reach the BatchSize
+func (h *FluentHandler) Handle(r *slog.Record) error {
+	entry := encodeFluentEntry(r)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.batch = append(h.batch, entry)
+	if len(h.batch) >= h.cfg.BatchSize {
+		return h.sendBatch()
+	}
+	return nil
+}
+
+// encode the record to forward entry: [EventTime, record map]
+func encodeFluentEntry(r *slog.Record) []byte {
+	ts := r.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	data := make(map[string]any, len(r.Fields)+5)
+	for k, v := range r.Fields {
+		data[k] = v
+	}
+
+	data[slog.FieldKeyMessage] = r.Message
+	data[slog.FieldKeyLevel] = r.Level.Name()
+	data[slog.FieldKeyChannel] = r.Channel
+	if len(r.Data) > 0 {
+		data[slog.FieldKeyData] = r.Data
+	}
+	if len(r.Extra) > 0 {
+		data[slog.FieldKeyExtra] = r.Extra
+	}
+
+	b := make([]byte, 0, 256)
+	b = appendMsgpackArrayHeader(b, 2)
+	b = appendMsgpackEventTime(b, ts)
+	return appendMsgpackMap(b, data)
+}
+
+// Flush force send the pending batch
+func (h *FluentHandler) Flush() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sendBatch()
+}
+
+// Close the handler. will flush the pending batch, then stop the flush ticker and close the connection.
+func (h *FluentHandler) Close() error {
+	err := h.Flush()
+
+	h.mu.Lock()
+	if !h.closed && h.stopCh != nil {
+		close(h.stopCh)
+	}
+	h.closed = true
+	h.mu.Unlock()
+
+	h.wg.Wait()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.conn != nil {
+		if cErr := h.conn.Close(); err == nil {
+			err = cErr
+		}
+		h.conn = nil
+	}
+	return err
+}
+
+func (h *FluentHandler) flushDaemon() {
+	defer h.wg.Done()
+	tk := time.NewTicker(h.cfg.FlushInterval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			printErrln("slog: fluent handler flush error:", h.Flush())
+		case <-h.stopCh:
+			return
+		}
+	}
+}
+
+// send pending batch, will reconnect and retry on failed. should be in lock
+func (h *FluentHandler) sendBatch() (err error) {
+	if len(h.batch) == 0 {
+		return nil
+	}
+
+	var chunk string
+	if h.cfg.RequireAck {
+		chunk = newFluentChunkID()
+	}
+
+	msg := h.buildMessage(chunk)
+	// reset batch, the failed batch will be dropped
+	h.batch = h.batch[:0]
+
+	wait := h.cfg.RetryWait
+	for i := 0; i <= h.cfg.MaxRetry; i++ {
+		if i > 0 {
+			time.Sleep(wait)
+			wait *= 2
+		}
+
+		if err = h.send(msg, chunk); err == nil {
+			return nil
+		}
+
+		// close the broken connection, will reconnect on next send
+		if h.conn != nil {
+			_ = h.conn.Close()
+			h.conn = nil
+		}
+	}
+	return err
+}
+
+// build the forward mode message: [tag, [entries...], option]
+func (h *FluentHandler) buildMessage(chunk string) []byte {
+	size := 64
+	for _, entry := range h.batch {
+		size += len(entry)
+	}
+
+	b := make([]byte, 0, size)
+	b = appendMsgpackArrayHeader(b, 3)
+	b = appendMsgpackStr(b, h.cfg.Tag)
+	b = appendMsgpackArrayHeader(b, len(h.batch))
+	for _, entry := range h.batch {
+		b = append(b, entry...)
+	}
+
+	// option
+	if chunk != "" {
+		b = appendMsgpackMapHeader(b, 2)
+		b = appendMsgpackStr(b, "size")
+		b = appendMsgpackUint(b, uint64(len(h.batch)))
+		b = appendMsgpackStr(b, "chunk")
+		return appendMsgpackStr(b, chunk)
+	}
+
+	b = appendMsgpackMapHeader(b, 1)
+	b = appendMsgpackStr(b, "size")
+	return appendMsgpackUint(b, uint64(len(h.batch)))
+}
+
+func (h *FluentHandler) send(msg []byte, chunk string) (err error) {
+	if h.conn == nil {
+		h.conn, err = dialTimeout(h.cfg.Network, h.cfg.Addr, h.cfg.Timeout, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	_ = setConnDeadline(h.conn, h.cfg.Timeout)
+	if _, err = h.conn.Write(msg); err != nil {
+		return err
+	}
+	if chunk == "" {
+		return nil
+	}
+
+	// wait the ack response: {"ack": chunk}
+	resp, err := readMsgpackStrMap(h.conn)
+	if err != nil {
+		return err
+	}
+	if resp["ack"] != chunk {
+		return fmt.Errorf("slog: fluent ack mismatch, want %q, got %q", chunk, resp["ack"])
+	}
+	return nil
+}
+
+func newFluentChunkID() string {
+	var bs [16]byte
+	_, _ = rand.Read(bs[:])
+	return base64.StdEncoding.EncodeToString(bs[:])
+}
@@ -0,0 +1,266 @@
+package handler_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+// a fake fluentd forward server
+type fluentRecv struct {
+	ln   net.Listener
+	ack  bool
+	mu   sync.Mutex
+	msgs [][]any
+	// close the connection after read N messages
+	dropAfter int
+}
+
+func newFluentServer(t *testing.T, ack bool) *fluentRecv {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoErr(t, err)
+
+	fr := &fluentRecv{ln: ln, ack: ack}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go fr.serve(conn)
+		}
+	}()
+	return fr
+}
+
+func (fr *fluentRecv) serve(conn net.Conn) {
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+	for {
+		v, err := decodeMsgpack(br)
+		if err != nil {
+			return
+		}
+
+		msg := v.([]any)
+		fr.mu.Lock()
+		fr.msgs = append(fr.msgs, msg)
+		drop := fr.dropAfter > 0 && len(fr.msgs) == fr.dropAfter
+		fr.mu.Unlock()
+		if drop {
+			return
+		}
+
+		if fr.ack {
+			chunk := msg[2].(map[string]any)["chunk"].(string)
+			// {"ack": chunk}
+			resp := []byte{0x81, 0xa3, 'a', 'c', 'k', 0xa0 | byte(len(chunk))}
+			_, _ = conn.Write(append(resp, chunk...))
+		}
+	}
+}
+
+func (fr *fluentRecv) Msgs() [][]any {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	return fr.msgs
+}
+
+func TestFluentHandler_batch(t *testing.T) {
+	fr := newFluentServer(t, false)
+	defer fr.ln.Close()
+
+	h := handler.NewFluentHandler(fr.ln.Addr().String(), func(c *handler.FluentConfig) {
+		c.Tag = "app.logs"
+		c.BatchSize = 2
+		c.FlushInterval = 0
+	})
+	assert.True(t, h.IsHandling(slog.InfoLevel))
+	assert.False(t, h.IsHandling(slog.DebugLevel))
+
+	r1 := newLogRecord("info message")
+	r1.Time = time.Unix(1700000000, 123)
+	r1.Fields = slog.M{"user_id": 23, "ratio": 0.5, "tags": []string{"a", "b"}}
+	r2 := newLogRecord("error message")
+	r2.Level = slog.ErrorLevel
+
+	assert.NoErr(t, h.Handle(r1))
+	assert.NoErr(t, h.Handle(r2))
+	assert.NoErr(t, h.Handle(r1))
+	assert.NoErr(t, h.Close())
+
+	msgs := waitFluentMsgs(fr, 2)
+	assert.Len(t, msgs, 2)
+	msg := msgs[0]
+	assert.Eq(t, "app.logs", msg[0])
+	assert.Eq(t, map[string]any{"size": uint64(2)}, msg[2])
+
+	entries := msg[1].([]any)
+	assert.Len(t, entries, 2)
+	entry := entries[0].([]any)
+	assert.Eq(t, "1700000000.123", entry[0])
+
+	data := entry[1].(map[string]any)
+	assert.Eq(t, "info message", data["message"])
+	assert.Eq(t, "INFO", data["level"])
+	assert.Eq(t, "handler_test", data["channel"])
+	assert.Eq(t, uint64(23), data["user_id"])
+	assert.Eq(t, 0.5, data["ratio"])
+	assert.Eq(t, []any{"a", "b"}, data["tags"])
+	assert.Eq(t, "linux", data["extra"].(map[string]any)["source"])
+	assert.Eq(t, "ERROR", entries[1].([]any)[1].(map[string]any)["level"])
+
+	// the remaining record is sent on close
+	assert.Len(t, msgs[1][1].([]any), 1)
+}
+
+func TestFluentHandler_ack(t *testing.T) {
+	fr := newFluentServer(t, true)
+	defer fr.ln.Close()
+
+	h := handler.NewFluentHandler(fr.ln.Addr().String(), func(c *handler.FluentConfig) {
+		c.RequireAck = true
+		c.FlushInterval = 20 * time.Millisecond
+		c.RetryWait = time.Millisecond
+	})
+
+	assert.NoErr(t, h.Handle(newLogRecord("message1")))
+	msgs := waitFluentMsgs(fr, 1)
+	assert.Len(t, msgs, 1)
+	assert.Eq(t, "slog", msgs[0][0])
+	opt := msgs[0][2].(map[string]any)
+	assert.Eq(t, uint64(1), opt["size"])
+	assert.NotEmpty(t, opt["chunk"])
+
+	assert.NoErr(t, h.Handle(newLogRecord("message2")))
+	assert.NoErr(t, h.Flush())
+	assert.Len(t, fr.Msgs(), 2)
+	assert.NoErr(t, h.Close())
+}
+
+func TestFluentHandler_reconnect(t *testing.T) {
+	fr := newFluentServer(t, false)
+	fr.dropAfter = 1
+
+	h := handler.NewFluentHandler(fr.ln.Addr().String(), func(c *handler.FluentConfig) {
+		c.FlushInterval = 0
+		c.RequireAck = true
+		c.Timeout = 100 * time.Millisecond
+		c.MaxRetry = 1
+		c.RetryWait = time.Millisecond
+	})
+
+	// the server closes the connection without ack, retry on new connection also no ack
+	assert.NoErr(t, h.Handle(newLogRecord("message")))
+	assert.Err(t, h.Flush())
+	assert.Len(t, fr.Msgs(), 2)
+
+	// server is down
+	assert.NoErr(t, fr.ln.Close())
+	assert.NoErr(t, h.Handle(newLogRecord("message")))
+	assert.Err(t, h.Close())
+}
+
+func waitFluentMsgs(fr *fluentRecv, n int) [][]any {
+	for i := 0; i < 100; i++ {
+		if msgs := fr.Msgs(); len(msgs) >= n {
+			return msgs
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return fr.Msgs()
+}
+
+// a minimal msgpack decoder for testing. the EventTime is decoded as "sec.nsec" string
+func decodeMsgpack(r *bufio.Reader) (any, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	readN := func(n int) []byte {
+		bs := make([]byte, n)
+		_, err = io.ReadFull(r, bs)
+		return bs
+	}
+	readArray := func(n int) (any, error) {
+		arr := make([]any, n)
+		for i := range arr {
+			if arr[i], err = decodeMsgpack(r); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	}
+	readMap := func(n int) (any, error) {
+		mp := make(map[string]any, n)
+		for i := 0; i < n; i++ {
+			k, err := decodeMsgpack(r)
+			if err != nil {
+				return nil, err
+			}
+			if mp[k.(string)], err = decodeMsgpack(r); err != nil {
+				return nil, err
+			}
+		}
+		return mp, nil
+	}
+
+	switch {
+	case c <= 0x7f:
+		return uint64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return readMap(int(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return readArray(int(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return string(readN(int(c & 0x1f))), err
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2, 0xc3:
+		return c == 0xc3, nil
+	case 0xcc:
+		return uint64(readN(1)[0]), err
+	case 0xcd:
+		return uint64(binary.BigEndian.Uint16(readN(2))), err
+	case 0xce:
+		return uint64(binary.BigEndian.Uint32(readN(4))), err
+	case 0xcf:
+		return binary.BigEndian.Uint64(readN(8)), err
+	case 0xd0:
+		return int64(int8(readN(1)[0])), err
+	case 0xd3:
+		return int64(binary.BigEndian.Uint64(readN(8))), err
+	case 0xcb:
+		return math.Float64frombits(binary.BigEndian.Uint64(readN(8))), err
+	case 0xd9:
+		return string(readN(int(readN(1)[0]))), err
+	case 0xc4:
+		return readN(int(readN(1)[0])), err
+	case 0xdc:
+		return readArray(int(binary.BigEndian.Uint16(readN(2))))
+	case 0xde:
+		return readMap(int(binary.BigEndian.Uint16(readN(2))))
+	case 0xd7: // fixext8, EventTime
+		bs := readN(9)
+		sec, nsec := binary.BigEndian.Uint32(bs[1:5]), binary.BigEndian.Uint32(bs[5:])
+		return strconv.FormatUint(uint64(sec), 10) + "." + strconv.FormatUint(uint64(nsec), 10), err
+	}
+	return nil, errors.New("unsupported msgpack type")
+}
@@ -4,6 +4,7 @@
 package handler
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"sync"
@@ -66,6 +67,13 @@ type LevelWithFormatter = slog.LevelWithFormatter
 // Deprecated: please use slog.LevelsWithFormatter instead.
 type LevelsWithFormatter = slog.LevelsWithFormatter
 
+// use the formatter on the LevelFormattable has no explicit formatter. see slog.FormatterInheritor
+func inheritFormatter(lf slog.LevelFormattable, f slog.Formatter) {
+	if fi, ok := lf.(slog.FormatterInheritor); ok {
+		fi.InheritFormatter(f)
+	}
+}
+
 // NopFlushClose no operation.
 //
 // provide empty Flush(), Close() methods, useful for tests.
@@ -115,3 +123,36 @@ func (lw *LockWrapper) LockEnabled() bool {
 func QuickOpenFile(filepath string) (*os.File, error) {
 	return fsutil.OpenFile(filepath, DefaultFileFlags, DefaultFilePerm)
 }
+
+// flush the writer if it has the Flush() or Sync() method
+func flushWriter(w io.Writer) error {
+	if w == os.Stdout || w == os.Stderr {
+		return nil // sync stdout/stderr may return error on some platforms
+	}
+
+	switch fw := w.(type) {
+	case interface{ Flush() error }:
+		return fw.Flush()
+	case interface{ Sync() error }:
+		return fw.Sync()
+	}
+	return nil
+}
+
+// close the writer if it is an io.Closer. the os.Stdout and os.Stderr will not be closed.
+func closeWriter(w io.Writer) error {
+	if w == os.Stdout || w == os.Stderr {
+		return nil
+	}
+
+	if c, ok := w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func printErrln(pfx string, err error) {
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, pfx, err)
+	}
+}
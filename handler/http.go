@@ -0,0 +1,363 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gookit/slog"
+)
+
+var (
+	// ErrHTTPClosed error on write to a closed HTTPHandler
+	ErrHTTPClosed = errors.New("slog: the http handler has been closed")
+	// ErrHTTPQueueFull error on the HTTPHandler send queue is full, the batch is dropped.
+	ErrHTTPQueueFull = errors.New("slog: the http handler queue is full")
+)
+
+// HTTPConfig struct for the HTTPHandler
+type HTTPConfig struct {
+	// URL the endpoint for POST log records
+	URL string `json:"url" yaml:"url"`
+	// ContentType for the request body.
+	//
+	// default is "application/json" on use JSONFormatter, otherwise is "text/plain; charset=utf-8"
+	ContentType string `json:"content_type" yaml:"content_type"`
+	// Headers custom request headers. eg: {"Authorization": "Bearer TOKEN"}
+	Headers map[string]string `json:"headers" yaml:"headers"`
+	// BatchSize send the batch when pending records reach the number. default is 20
+	BatchSize int `json:"batch_size" yaml:"batch_size"`
+	// FlushInterval send the pending batch on each interval. default is 5s, set 0 to disable
+	FlushInterval time.Duration `json:"flush_interval" yaml:"flush_interval"`
+	// QueueSize the max full batches number wait to send. Handle will drop the batch and return ErrHTTPQueueFull on full.
+	// default is 8
+	QueueSize int `json:"queue_size" yaml:"queue_size"`
+	// MaxRetry max retry times on send failed or response 5xx. default is 3
+	MaxRetry int `json:"max_retry" yaml:"max_retry"`
+	// RetryWait the first wait time before retry, will double it on each retry. default is 500ms
+	RetryWait time.Duration `json:"retry_wait" yaml:"retry_wait"`
+	// Timeout for each request, include the connect and read the response. default is 10s
+	//
+	// NOTICE: it is not used on the Client is custom.
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// Client custom the http client. default is new client with the Timeout
+	Client *http.Client `json:"-" yaml:"-"`
+}
+
+// HTTPHandler send log records to a http endpoint by POST, support batching.
+//
+// The batches are sent on a background goroutine, so a slow or dead endpoint does not block the logging.
+// the Flush() push the pending batch to the send queue without waiting, the Logger flushes after each error record.
+// the send errors are returned by the next Flush(), use FlushWait() for wait the batches sent.
+//
+// The request body is built by the handler formatter:
+//   - on use slog.JSONFormatter, the body is a JSON array of records
+//   - otherwise, the body is all formatted records joined
+type HTTPHandler struct {
+	slog.LevelWithFormatter
+	cfg *HTTPConfig
+
+	mu sync.Mutex
+	// pending formatted records
+	batch [][]byte
+	// the last send error of the batches without waiting
+	lastErr error
+
+	// lock for close the queue
+	rw     sync.RWMutex
+	closed bool
+	// the batches wait to send
+	queue chan *httpBatch
+
+	// for stop the flush ticker
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+type httpBatch struct {
+	records [][]byte
+	// not nil on FlushWait(), will receive the send error
+	done chan error
+}
+
+// NewHTTPHandler create new HTTPHandler
+//
+// Usage:
+//
+//	h := handler.NewHTTPHandler("http://alert.example.com/logs", func(c *handler.HTTPConfig) {
+//		c.Headers = map[string]string{"Authorization": "Bearer TOKEN"}
+//	})
+//	h.SetMaxLevel(slog.ErrorLevel)
+//	h.SetFormatter(slog.NewJSONFormatter())
+func NewHTTPHandler(url string, fns ...func(c *HTTPConfig)) *HTTPHandler {
+	cfg := &HTTPConfig{
+		URL:           url,
+		BatchSize:     20,
+		FlushInterval: 5 * time.Second,
+		MaxRetry:      3,
+		RetryWait:     500 * time.Millisecond,
+		Timeout:       10 * time.Second,
+		QueueSize:     8,
+	}
+	for _, fn := range fns {
+		fn(cfg)
+	}
+
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = 1
+	}
+	if cfg.QueueSize < 1 {
+		cfg.QueueSize = 1
+	}
+	cfg.Client = httpClientOr(cfg.Client, cfg.Timeout)
+
+	h := &HTTPHandler{
+		cfg:   cfg,
+		queue: make(chan *httpBatch, cfg.QueueSize),
+	}
+	// init default log level
+	h.Level = slog.InfoLevel
+
+	h.wg.Add(1)
+	go h.sendLoop()
+
+	if cfg.FlushInterval > 0 {
+		h.stopCh = make(chan struct{})
+		h.wg.Add(1)
+		go h.flushDaemon()
+	}
+	return h
+}
+
+// Config get the handler config
+func (h *HTTPHandler) Config() *HTTPConfig {
+	return h.cfg
+}
+
+// Handle a log record. will push the batch to send queue on reach the BatchSize
+func (h *HTTPHandler) Handle(r *slog.Record) error {
+	bts, err := h.Format(r)
+	if err != nil {
+		return err
+	}
+
+	h.rw.RLock()
+	defer h.rw.RUnlock()
+	if h.closed {
+		return ErrHTTPClosed
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// must copy it, the formatter may reuse the buffer
+	h.batch = append(h.batch, append([]byte(nil), bts...))
+	if len(h.batch) < h.cfg.BatchSize {
+		return nil
+	}
+
+	b := &httpBatch{records: h.batch}
+	h.batch = nil
+
+	select {
+	case h.queue <- b:
+		return nil
+	default: // queue is full
+		return ErrHTTPQueueFull
+	}
+}
+
+// Flush push the pending batch to the send queue, does not wait it sent.
+// returns the last send error of the previous batches.
+//
+// NOTICE: the pending batch is kept on the queue is full, will be sent on the next Flush().
+func (h *HTTPHandler) Flush() error {
+	h.rw.RLock()
+	defer h.rw.RUnlock()
+	if h.closed {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	err := h.lastErr
+	h.lastErr = nil
+	if len(h.batch) == 0 {
+		return err
+	}
+
+	select {
+	case h.queue <- &httpBatch{records: h.batch}:
+		h.batch = nil
+	default: // queue is full, keep the pending batch
+	}
+	return err
+}
+
+// FlushWait send the pending batch and wait all queued batches sent.
+// returns the send error, or the last send error of the previous batches.
+func (h *HTTPHandler) FlushWait() error {
+	h.rw.RLock()
+	defer h.rw.RUnlock()
+	if h.closed {
+		return nil
+	}
+	return h.flushWait()
+}
+
+// Close the handler. will stop the flush ticker, then send the pending batch and wait all queued batches sent.
+func (h *HTTPHandler) Close() error {
+	h.rw.Lock()
+	if h.closed {
+		h.rw.Unlock()
+		return nil
+	}
+	h.closed = true
+	h.rw.Unlock()
+
+	if h.stopCh != nil {
+		close(h.stopCh)
+	}
+
+	// no more writes after closed
+	err := h.flushWait()
+	close(h.queue)
+	h.wg.Wait()
+	return err
+}
+
+// push the pending batch to queue and wait it sent.
+func (h *HTTPHandler) flushWait() error {
+	h.mu.Lock()
+	b := &httpBatch{records: h.batch, done: make(chan error, 1)}
+	h.batch = nil
+	h.mu.Unlock()
+
+	h.queue <- b
+	return <-b.done
+}
+
+func (h *HTTPHandler) flushDaemon() {
+	defer h.wg.Done()
+	tk := time.NewTicker(h.cfg.FlushInterval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			printErrln("slog: http handler flush error:", h.Flush())
+		case <-h.stopCh:
+			return
+		}
+	}
+}
+
+// send the queued batches in order, on a background goroutine
+func (h *HTTPHandler) sendLoop() {
+	defer h.wg.Done()
+
+	for b := range h.queue {
+		var err error
+		if len(b.records) > 0 {
+			err = h.sendBatch(b.records)
+		}
+
+		h.mu.Lock()
+		if b.done == nil {
+			if err != nil {
+				h.lastErr = err
+			}
+			h.mu.Unlock()
+			continue
+		}
+
+		if err == nil {
+			err = h.lastErr
+		}
+		h.lastErr = nil
+		h.mu.Unlock()
+		b.done <- err
+	}
+}
+
+// send the batch, the failed batch will be dropped
+func (h *HTTPHandler) sendBatch(batch [][]byte) error {
+	body, contentType := h.buildBody(batch)
+
+	return postWithRetry(h.cfg.Client, h.cfg.MaxRetry, h.cfg.RetryWait, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPost, h.cfg.URL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Content-Type", contentType)
+		for k, v := range h.cfg.Headers {
+			req.Header.Set(k, v)
+		}
+		return req, nil
+	})
+}
+
+func (h *HTTPHandler) buildBody(batch [][]byte) (body []byte, contentType string) {
+	_, isJSON := h.Formatter().(*slog.JSONFormatter)
+	if isJSON {
+		contentType = "application/json"
+		// build a JSON array
+		body = append(body, '[')
+		for i, bts := range batch {
+			if i > 0 {
+				body = append(body, ',')
+			}
+			body = append(body, bytes.TrimRight(bts, "\r\n")...)
+		}
+		body = append(body, ']')
+	} else {
+		contentType = "text/plain; charset=utf-8"
+		body = bytes.Join(batch, nil)
+	}
+
+	if h.cfg.ContentType != "" {
+		contentType = h.cfg.ContentType
+	}
+	return
+}
+
+// postWithRetry send request by the client, will retry on send error or response status is 5xx.
+func postWithRetry(c *http.Client, maxRetry int, wait time.Duration, newReq func() (*http.Request, error)) (err error) {
+	for i := 0; i <= maxRetry; i++ {
+		if i > 0 {
+			time.Sleep(wait)
+			wait *= 2
+		}
+
+		var req *http.Request
+		if req, err = newReq(); err != nil {
+			return err
+		}
+
+		var resp *http.Response
+		resp, err = c.Do(req)
+		if err != nil {
+			continue
+		}
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		if resp.StatusCode < 300 {
+			return nil
+		}
+
+		err = fmt.Errorf("slog: request %s got response status %s", req.URL, resp.Status)
+		// dont retry on client error
+		if resp.StatusCode < 500 {
+			return err
+		}
+	}
+	return err
+}
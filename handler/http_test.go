@@ -0,0 +1,221 @@
+package handler_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+type httpRecv struct {
+	mu     sync.Mutex
+	bodies []string
+	header http.Header
+	path   string
+	hits   int
+}
+
+func (hr *httpRecv) Bodies() []string {
+	hr.mu.Lock()
+	defer hr.mu.Unlock()
+	return append([]string(nil), hr.bodies...)
+}
+
+func newHTTPServer(hr *httpRecv, failTimes int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hr.mu.Lock()
+		defer hr.mu.Unlock()
+
+		hr.hits++
+		if hr.hits <= failTimes {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		bs, _ := io.ReadAll(r.Body)
+		hr.bodies = append(hr.bodies, string(bs))
+		hr.header = r.Header.Clone()
+		hr.path = r.URL.Path
+	}))
+}
+
+func TestHTTPHandler_batch(t *testing.T) {
+	hr := &httpRecv{}
+	srv := newHTTPServer(hr, 0)
+	defer srv.Close()
+
+	h := handler.NewHTTPHandler(srv.URL, func(c *handler.HTTPConfig) {
+		c.BatchSize = 2
+		c.FlushInterval = 0
+		c.Headers = map[string]string{"X-Token": "abc"}
+	})
+	h.SetFormatter(slog.NewJSONFormatter())
+
+	assert.NoErr(t, h.Handle(newLogRecord("message 1")))
+	assert.Empty(t, hr.Bodies())
+
+	// the full batch is sent on background, FlushWait will wait it sent
+	assert.NoErr(t, h.Handle(newLogRecord("message 2")))
+	assert.NoErr(t, h.FlushWait())
+	bodies := hr.Bodies()
+	assert.Len(t, bodies, 1)
+	assert.StrContains(t, bodies[0], `[{"channel":"handler_test"`)
+	assert.StrContains(t, bodies[0], `"message":"message 2"}]`)
+	assert.Eq(t, "abc", hr.header.Get("X-Token"))
+	assert.Eq(t, "application/json", hr.header.Get("Content-Type"))
+
+	// flush pending
+	assert.NoErr(t, h.Handle(newLogRecord("message 3")))
+	assert.NoErr(t, h.Close())
+	bodies = hr.Bodies()
+	assert.Len(t, bodies, 2)
+	assert.StrContains(t, bodies[1], "message 3")
+}
+
+func TestHTTPHandler_interval(t *testing.T) {
+	hr := &httpRecv{}
+	srv := newHTTPServer(hr, 0)
+	defer srv.Close()
+
+	h := handler.NewHTTPHandler(srv.URL, func(c *handler.HTTPConfig) {
+		c.FlushInterval = 50 * time.Millisecond
+	})
+	h.SetFormatter(newTestFormatter())
+
+	assert.NoErr(t, h.Handle(newLogRecord("message on interval")))
+	time.Sleep(120 * time.Millisecond)
+
+	bodies := hr.Bodies()
+	assert.Len(t, bodies, 1)
+	assert.Eq(t, "message on interval", bodies[0])
+	assert.NoErr(t, h.Close())
+}
+
+func TestHTTPHandler_retry(t *testing.T) {
+	hr := &httpRecv{}
+	srv := newHTTPServer(hr, 2)
+	defer srv.Close()
+
+	h := handler.NewHTTPHandler(srv.URL, func(c *handler.HTTPConfig) {
+		c.BatchSize = 1
+		c.FlushInterval = 0
+		c.RetryWait = time.Millisecond
+	})
+	h.SetFormatter(newTestFormatter())
+
+	assert.NoErr(t, h.Handle(newLogRecord("retry message")))
+	assert.NoErr(t, h.FlushWait())
+	assert.Eq(t, []string{"retry message"}, hr.Bodies())
+	assert.Eq(t, 3, hr.hits)
+
+	// always fail, the error is returned by next Flush
+	h.Config().MaxRetry = 0
+	hr.mu.Lock()
+	hr.hits = -10
+	hr.mu.Unlock()
+	assert.NoErr(t, h.Handle(newLogRecord("failed message")))
+	assert.Err(t, h.FlushWait())
+	assert.NoErr(t, h.FlushWait())
+
+	// Flush does not wait, the error is returned by the next one
+	assert.NoErr(t, h.Handle(newLogRecord("failed message")))
+	assert.NoErr(t, h.Flush())
+	assert.Err(t, h.FlushWait())
+	assert.NoErr(t, h.Flush())
+	assert.NoErr(t, h.Close())
+}
+
+func TestHTTPHandler_timeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-time.After(3 * time.Second):
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	h := handler.NewHTTPHandler(srv.URL, func(c *handler.HTTPConfig) {
+		c.BatchSize = 1
+		c.FlushInterval = 0
+		c.MaxRetry = 0
+		c.Timeout = 50 * time.Millisecond
+	})
+	assert.Eq(t, 50*time.Millisecond, h.Config().Client.Timeout)
+	h.SetFormatter(newTestFormatter())
+
+	st := time.Now()
+	assert.NoErr(t, h.Handle(newLogRecord("stalled message")))
+	assert.Err(t, h.FlushWait())
+	assert.True(t, time.Since(st) < time.Second)
+	assert.NoErr(t, h.Close())
+}
+
+func TestHTTPHandler_notBlock(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+
+	h := handler.NewHTTPHandler(srv.URL, func(c *handler.HTTPConfig) {
+		c.BatchSize = 1
+		c.FlushInterval = 0
+		c.QueueSize = 1
+	})
+	h.SetFormatter(newTestFormatter())
+
+	// the stalled endpoint does not block Handle, drop the batch on the queue is full
+	st := time.Now()
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		err = h.Handle(newLogRecord("stalled message"))
+	}
+	assert.ErrIs(t, err, handler.ErrHTTPQueueFull)
+	assert.True(t, time.Since(st) < time.Second)
+
+	close(done)
+	assert.NoErr(t, h.Close())
+	assert.ErrIs(t, h.Handle(newLogRecord("closed")), handler.ErrHTTPClosed)
+	assert.NoErr(t, h.Flush())
+	assert.NoErr(t, h.Close())
+}
+
+func TestHTTPHandler_flushNotBlock(t *testing.T) {
+	done := make(chan struct{})
+	hr := &httpRecv{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+		bs, _ := io.ReadAll(r.Body)
+		hr.mu.Lock()
+		hr.bodies = append(hr.bodies, string(bs))
+		hr.mu.Unlock()
+	}))
+	defer srv.Close()
+
+	h := handler.NewHTTPHandler(srv.URL, func(c *handler.HTTPConfig) {
+		c.FlushInterval = 0
+	})
+	h.SetFormatter(newTestFormatter())
+
+	// the logger flush the handlers after each error record, it does not wait the stalled endpoint
+	l := slog.NewWithHandlers(h)
+	st := time.Now()
+	for i := 0; i < 3; i++ {
+		l.Error("error message\n")
+	}
+	assert.True(t, time.Since(st) < time.Second)
+	assert.Empty(t, hr.Bodies())
+
+	close(done)
+	assert.NoErr(t, l.Close())
+	assert.Eq(t, 3, strings.Count(strings.Join(hr.Bodies(), ""), "error message"))
+}
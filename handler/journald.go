@@ -0,0 +1,268 @@
+//go:build linux
+
+package handler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"syscall"
+
+	"github.com/gookit/slog"
+)
+
+// JournaldSocket the default journald native protocol socket path
+const JournaldSocket = "/run/systemd/journal/socket"
+
+// JournaldConfig struct for the JournaldHandler
+type JournaldConfig struct {
+	// SocketPath the journald socket. default is JournaldSocket
+	SocketPath string `json:"socket_path" yaml:"socket_path"`
+	// Identifier the SYSLOG_IDENTIFIER field. default is the program name
+	Identifier string `json:"identifier" yaml:"identifier"`
+	// Fallback write the formatted record to it on the journald socket is unavailable.
+	// default is os.Stderr
+	Fallback io.Writer `json:"-" yaml:"-"`
+}
+
+// JournaldHandler send the records to systemd journald by the native protocol.
+//
+// The record is sent as the journald fields:
+//   - MESSAGE the formatted record. default formatter only renders the message
+//   - PRIORITY the syslog severity of the level. see SyslogSeverity()
+//   - SYSLOG_IDENTIFIER, SLOG_CHANNEL, and the CODE_FILE, CODE_LINE, CODE_FUNC on report caller
+//   - the Record.Fields as the uppercased names. eg: "user_id" -> "USER_ID"
+//     the names same as the above fields are prefixed by "F_". eg: "message" -> "F_MESSAGE"
+//
+// On the socket is unavailable, the formatted record will be written to JournaldConfig.Fallback.
+//
+// refer: https://systemd.io/JOURNAL_NATIVE_PROTOCOL/
+type JournaldHandler struct {
+	slog.LevelWithFormatter
+	cfg JournaldConfig
+
+	addr *net.UnixAddr
+
+	mu sync.Mutex
+	// the unconnected datagram socket, send the entries to addr
+	conn *net.UnixConn
+}
+
+// NewJournaldHandler create new JournaldHandler
+//
+// Usage:
+//
+//	h := handler.NewJournaldHandler(func(c *handler.JournaldConfig) {
+//		c.Identifier = "myapp"
+//	})
+func NewJournaldHandler(fns ...func(c *JournaldConfig)) *JournaldHandler {
+	cfg := JournaldConfig{
+		SocketPath: JournaldSocket,
+		Identifier: filepath.Base(os.Args[0]),
+		Fallback:   os.Stderr,
+	}
+	for _, fn := range fns {
+		fn(&cfg)
+	}
+
+	h := &JournaldHandler{
+		cfg:  cfg,
+		addr: &net.UnixAddr{Name: cfg.SocketPath, Net: "unixgram"},
+	}
+	// init default log level
+	h.Level = slog.InfoLevel
+	// the journald has the time and priority, only render the message by default.
+	h.InheritFormatter(slog.NewTextFormatter("{{message}}"))
+	return h
+}
+
+// Config get the config
+func (h *JournaldHandler) Config() JournaldConfig {
+	return h.cfg
+}
+
+// Handle a log record
+func (h *JournaldHandler) Handle(r *slog.Record) error {
+	bts, err := h.Format(r)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.conn == nil {
+		if h.conn, err = net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"}); err != nil {
+			return h.fallback(bts)
+		}
+	}
+
+	err = h.send(h.buildEntry(r, bytes.TrimRight(bts, "\r\n")))
+	// the journald socket is unavailable
+	if errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.ECONNREFUSED) {
+		return h.fallback(bts)
+	}
+	return err
+}
+
+func (h *JournaldHandler) fallback(bts []byte) error {
+	if h.cfg.Fallback == nil {
+		return nil
+	}
+	if len(bts) == 0 || bts[len(bts)-1] != '\n' {
+		// don't write to the formatter buffer
+		bts = append(bts[:len(bts):len(bts)], '\n')
+	}
+
+	_, err := h.cfg.Fallback.Write(bts)
+	return err
+}
+
+// send the entry by datagram. if it is too large, send it by the file descriptor.
+func (h *JournaldHandler) send(data []byte) error {
+	_, err := h.conn.WriteToUnix(data, h.addr)
+	if err == nil || (!errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS)) {
+		return err
+	}
+
+	// write to a deleted temp file, and pass the fd to journald
+	f, err := os.CreateTemp("/dev/shm", "slog-journal-")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err = os.Remove(f.Name()); err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		return err
+	}
+
+	_, _, err = h.conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), h.addr)
+	return err
+}
+
+func (h *JournaldHandler) buildEntry(r *slog.Record, msg []byte) []byte {
+	buf := make([]byte, 0, len(msg)+128)
+	buf = appendJournalField(buf, "MESSAGE", msg)
+	buf = appendJournalField(buf, "PRIORITY", strconv.AppendUint(nil, uint64(SyslogSeverity(r.Level)), 10))
+	if h.cfg.Identifier != "" {
+		buf = appendJournalField(buf, "SYSLOG_IDENTIFIER", []byte(h.cfg.Identifier))
+	}
+	if r.Channel != "" {
+		buf = appendJournalField(buf, "SLOG_CHANNEL", []byte(r.Channel))
+	}
+
+	if r.Caller != nil {
+		buf = appendJournalField(buf, "CODE_FILE", []byte(r.Caller.File))
+		buf = appendJournalField(buf, "CODE_LINE", strconv.AppendInt(nil, int64(r.Caller.Line), 10))
+		buf = appendJournalField(buf, "CODE_FUNC", []byte(r.Caller.Function))
+	}
+
+	// sort the keys, keep the output stable
+	keys := make([]string, 0, len(r.Fields))
+	for k := range r.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		name := JournaldFieldName(k)
+		if name == "" {
+			continue
+		}
+
+		// avoid the multi-valued field with the handler written fields. eg: "message" -> "F_MESSAGE"
+		if _, ok := journaldOwnFields[name]; ok {
+			name = "F_" + name
+		}
+		buf = appendJournalField(buf, name, []byte(slog.EncodeToString(r.Fields[k])))
+	}
+	return buf
+}
+
+// the fields written by the JournaldHandler itself
+var journaldOwnFields = map[string]struct{}{
+	"MESSAGE":           {},
+	"PRIORITY":          {},
+	"SYSLOG_IDENTIFIER": {},
+	"SLOG_CHANNEL":      {},
+	"CODE_FILE":         {},
+	"CODE_LINE":         {},
+	"CODE_FUNC":         {},
+}
+
+// append the field by the native protocol. the value contains newline will be
+// serialized as binary: NAME\n<64bit LE length><value>\n
+func appendJournalField(buf []byte, name string, val []byte) []byte {
+	buf = append(buf, name...)
+	if bytes.IndexByte(val, '\n') < 0 {
+		buf = append(buf, '=')
+	} else {
+		buf = append(buf, '\n')
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(len(val)))
+	}
+
+	buf = append(buf, val...)
+	return append(buf, '\n')
+}
+
+// JournaldFieldName sanitize the key to journald field name.
+//
+// The name only allows "A-Z0-9_", can not start with "_" or digit, and max length is 64.
+// the lowercase letters are uppercased, the other chars are replaced by "_".
+// eg: "user.id" -> "USER_ID". returns empty on no valid char.
+func JournaldFieldName(key string) string {
+	bs := make([]byte, 0, len(key))
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			bs = append(bs, c-'a'+'A')
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			bs = append(bs, c)
+		default:
+			bs = append(bs, '_')
+		}
+	}
+
+	// the leading "_" is for the trusted fields
+	bs = bytes.TrimLeft(bs, "_")
+	if len(bs) == 0 {
+		return ""
+	}
+	if bs[0] >= '0' && bs[0] <= '9' {
+		bs = append([]byte("F_"), bs...)
+	}
+	if len(bs) > 64 {
+		bs = bs[:64]
+	}
+	return string(bs)
+}
+
+// Flush handler
+func (h *JournaldHandler) Flush() error {
+	return nil
+}
+
+// Close handler
+func (h *JournaldHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.conn == nil {
+		return nil
+	}
+
+	err := h.conn.Close()
+	h.conn = nil
+	return err
+}
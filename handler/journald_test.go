@@ -0,0 +1,123 @@
+//go:build linux
+
+package handler_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+// read one journald entry from the socket, support the entry passed by fd.
+func readJournalEntry(t *testing.T, conn *net.UnixConn) map[string]string {
+	buf := make([]byte, 1<<16)
+	oob := make([]byte, 128)
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	assert.NoErr(t, err)
+
+	data := buf[:n]
+	if oobn > 0 {
+		msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+		assert.NoErr(t, err)
+		fds, err := syscall.ParseUnixRights(&msgs[0])
+		assert.NoErr(t, err)
+
+		f := os.NewFile(uintptr(fds[0]), "journal-fd")
+		defer f.Close()
+		_, err = f.Seek(0, io.SeekStart)
+		assert.NoErr(t, err)
+		data, err = io.ReadAll(f)
+		assert.NoErr(t, err)
+	}
+
+	fields := make(map[string]string)
+	for len(data) > 0 {
+		i := bytes.IndexAny(data, "=\n")
+		name := string(data[:i])
+		if data[i] == '=' {
+			end := bytes.IndexByte(data, '\n')
+			fields[name] = string(data[i+1 : end])
+			data = data[end+1:]
+			continue
+		}
+
+		// binary value
+		size := int(binary.LittleEndian.Uint64(data[i+1:]))
+		fields[name] = string(data[i+9 : i+9+size])
+		data = data[i+10+size:]
+	}
+	return fields
+}
+
+func TestJournaldHandler_Handle(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: sock, Net: "unixgram"})
+	assert.NoErr(t, err)
+	defer conn.Close()
+
+	h := handler.NewJournaldHandler(func(c *handler.JournaldConfig) {
+		c.SocketPath = sock
+		c.Identifier = "myapp"
+	})
+	assert.Eq(t, sock, h.Config().SocketPath)
+	assert.True(t, h.IsHandling(slog.InfoLevel))
+
+	r := newLogRecord("line1\nline2")
+	r.Level = slog.WarnLevel
+	r.Fields = slog.M{"user_id": 23, "http.method": "GET", "_trusted": "x", "2fa": true, "-": "skip", "message": "field", "priority": 1}
+	assert.NoErr(t, h.Handle(r))
+
+	fields := readJournalEntry(t, conn)
+	assert.Eq(t, map[string]string{
+		"MESSAGE":           "line1\nline2",
+		"PRIORITY":          "4",
+		"SYSLOG_IDENTIFIER": "myapp",
+		"SLOG_CHANNEL":      "handler_test",
+		"USER_ID":           "23",
+		"HTTP_METHOD":       "GET",
+		"TRUSTED":           "x",
+		"F_2FA":             "true",
+		"F_MESSAGE":         "field",
+		"F_PRIORITY":        "1",
+	}, fields)
+
+	// the large entry is passed by fd
+	msg := strings.Repeat("a", 512*1024)
+	assert.NoErr(t, h.Handle(newLogRecord(msg)))
+	fields = readJournalEntry(t, conn)
+	assert.Eq(t, msg, fields["MESSAGE"])
+	assert.Eq(t, "6", fields["PRIORITY"])
+
+	assert.NoErr(t, h.Flush())
+	assert.NoErr(t, h.Close())
+}
+
+func TestJournaldHandler_fallback(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h := handler.NewJournaldHandler(func(c *handler.JournaldConfig) {
+		c.SocketPath = filepath.Join(t.TempDir(), "not-exists.sock")
+		c.Fallback = buf
+	})
+
+	assert.NoErr(t, h.Handle(newLogRecord("fallback message")))
+	assert.Eq(t, "fallback message\n", buf.String())
+	assert.NoErr(t, h.Close())
+}
+
+func TestJournaldFieldName(t *testing.T) {
+	assert.Eq(t, "USER_ID", handler.JournaldFieldName("user_id"))
+	assert.Eq(t, "HTTP_STATUS", handler.JournaldFieldName("http.status"))
+	assert.Eq(t, "F_1ST", handler.JournaldFieldName("1st"))
+	assert.Eq(t, "", handler.JournaldFieldName("__"))
+	assert.Eq(t, 64, len(handler.JournaldFieldName(strings.Repeat("k", 100))))
+}
@@ -0,0 +1,441 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gookit/slog"
+)
+
+var (
+	// ErrKafkaClosed error on write to a closed KafkaHandler
+	ErrKafkaClosed = errors.New("slog: the kafka handler has been closed")
+	// ErrKafkaQueueFull error on the KafkaHandler queue is full, the record is dropped.
+	ErrKafkaQueueFull = errors.New("slog: the kafka handler queue is full")
+)
+
+// KafkaConfig struct for the KafkaHandler
+type KafkaConfig struct {
+	// Brokers the bootstrap broker list. default is ["127.0.0.1:9092"]
+	Brokers []string `json:"brokers" yaml:"brokers"`
+	// Topic to produce the records
+	Topic string `json:"topic" yaml:"topic"`
+	// ClientID for the requests. default is "slog"
+	ClientID string `json:"client_id" yaml:"client_id"`
+	// KeyField the message key and partition key. default is "channel"
+	//
+	// "channel" use the Record.Channel, others use the value in Record.Fields. eg: "tenant"
+	// the records with same key will be produced to same partition, keep the order.
+	// if the key is empty, the records will be produced to partitions by round-robin.
+	KeyField string `json:"key_field" yaml:"key_field"`
+	// Acks the required acks. 0: no response, 1: leader only, -1: all in-sync replicas. default is 1
+	Acks int16 `json:"acks" yaml:"acks"`
+	// QueueSize the max pending records number. Handle will drop the record and return ErrKafkaQueueFull on full.
+	// default is 1024
+	QueueSize int `json:"queue_size" yaml:"queue_size"`
+	// BatchSize produce the batch when pending records reach the number. default is 100
+	BatchSize int `json:"batch_size" yaml:"batch_size"`
+	// FlushInterval produce the pending batch on each interval. default is 1s, set 0 to disable
+	FlushInterval time.Duration `json:"flush_interval" yaml:"flush_interval"`
+	// Timeout for connect, write and wait response. default is 3s
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// MaxRetry max retry times on produce failed, will refresh metadata and reconnect before retry. default is 3
+	MaxRetry int `json:"max_retry" yaml:"max_retry"`
+	// RetryWait the first wait time before retry, will double it on each retry. default is 500ms
+	RetryWait time.Duration `json:"retry_wait" yaml:"retry_wait"`
+	// OnError report the delivery error with the dropped records number.
+	// default will print the error to stderr.
+	OnError func(err error, n int) `json:"-" yaml:"-"`
+}
+
+// KafkaHandler produce the formatted records to a Kafka topic, on a background goroutine.
+//
+// The message value is the formatted record, the message key is by KafkaConfig.KeyField.
+// the partition is selected by the murmur2 hash of the key, same as the Kafka Java client.
+//
+// NOTICE: the authentication(SASL, TLS) and compression are not supported.
+// the batch may be produced repeatedly on retry, it is at-least-once delivery.
+//
+// refer: https://kafka.apache.org/protocol.html
+type KafkaHandler struct {
+	slog.LevelWithFormatter
+	cfg   *KafkaConfig
+	queue chan *kafkaMsg
+	// notify the producer to send the batch
+	flushCh  chan struct{}
+	flushing int32
+
+	// lock for close the queue
+	rw     sync.RWMutex
+	closed bool
+	done   chan struct{}
+
+	// pending records number, for wait on Flush()
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending int
+	lastErr error
+
+	// below fields are only used on the producer goroutine
+	meta   *kafkaMeta
+	conns  map[int32]net.Conn
+	corrID int32
+	// for round-robin partitioning
+	rrIdx uint32
+}
+
+// NewKafkaHandler create new KafkaHandler. the connections will be created on first produce.
+//
+// Usage:
+//
+//	h := handler.NewKafkaHandler([]string{"127.0.0.1:9092"}, "app-logs", func(c *handler.KafkaConfig) {
+//		c.KeyField = "tenant"
+//		c.Acks = -1
+//	})
+//	h.SetFormatter(slog.NewJSONFormatter())
+func NewKafkaHandler(brokers []string, topic string, fns ...func(c *KafkaConfig)) *KafkaHandler {
+	cfg := &KafkaConfig{
+		Brokers:       brokers,
+		Topic:         topic,
+		ClientID:      "slog",
+		KeyField:      slog.FieldKeyChannel,
+		Acks:          1,
+		QueueSize:     1024,
+		BatchSize:     100,
+		FlushInterval: time.Second,
+		Timeout:       3 * time.Second,
+		MaxRetry:      3,
+		RetryWait:     500 * time.Millisecond,
+	}
+	for _, fn := range fns {
+		fn(cfg)
+	}
+
+	if len(cfg.Brokers) == 0 {
+		cfg.Brokers = []string{"127.0.0.1:9092"}
+	}
+	if cfg.QueueSize < 1 {
+		cfg.QueueSize = 1
+	}
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = 1
+	}
+
+	h := &KafkaHandler{
+		cfg:     cfg,
+		queue:   make(chan *kafkaMsg, cfg.QueueSize),
+		flushCh: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		conns:   make(map[int32]net.Conn),
+	}
+	h.cond = sync.NewCond(&h.mu)
+	// init default log level
+	h.Level = slog.InfoLevel
+
+	go h.produceLoop()
+	return h
+}
+
+// Config get the handler config
+func (h *KafkaHandler) Config() *KafkaConfig {
+	return h.cfg
+}
+
+// Handle format the record and push it to queue
+func (h *KafkaHandler) Handle(r *slog.Record) error {
+	bts, err := h.Format(r)
+	if err != nil {
+		return err
+	}
+
+	ts := r.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	// must copy it, the formatter may reuse the buffer
+	msg := &kafkaMsg{
+		key:   h.messageKey(r),
+		value: append([]byte(nil), bytes.TrimRight(bts, "\r\n")...),
+		time:  ts,
+	}
+
+	h.rw.RLock()
+	defer h.rw.RUnlock()
+	if h.closed {
+		return ErrKafkaClosed
+	}
+
+	h.addPending(1)
+	select {
+	case h.queue <- msg:
+		return nil
+	default: // queue is full
+		h.addPending(-1)
+		return ErrKafkaQueueFull
+	}
+}
+
+// get the message key by KeyField. returns nil on the key is empty.
+func (h *KafkaHandler) messageKey(r *slog.Record) []byte {
+	var key string
+	if h.cfg.KeyField == slog.FieldKeyChannel {
+		key = r.Channel
+	} else if val, ok := r.Fields[h.cfg.KeyField]; ok && val != nil {
+		key = fmt.Sprint(val)
+	}
+
+	if key == "" {
+		return nil
+	}
+	return []byte(key)
+}
+
+func (h *KafkaHandler) addPending(n int) {
+	h.mu.Lock()
+	h.pending += n
+	if h.pending == 0 {
+		h.cond.Broadcast()
+	}
+	h.mu.Unlock()
+}
+
+// Flush wait all queued records produced. returns the last delivery error.
+func (h *KafkaHandler) Flush() error {
+	atomic.AddInt32(&h.flushing, 1)
+	defer atomic.AddInt32(&h.flushing, -1)
+
+	// notify the producer to send the batch now
+	select {
+	case h.flushCh <- struct{}{}:
+	default:
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for h.pending > 0 {
+		h.cond.Wait()
+	}
+
+	err := h.lastErr
+	h.lastErr = nil
+	return err
+}
+
+// Close stop receive new records, wait all queued records produced, then close the connections.
+func (h *KafkaHandler) Close() error {
+	h.rw.Lock()
+	if h.closed {
+		h.rw.Unlock()
+		return nil
+	}
+	h.closed = true
+	close(h.queue)
+	h.rw.Unlock()
+
+	<-h.done
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.lastErr
+}
+
+func (h *KafkaHandler) produceLoop() {
+	defer close(h.done)
+	defer h.closeConns()
+
+	var tickCh <-chan time.Time
+	if h.cfg.FlushInterval > 0 {
+		tk := time.NewTicker(h.cfg.FlushInterval)
+		defer tk.Stop()
+		tickCh = tk.C
+	}
+
+	batch := make([]*kafkaMsg, 0, h.cfg.BatchSize)
+	for {
+		select {
+		case msg, ok := <-h.queue:
+			if !ok {
+				h.produce(batch)
+				return
+			}
+
+			batch = append(batch, msg)
+			// on flushing, send the batch once the queue is drained
+			if len(batch) >= h.cfg.BatchSize || (len(h.queue) == 0 && atomic.LoadInt32(&h.flushing) > 0) {
+				h.produce(batch)
+				batch = batch[:0]
+			}
+		case <-h.flushCh:
+			h.produce(batch)
+			batch = batch[:0]
+		case <-tickCh:
+			h.produce(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// produce the batch with retry, and report the delivery error.
+func (h *KafkaHandler) produce(batch []*kafkaMsg) {
+	if len(batch) == 0 {
+		return
+	}
+
+	var err error
+	wait := h.cfg.RetryWait
+	for i := 0; i <= h.cfg.MaxRetry; i++ {
+		if i > 0 {
+			time.Sleep(wait)
+			wait *= 2
+		}
+
+		if err = h.send(batch); err == nil {
+			break
+		}
+
+		// refresh the metadata and reconnect on retry
+		h.meta = nil
+		h.closeConns()
+	}
+
+	if err != nil {
+		if h.cfg.OnError != nil {
+			h.cfg.OnError(err, len(batch))
+		} else {
+			printErrln(fmt.Sprintf("slog: kafka handler produce error(dropped %d records):", len(batch)), err)
+		}
+	}
+
+	h.mu.Lock()
+	if err != nil {
+		h.lastErr = err
+	}
+	h.pending -= len(batch)
+	if h.pending == 0 {
+		h.cond.Broadcast()
+	}
+	h.mu.Unlock()
+}
+
+// send the batch to the partition leaders
+func (h *KafkaHandler) send(batch []*kafkaMsg) error {
+	if h.meta == nil {
+		meta, err := h.fetchMetadata()
+		if err != nil {
+			return err
+		}
+		h.meta = meta
+	}
+
+	// group by the leader and partition, keep the order in each partition
+	groups := make(map[int32]map[int32][]*kafkaMsg)
+	for _, msg := range batch {
+		partition := h.partition(msg.key)
+		leader := h.meta.leaders[partition]
+		if groups[leader] == nil {
+			groups[leader] = make(map[int32][]*kafkaMsg)
+		}
+		groups[leader][partition] = append(groups[leader][partition], msg)
+	}
+
+	for leader, parts := range groups {
+		batches := make(map[int32][]byte, len(parts))
+		for partition, msgs := range parts {
+			batches[partition] = buildKafkaRecordBatch(msgs)
+		}
+
+		body := buildKafkaProduceBody(h.cfg.Topic, h.cfg.Acks, h.cfg.Timeout, batches)
+		resp, err := h.request(leader, kafkaAPIProduce, 3, body)
+		if err != nil {
+			return err
+		}
+		if h.cfg.Acks != 0 {
+			if err = parseKafkaProduceResponse(resp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// select the partition by the key hash, or round-robin on the key is empty.
+func (h *KafkaHandler) partition(key []byte) int32 {
+	num := uint32(len(h.meta.leaders))
+	if key == nil {
+		h.rrIdx++
+		return int32(h.rrIdx % num)
+	}
+	return int32(uint32(kafkaMurmur2(key)&0x7fffffff) % num)
+}
+
+// fetch the topic metadata from the bootstrap brokers
+func (h *KafkaHandler) fetchMetadata() (meta *kafkaMeta, err error) {
+	body := buildKafkaMetadataBody(h.cfg.Topic)
+	for _, addr := range h.cfg.Brokers {
+		var conn net.Conn
+		conn, err = dialTimeout("tcp", addr, h.cfg.Timeout, nil)
+		if err != nil {
+			continue
+		}
+
+		var resp []byte
+		resp, err = h.roundTrip(conn, kafkaAPIMetadata, 4, body, true)
+		_ = conn.Close()
+		if err != nil {
+			continue
+		}
+
+		if meta, err = parseKafkaMetadata(resp, h.cfg.Topic); err == nil {
+			return meta, nil
+		}
+	}
+	return nil, err
+}
+
+// send the request to the broker, will connect to it on not connected.
+func (h *KafkaHandler) request(brokerID int32, apiKey, version int16, body []byte) ([]byte, error) {
+	conn := h.conns[brokerID]
+	if conn == nil {
+		addr, ok := h.meta.brokers[brokerID]
+		if !ok {
+			return nil, fmt.Errorf("slog: kafka partition leader %d is not available", brokerID)
+		}
+
+		var err error
+		if conn, err = dialTimeout("tcp", addr, h.cfg.Timeout, nil); err != nil {
+			return nil, err
+		}
+		h.conns[brokerID] = conn
+	}
+
+	// the broker does not send response on acks=0 produce
+	wantResp := apiKey != kafkaAPIProduce || h.cfg.Acks != 0
+	return h.roundTrip(conn, apiKey, version, body, wantResp)
+}
+
+func (h *KafkaHandler) roundTrip(conn net.Conn, apiKey, version int16, body []byte, wantResp bool) ([]byte, error) {
+	h.corrID++
+	req := buildKafkaRequest(apiKey, version, h.corrID, h.cfg.ClientID, body)
+
+	_ = setConnDeadline(conn, h.cfg.Timeout)
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+	if !wantResp {
+		return nil, nil
+	}
+	return readKafkaResponse(conn, h.corrID)
+}
+
+func (h *KafkaHandler) closeConns() {
+	for id, conn := range h.conns {
+		_ = conn.Close()
+		delete(h.conns, id)
+	}
+}
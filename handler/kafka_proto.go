@@ -0,0 +1,306 @@
+package handler
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+// a minimal Kafka protocol client for the KafkaHandler. only the Metadata(v4) and Produce(v3) APIs are used.
+//
+// refer: https://kafka.apache.org/protocol.html
+
+const (
+	kafkaAPIProduce  int16 = 0
+	kafkaAPIMetadata int16 = 3
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+var errKafkaFormat = errors.New("slog: invalid kafka response")
+
+// kafkaMsg a formatted record for produce
+type kafkaMsg struct {
+	key   []byte
+	value []byte
+	time  time.Time
+}
+
+// kafkaMeta the topic metadata. the partitions index is the partition id
+type kafkaMeta struct {
+	brokers map[int32]string
+	// the leader broker id of each partition
+	leaders []int32
+}
+
+// build the request with header v1: api_key, api_version, correlation_id, client_id
+func buildKafkaRequest(apiKey, version int16, corrID int32, clientID string, body []byte) []byte {
+	b := make([]byte, 4, 14+len(clientID)+len(body))
+	b = binary.BigEndian.AppendUint16(b, uint16(apiKey))
+	b = binary.BigEndian.AppendUint16(b, uint16(version))
+	b = binary.BigEndian.AppendUint32(b, uint32(corrID))
+	b = appendKafkaStr(b, clientID)
+	b = append(b, body...)
+
+	binary.BigEndian.PutUint32(b, uint32(len(b)-4))
+	return b
+}
+
+// read the response, returns the body after the correlation id.
+func readKafkaResponse(r io.Reader, corrID int32) ([]byte, error) {
+	var head [8]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, err
+	}
+
+	size := int(binary.BigEndian.Uint32(head[:]))
+	if size < 4 || size > 64*1024*1024 {
+		return nil, errKafkaFormat
+	}
+
+	bs := make([]byte, size-4)
+	if _, err := io.ReadFull(r, bs); err != nil {
+		return nil, err
+	}
+
+	if got := int32(binary.BigEndian.Uint32(head[4:])); got != corrID {
+		return nil, fmt.Errorf("slog: kafka correlation id mismatch, want %d, got %d", corrID, got)
+	}
+	return bs, nil
+}
+
+func appendKafkaStr(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// Metadata v4 request body: topics, allow_auto_topic_creation
+func buildKafkaMetadataBody(topic string) []byte {
+	b := binary.BigEndian.AppendUint32(nil, 1)
+	b = appendKafkaStr(b, topic)
+	return append(b, 0x01)
+}
+
+// Produce v3 request body: transactional_id, acks, timeout_ms, topic_data
+func buildKafkaProduceBody(topic string, acks int16, timeout time.Duration, batches map[int32][]byte) []byte {
+	b := binary.BigEndian.AppendUint16(nil, 0xffff) // null transactional_id
+	b = binary.BigEndian.AppendUint16(b, uint16(acks))
+	b = binary.BigEndian.AppendUint32(b, uint32(timeout.Milliseconds()))
+
+	b = binary.BigEndian.AppendUint32(b, 1)
+	b = appendKafkaStr(b, topic)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(batches)))
+	for partition, batch := range batches {
+		b = binary.BigEndian.AppendUint32(b, uint32(partition))
+		b = binary.BigEndian.AppendUint32(b, uint32(len(batch)))
+		b = append(b, batch...)
+	}
+	return b
+}
+
+// build the record batch(magic v2), without compression.
+func buildKafkaRecordBatch(msgs []*kafkaMsg) []byte {
+	first := msgs[0].time.UnixMilli()
+	maxTs := first
+	for _, msg := range msgs {
+		if ts := msg.time.UnixMilli(); ts > maxTs {
+			maxTs = ts
+		}
+	}
+
+	b := make([]byte, 0, 128)
+	b = binary.BigEndian.AppendUint64(b, 0)          // base offset
+	b = binary.BigEndian.AppendUint32(b, 0)          // placeholder for batch length
+	b = binary.BigEndian.AppendUint32(b, 0xffffffff) // partition leader epoch
+	b = append(b, 2)                                 // magic
+	b = binary.BigEndian.AppendUint32(b, 0)          // placeholder for crc
+	crcStart := len(b)
+	b = binary.BigEndian.AppendUint16(b, 0) // attributes
+	b = binary.BigEndian.AppendUint32(b, uint32(len(msgs)-1))
+	b = binary.BigEndian.AppendUint64(b, uint64(first))
+	b = binary.BigEndian.AppendUint64(b, uint64(maxTs))
+	b = binary.BigEndian.AppendUint64(b, 0xffffffffffffffff) // producer id
+	b = binary.BigEndian.AppendUint16(b, 0xffff)             // producer epoch
+	b = binary.BigEndian.AppendUint32(b, 0xffffffff)         // base sequence
+	b = binary.BigEndian.AppendUint32(b, uint32(len(msgs)))
+
+	var rec []byte
+	for i, msg := range msgs {
+		rec = append(rec[:0], 0) // attributes
+		rec = binary.AppendVarint(rec, msg.time.UnixMilli()-first)
+		rec = binary.AppendVarint(rec, int64(i))
+		if msg.key == nil {
+			rec = binary.AppendVarint(rec, -1)
+		} else {
+			rec = binary.AppendVarint(rec, int64(len(msg.key)))
+			rec = append(rec, msg.key...)
+		}
+		rec = binary.AppendVarint(rec, int64(len(msg.value)))
+		rec = append(rec, msg.value...)
+		rec = binary.AppendVarint(rec, 0) // headers
+
+		b = binary.AppendVarint(b, int64(len(rec)))
+		b = append(b, rec...)
+	}
+
+	binary.BigEndian.PutUint32(b[8:], uint32(len(b)-12))
+	binary.BigEndian.PutUint32(b[crcStart-4:], crc32.Checksum(b[crcStart:], crc32cTable))
+	return b
+}
+
+// a simple big-endian reader for parse the response
+type kafkaReader struct {
+	bs  []byte
+	err error
+}
+
+func (r *kafkaReader) next(n int) []byte {
+	if r.err != nil || n < 0 || len(r.bs) < n {
+		r.err = errKafkaFormat
+		return make([]byte, 8)
+	}
+
+	bs := r.bs[:n]
+	r.bs = r.bs[n:]
+	return bs
+}
+
+func (r *kafkaReader) int16() int16 { return int16(binary.BigEndian.Uint16(r.next(2))) }
+func (r *kafkaReader) int32() int32 { return int32(binary.BigEndian.Uint32(r.next(4))) }
+func (r *kafkaReader) int64() int64 { return int64(binary.BigEndian.Uint64(r.next(8))) }
+
+// read the string or nullable string
+func (r *kafkaReader) str() string {
+	n := r.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(r.next(int(n)))
+}
+
+// read the array length
+func (r *kafkaReader) arrayLen() int {
+	n := int(r.int32())
+	if n < 0 { // null array
+		return 0
+	}
+	if n > len(r.bs) {
+		r.err = errKafkaFormat
+		return 0
+	}
+	return n
+}
+
+// parse the Metadata v4 response for the topic
+func parseKafkaMetadata(bs []byte, topic string) (*kafkaMeta, error) {
+	r := &kafkaReader{bs: bs}
+	r.int32() // throttle_time_ms
+
+	meta := &kafkaMeta{brokers: make(map[int32]string)}
+	for i, n := 0, r.arrayLen(); i < n; i++ {
+		id := r.int32()
+		host := r.str()
+		port := r.int32()
+		r.str() // rack
+		meta.brokers[id] = fmt.Sprintf("%s:%d", host, port)
+	}
+
+	r.str()   // cluster_id
+	r.int32() // controller_id
+
+	var found bool
+	for i, n := 0, r.arrayLen(); i < n; i++ {
+		errCode := r.int16()
+		name := r.str()
+		r.next(1) // is_internal
+
+		pNum := r.arrayLen()
+		leaders := make([]int32, pNum)
+		for j := 0; j < pNum; j++ {
+			r.int16() // partition error_code
+			id := r.int32()
+			leader := r.int32()
+			r.next(4 * r.arrayLen()) // replica_nodes
+			r.next(4 * r.arrayLen()) // isr_nodes
+			if id >= 0 && int(id) < pNum {
+				leaders[id] = leader
+			}
+		}
+
+		if name != topic {
+			continue
+		}
+		if errCode != 0 {
+			return nil, fmt.Errorf("slog: kafka metadata error code %d for topic %q", errCode, topic)
+		}
+		found = true
+		meta.leaders = leaders
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	if !found || len(meta.leaders) == 0 {
+		return nil, fmt.Errorf("slog: kafka topic %q is not found", topic)
+	}
+	return meta, nil
+}
+
+// parse the Produce v3 response, returns the first partition error.
+func parseKafkaProduceResponse(bs []byte) error {
+	r := &kafkaReader{bs: bs}
+	for i, n := 0, r.arrayLen(); i < n; i++ {
+		r.str() // topic name
+		for j, pNum := 0, r.arrayLen(); j < pNum; j++ {
+			partition := r.int32()
+			errCode := r.int16()
+			r.int64() // base_offset
+			r.int64() // log_append_time_ms
+			if errCode != 0 && r.err == nil {
+				return fmt.Errorf("slog: kafka produce error code %d on partition %d", errCode, partition)
+			}
+		}
+	}
+	return r.err
+}
+
+// murmur2 hash, same as the Kafka Java client default partitioner.
+func kafkaMurmur2(data []byte) int32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		rr          = 24
+	)
+
+	length := len(data)
+	h := seed ^ uint32(length)
+	for i := 0; i+4 <= length; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> rr
+		k *= m
+		h *= m
+		h ^= k
+	}
+
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return int32(h)
+}
@@ -0,0 +1,300 @@
+package handler_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+type kafkaRecord struct {
+	partition int32
+	key       string
+	value     string
+}
+
+// a fake kafka broker, only handle the Metadata v4 and Produce v3 requests
+type kafkaRecv struct {
+	ln         net.Listener
+	partitions int
+	// reply the error code on produce
+	errCode int16
+
+	mu       sync.Mutex
+	produced int
+	records  []kafkaRecord
+}
+
+func newKafkaServer(t *testing.T, partitions int) *kafkaRecv {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoErr(t, err)
+
+	kr := &kafkaRecv{ln: ln, partitions: partitions}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go kr.serve(conn)
+		}
+	}()
+	return kr
+}
+
+func (kr *kafkaRecv) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return
+		}
+
+		bs := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(conn, bs); err != nil {
+			return
+		}
+
+		apiKey := binary.BigEndian.Uint16(bs)
+		corrID := bs[4:8]
+		// skip the client id
+		bs = bs[10+int(binary.BigEndian.Uint16(bs[8:])):]
+
+		var resp []byte
+		if apiKey == 3 {
+			resp = kr.metadataResp(bs)
+		} else {
+			resp = kr.produceResp(bs)
+		}
+
+		out := binary.BigEndian.AppendUint32(nil, uint32(4+len(resp)))
+		out = append(append(out, corrID...), resp...)
+		_, _ = conn.Write(out)
+	}
+}
+
+func (kr *kafkaRecv) metadataResp(body []byte) []byte {
+	topic := string(body[6 : 6+int(binary.BigEndian.Uint16(body[4:]))])
+	host, port, _ := net.SplitHostPort(kr.ln.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+
+	b := binary.BigEndian.AppendUint32(nil, 0) // throttle
+	b = binary.BigEndian.AppendUint32(b, 1)    // brokers
+	b = binary.BigEndian.AppendUint32(b, 7)    // node id
+	b = appendTestKafkaStr(b, host)
+	b = binary.BigEndian.AppendUint32(b, uint32(portNum))
+	b = binary.BigEndian.AppendUint16(b, 0xffff) // rack
+	b = binary.BigEndian.AppendUint16(b, 0xffff) // cluster id
+	b = binary.BigEndian.AppendUint32(b, 7)      // controller
+
+	b = binary.BigEndian.AppendUint32(b, 1) // topics
+	b = binary.BigEndian.AppendUint16(b, 0)
+	b = appendTestKafkaStr(b, topic)
+	b = append(b, 0)
+	b = binary.BigEndian.AppendUint32(b, uint32(kr.partitions))
+	for i := 0; i < kr.partitions; i++ {
+		b = binary.BigEndian.AppendUint16(b, 0)
+		b = binary.BigEndian.AppendUint32(b, uint32(i))
+		b = binary.BigEndian.AppendUint32(b, 7) // leader
+		b = binary.BigEndian.AppendUint32(b, 1)
+		b = binary.BigEndian.AppendUint32(b, 7)
+		b = binary.BigEndian.AppendUint32(b, 1)
+		b = binary.BigEndian.AppendUint32(b, 7)
+	}
+	return b
+}
+
+func (kr *kafkaRecv) produceResp(bs []byte) []byte {
+	// skip transactional id, acks, timeout, topics length
+	bs = bs[12:]
+	topic := string(bs[2 : 2+int(binary.BigEndian.Uint16(bs))])
+	bs = bs[2+len(topic):]
+
+	pNum := int(binary.BigEndian.Uint32(bs))
+	bs = bs[4:]
+
+	b := binary.BigEndian.AppendUint32(nil, 1)
+	b = appendTestKafkaStr(b, topic)
+	b = binary.BigEndian.AppendUint32(b, uint32(pNum))
+	for i := 0; i < pNum; i++ {
+		partition := int32(binary.BigEndian.Uint32(bs))
+		size := int(binary.BigEndian.Uint32(bs[4:]))
+		records := decodeKafkaRecordBatch(bs[8 : 8+size])
+		bs = bs[8+size:]
+
+		kr.mu.Lock()
+		kr.produced++
+		if kr.errCode == 0 {
+			for _, rec := range records {
+				rec.partition = partition
+				kr.records = append(kr.records, rec)
+			}
+		}
+		kr.mu.Unlock()
+
+		b = binary.BigEndian.AppendUint32(b, uint32(partition))
+		b = binary.BigEndian.AppendUint16(b, uint16(kr.errCode))
+		b = binary.BigEndian.AppendUint64(b, 0)
+		b = binary.BigEndian.AppendUint64(b, 0xffffffffffffffff)
+	}
+	return binary.BigEndian.AppendUint32(b, 0) // throttle
+}
+
+func (kr *kafkaRecv) Records() []kafkaRecord {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	return kr.records
+}
+
+func appendTestKafkaStr(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// decode the record batch v2, will panic on crc mismatch
+func decodeKafkaRecordBatch(bs []byte) []kafkaRecord {
+	crc := binary.BigEndian.Uint32(bs[17:])
+	if crc != crc32.Checksum(bs[21:], crc32.MakeTable(crc32.Castagnoli)) {
+		panic("kafka record batch crc mismatch")
+	}
+
+	num := int(binary.BigEndian.Uint32(bs[57:]))
+	bs = bs[61:]
+
+	readVarint := func() int {
+		v, n := binary.Varint(bs)
+		bs = bs[n:]
+		return int(v)
+	}
+
+	records := make([]kafkaRecord, 0, num)
+	for i := 0; i < num; i++ {
+		readVarint() // length
+		bs = bs[1:]  // attributes
+		readVarint() // timestamp delta
+		readVarint() // offset delta
+
+		var rec kafkaRecord
+		if n := readVarint(); n >= 0 {
+			rec.key = string(bs[:n])
+			bs = bs[n:]
+		}
+		n := readVarint()
+		rec.value = string(bs[:n])
+		bs = bs[n:]
+		readVarint() // headers
+
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestKafkaHandler_produce(t *testing.T) {
+	kr := newKafkaServer(t, 3)
+	defer kr.ln.Close()
+
+	h := handler.NewKafkaHandler([]string{kr.ln.Addr().String()}, "app-logs", func(c *handler.KafkaConfig) {
+		c.KeyField = "tenant"
+		c.BatchSize = 4
+		c.FlushInterval = 0
+	})
+	h.SetFormatter(slog.NewTextFormatter("{{message}}\n"))
+	assert.True(t, h.IsHandling(slog.InfoLevel))
+	assert.False(t, h.IsHandling(slog.DebugLevel))
+
+	for i := 0; i < 6; i++ {
+		r := newLogRecord("message" + strconv.Itoa(i))
+		r.Fields = slog.M{"tenant": "t" + strconv.Itoa(i%2)}
+		assert.NoErr(t, h.Handle(r))
+	}
+	// no key, round-robin
+	assert.NoErr(t, h.Handle(newLogRecord("no key")))
+	assert.NoErr(t, h.Flush())
+
+	records := kr.Records()
+	assert.Len(t, records, 7)
+
+	// same key to same partition, keep the order
+	partitions := make(map[string]int32)
+	values := make(map[string][]string)
+	for _, rec := range records {
+		if rec.key == "" {
+			assert.Eq(t, "no key", rec.value)
+			continue
+		}
+
+		if p, ok := partitions[rec.key]; ok {
+			assert.Eq(t, p, rec.partition)
+		}
+		partitions[rec.key] = rec.partition
+		values[rec.key] = append(values[rec.key], rec.value)
+	}
+	assert.Eq(t, []string{"message0", "message2", "message4"}, values["t0"])
+	assert.Eq(t, []string{"message1", "message3", "message5"}, values["t1"])
+
+	assert.NoErr(t, h.Close())
+	assert.ErrIs(t, h.Handle(newLogRecord("closed")), handler.ErrKafkaClosed)
+}
+
+func TestKafkaHandler_channelKey(t *testing.T) {
+	kr := newKafkaServer(t, 2)
+	defer kr.ln.Close()
+
+	h := handler.NewKafkaHandler([]string{kr.ln.Addr().String()}, "app-logs", func(c *handler.KafkaConfig) {
+		c.FlushInterval = 10 * time.Millisecond
+	})
+	h.SetFormatter(slog.NewTextFormatter("{{message}}"))
+
+	assert.NoErr(t, h.Handle(newLogRecord("message")))
+	// the pending record is produced on close
+	assert.NoErr(t, h.Close())
+
+	records := kr.Records()
+	assert.Len(t, records, 1)
+	assert.Eq(t, "handler_test", records[0].key)
+	assert.Eq(t, "message", records[0].value)
+}
+
+func TestKafkaHandler_error(t *testing.T) {
+	kr := newKafkaServer(t, 1)
+	kr.errCode = 2 // CORRUPT_MESSAGE
+
+	var dropped int
+	var lastErr error
+	h := handler.NewKafkaHandler([]string{kr.ln.Addr().String()}, "app-logs", func(c *handler.KafkaConfig) {
+		c.FlushInterval = 0
+		c.MaxRetry = 1
+		c.RetryWait = time.Millisecond
+		c.OnError = func(err error, n int) {
+			lastErr = err
+			dropped += n
+		}
+	})
+
+	assert.NoErr(t, h.Handle(newLogRecord("message1")))
+	err := h.Flush()
+	assert.ErrSubMsg(t, err, "error code 2")
+	assert.Eq(t, err, lastErr)
+	assert.Eq(t, 1, dropped)
+	// retried once
+	kr.mu.Lock()
+	assert.Eq(t, 2, kr.produced)
+	kr.mu.Unlock()
+
+	// broker is down
+	assert.NoErr(t, kr.ln.Close())
+	assert.NoErr(t, h.Handle(newLogRecord("message2")))
+	err = h.Close()
+	assert.Err(t, err)
+	assert.False(t, errors.Is(err, handler.ErrKafkaClosed))
+	assert.Eq(t, 2, dropped)
+}
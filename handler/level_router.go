@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"github.com/gookit/goutil/errorx"
+	"github.com/gookit/slog"
+)
+
+type levelRange struct {
+	maxLevel slog.Level
+	handler  slog.Handler
+}
+
+// LevelRouterHandler route the records to different child handlers by the record level.
+//
+// Match order: exact level routes, level range routes(by added order), the default handler.
+//
+// Usage:
+//
+//	h := handler.NewLevelRouter(nil, stdoutHandler).RouteMax(slog.ErrorLevel, stderrHandler)
+type LevelRouterHandler struct {
+	routes map[slog.Level]slog.Handler
+	ranges []levelRange
+	// default handler for unmapped levels. allow be nil
+	defaultH slog.Handler
+	// unique child handlers, for flush and close
+	handlers []slog.Handler
+}
+
+// NewLevelRouter create new LevelRouterHandler. the defaultH allow be nil.
+func NewLevelRouter(routes map[slog.Level]slog.Handler, defaultH slog.Handler) *LevelRouterHandler {
+	h := &LevelRouterHandler{
+		routes: make(map[slog.Level]slog.Handler, len(routes)),
+	}
+
+	for level, sub := range routes {
+		h.Route(sub, level)
+	}
+
+	if defaultH != nil {
+		h.defaultH = defaultH
+		h.addUnique(defaultH)
+	}
+	return h
+}
+
+// Route the levels to the handler. should be called before logging.
+func (h *LevelRouterHandler) Route(sub slog.Handler, levels ...slog.Level) *LevelRouterHandler {
+	for _, level := range levels {
+		h.routes[level] = sub
+	}
+	h.addUnique(sub)
+	return h
+}
+
+// RouteMax route the levels that level <= maxLevel to the handler. should be called before logging.
+//
+// eg: RouteMax(slog.ErrorLevel, h) will route panic, fatal, error levels to h.
+func (h *LevelRouterHandler) RouteMax(maxLevel slog.Level, sub slog.Handler) *LevelRouterHandler {
+	h.ranges = append(h.ranges, levelRange{maxLevel: maxLevel, handler: sub})
+	h.addUnique(sub)
+	return h
+}
+
+func (h *LevelRouterHandler) addUnique(sub slog.Handler) {
+	for _, exist := range h.handlers {
+		if exist == sub {
+			return
+		}
+	}
+	h.handlers = append(h.handlers, sub)
+}
+
+// HandlerFor get the routed child handler for the level. returns nil if not found.
+func (h *LevelRouterHandler) HandlerFor(level slog.Level) slog.Handler {
+	if sub, ok := h.routes[level]; ok {
+		return sub
+	}
+
+	for _, lr := range h.ranges {
+		if lr.maxLevel.ShouldHandling(level) {
+			return lr.handler
+		}
+	}
+	return h.defaultH
+}
+
+// IsHandling Check if the routed child handler can handle the level
+func (h *LevelRouterHandler) IsHandling(level slog.Level) bool {
+	sub := h.HandlerFor(level)
+	return sub != nil && sub.IsHandling(level)
+}
+
+// Handle the record by the routed child handler
+func (h *LevelRouterHandler) Handle(r *slog.Record) error {
+	sub := h.HandlerFor(r.Level)
+	if sub == nil || !sub.IsHandling(r.Level) {
+		return nil
+	}
+	return sub.Handle(r)
+}
+
+// Flush all child handlers
+func (h *LevelRouterHandler) Flush() error {
+	var es errorx.Errors
+	for _, sub := range h.handlers {
+		if err := sub.Flush(); err != nil {
+			es = append(es, err)
+		}
+	}
+	return es.ErrorOrNil()
+}
+
+// Close all child handlers
+func (h *LevelRouterHandler) Close() error {
+	var es errorx.Errors
+	for _, sub := range h.handlers {
+		if err := sub.Close(); err != nil {
+			es = append(es, err)
+		}
+	}
+	return es.ErrorOrNil()
+}
@@ -0,0 +1,57 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+func TestLevelRouterHandler(t *testing.T) {
+	stdout := new(syncMsgs)
+	stderr := new(syncMsgs)
+	debug := new(syncMsgs)
+
+	h := handler.NewLevelRouter(map[slog.Level]slog.Handler{
+		slog.DebugLevel: debug,
+	}, stdout).RouteMax(slog.ErrorLevel, stderr)
+
+	assert.Eq(t, stderr, h.HandlerFor(slog.FatalLevel))
+	assert.Eq(t, debug, h.HandlerFor(slog.DebugLevel))
+	assert.Eq(t, stdout, h.HandlerFor(slog.InfoLevel))
+	assert.True(t, h.IsHandling(slog.TraceLevel))
+
+	l := slog.NewWithHandlers(h)
+	l.Error("error message")
+	l.Warn("warn message")
+	l.Info("info message")
+	l.Debug("debug message")
+
+	assert.Eq(t, []string{"error message"}, stderr.Messages())
+	assert.Eq(t, []string{"warn message", "info message"}, stdout.Messages())
+	assert.Eq(t, []string{"debug message"}, debug.Messages())
+
+	assert.NoErr(t, h.Flush())
+	assert.NoErr(t, h.Close())
+}
+
+func TestLevelRouterHandler_noDefault(t *testing.T) {
+	stderr := new(syncMsgs)
+	h := handler.NewLevelRouter(nil, nil).Route(stderr, slog.ErrorLevel, slog.WarnLevel)
+
+	assert.True(t, h.IsHandling(slog.WarnLevel))
+	assert.False(t, h.IsHandling(slog.InfoLevel))
+	assert.Nil(t, h.HandlerFor(slog.InfoLevel))
+
+	assert.NoErr(t, h.Handle(newLogRecord("info message")))
+	assert.Len(t, stderr.Messages(), 0)
+
+	// close child handler only once
+	th := newTestHandler()
+	th.errOnClose = true
+	h = handler.NewLevelRouter(map[slog.Level]slog.Handler{
+		slog.InfoLevel: th,
+	}, th).RouteMax(slog.ErrorLevel, th)
+	assert.ErrMsg(t, h.Close(), "close error\n")
+}
@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gookit/slog"
+)
+
+// LokiPushPath the Loki push API path
+const LokiPushPath = "/loki/api/v1/push"
+
+// LokiConfig struct for the LokiHandler
+type LokiConfig struct {
+	// URL the Loki server address. eg: "http://localhost:3100"
+	//
+	// will append the LokiPushPath if it is not ends with it.
+	URL string `json:"url" yaml:"url"`
+	// Labels static labels for all streams. eg: {"app": "myapp", "env": "prod"}
+	Labels map[string]string `json:"labels" yaml:"labels"`
+	// LabelFields the record fields as stream labels. default is ["channel", "level"]
+	//
+	// "channel" and "level" will read from the record, others read from Record.Fields.
+	LabelFields []string `json:"label_fields" yaml:"label_fields"`
+	// TenantID will be set to the X-Scope-OrgID header on multi-tenant mode
+	TenantID string `json:"tenant_id" yaml:"tenant_id"`
+	// Username and Password for basic auth
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+	// BatchSize send the batch when pending entries reach the number. default is 100
+	BatchSize int `json:"batch_size" yaml:"batch_size"`
+	// FlushInterval send the pending batch on each interval. default is 5s, set 0 to disable
+	FlushInterval time.Duration `json:"flush_interval" yaml:"flush_interval"`
+	// MaxRetry max retry times on send failed or response 5xx. default is 3
+	MaxRetry int `json:"max_retry" yaml:"max_retry"`
+	// RetryWait the first wait time before retry, will double it on each retry. default is 500ms
+	RetryWait time.Duration `json:"retry_wait" yaml:"retry_wait"`
+	// Timeout for each push request, include the connect and read the response. default is 10s
+	//
+	// NOTICE: it is not used on the Client is custom.
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// Client custom the http client. default is new client with the Timeout
+	Client *http.Client `json:"-" yaml:"-"`
+}
+
+type lokiEntry struct {
+	// labels key for group entries to stream
+	key    string
+	labels map[string]string
+	// [unix nano timestamp, log line]
+	value [2]string
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// LokiHandler push log records to the Grafana Loki, support batching.
+//
+// The records will be grouped to streams by the labels, the entry line is formatted by the handler formatter.
+//
+// refer: https://grafana.com/docs/loki/latest/reference/loki-http-api/#ingest-logs
+type LokiHandler struct {
+	slog.LevelWithFormatter
+	cfg     *LokiConfig
+	pushURL string
+
+	mu sync.Mutex
+	// pending entries
+	batch  []lokiEntry
+	closed bool
+	// for stop the flush ticker
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewLokiHandler create new LokiHandler
+//
+// Usage:
+//
+//	h := handler.NewLokiHandler("http://localhost:3100", func(c *handler.LokiConfig) {
+//		c.Labels = map[string]string{"app": "myapp"}
+//		c.TenantID = "team-a"
+//	})
+func NewLokiHandler(url string, fns ...func(c *LokiConfig)) *LokiHandler {
+	cfg := &LokiConfig{
+		URL:           url,
+		LabelFields:   []string{slog.FieldKeyChannel, slog.FieldKeyLevel},
+		BatchSize:     100,
+		FlushInterval: 5 * time.Second,
+		MaxRetry:      3,
+		RetryWait:     500 * time.Millisecond,
+		Timeout:       10 * time.Second,
+	}
+	for _, fn := range fns {
+		fn(cfg)
+	}
+
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = 1
+	}
+	cfg.Client = httpClientOr(cfg.Client, cfg.Timeout)
+
+	pushURL := strings.TrimRight(cfg.URL, "/")
+	if !strings.HasSuffix(pushURL, LokiPushPath) {
+		pushURL += LokiPushPath
+	}
+
+	h := &LokiHandler{cfg: cfg, pushURL: pushURL}
+	// init default log level
+	h.Level = slog.InfoLevel
+
+	if cfg.FlushInterval > 0 {
+		h.stopCh = make(chan struct{})
+		h.wg.Add(1)
+		go h.flushDaemon()
+	}
+	return h
+}
+
+// Config get the handler config
+func (h *LokiHandler) Config() *LokiConfig {
+	return h.cfg
+}
+
+// Handle a log record. will send the batch on reach the BatchSize
+func (h *LokiHandler) Handle(r *slog.Record) error {
+	bts, err := h.Format(r)
+	if err != nil {
+		return err
+	}
+
+	ts := r.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	labels := h.buildLabels(r)
+	entry := lokiEntry{
+		key:    labelsKey(labels),
+		labels: labels,
+		value:  [2]string{strconv.FormatInt(ts.UnixNano(), 10), string(bytes.TrimRight(bts, "\r\n"))},
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.batch = append(h.batch, entry)
+	if len(h.batch) >= h.cfg.BatchSize {
+		return h.sendBatch()
+	}
+	return nil
+}
+
+func (h *LokiHandler) buildLabels(r *slog.Record) map[string]string {
+	labels := make(map[string]string, len(h.cfg.Labels)+len(h.cfg.LabelFields))
+	for k, v := range h.cfg.Labels {
+		labels[k] = v
+	}
+
+	for _, field := range h.cfg.LabelFields {
+		switch field {
+		case slog.FieldKeyChannel:
+			labels[field] = r.Channel
+		case slog.FieldKeyLevel:
+			labels[field] = r.Level.LowerName()
+		default:
+			if val := r.Field(field); val != nil {
+				labels[field] = fmt.Sprint(val)
+			}
+		}
+	}
+	return labels
+}
+
+// build sorted labels string. eg: `app=myapp,level=info`
+func labelsKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(strconv.Quote(labels[k]))
+		sb.WriteByte(',')
+	}
+	return sb.String()
+}
+
+// Flush force send the pending batch
+func (h *LokiHandler) Flush() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sendBatch()
+}
+
+// Close the handler. will flush the pending batch, then stop the flush ticker.
+func (h *LokiHandler) Close() error {
+	err := h.Flush()
+
+	h.mu.Lock()
+	if !h.closed && h.stopCh != nil {
+		close(h.stopCh)
+	}
+	h.closed = true
+	h.mu.Unlock()
+
+	h.wg.Wait()
+	return err
+}
+
+func (h *LokiHandler) flushDaemon() {
+	defer h.wg.Done()
+	tk := time.NewTicker(h.cfg.FlushInterval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			printErrln("slog: loki handler flush error:", h.Flush())
+		case <-h.stopCh:
+			return
+		}
+	}
+}
+
+// send pending batch. should be in lock
+func (h *LokiHandler) sendBatch() error {
+	if len(h.batch) == 0 {
+		return nil
+	}
+
+	body, err := buildLokiBody(h.batch)
+	// reset batch, the failed batch will be dropped
+	h.batch = h.batch[:0]
+	if err != nil {
+		return err
+	}
+
+	return postWithRetry(h.cfg.Client, h.cfg.MaxRetry, h.cfg.RetryWait, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPost, h.pushURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		if h.cfg.TenantID != "" {
+			req.Header.Set("X-Scope-OrgID", h.cfg.TenantID)
+		}
+		if h.cfg.Username != "" {
+			req.SetBasicAuth(h.cfg.Username, h.cfg.Password)
+		}
+		return req, nil
+	})
+}
+
+// build the push body, group the entries to streams by labels.
+func buildLokiBody(batch []lokiEntry) ([]byte, error) {
+	var streams []*lokiStream
+	index := make(map[string]*lokiStream)
+
+	for _, entry := range batch {
+		st, ok := index[entry.key]
+		if !ok {
+			st = &lokiStream{Stream: entry.labels}
+			index[entry.key] = st
+			streams = append(streams, st)
+		}
+		st.Values = append(st.Values, entry.value)
+	}
+
+	return json.Marshal(map[string]any{"streams": streams})
+}
@@ -0,0 +1,74 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+func TestLokiHandler_batch(t *testing.T) {
+	hr := &httpRecv{}
+	srv := newHTTPServer(hr, 1)
+	defer srv.Close()
+
+	h := handler.NewLokiHandler(srv.URL+"/", func(c *handler.LokiConfig) {
+		c.Labels = map[string]string{"app": "test"}
+		c.LabelFields = append(c.LabelFields, "user")
+		c.TenantID = "team-a"
+		c.Username, c.Password = "admin", "pwd"
+		c.BatchSize = 3
+		c.FlushInterval = 0
+		c.RetryWait = time.Millisecond
+	})
+	h.SetFormatter(newTestFormatter())
+
+	r1 := newLogRecord("info message")
+	r2 := newLogRecord("error message")
+	r2.Level = slog.ErrorLevel
+	r3 := newLogRecord("info message 2")
+	r3.AddField("user", "inhere")
+
+	assert.NoErr(t, h.Handle(r1))
+	assert.NoErr(t, h.Handle(r2))
+	assert.Empty(t, hr.Bodies())
+	assert.NoErr(t, h.Handle(r3))
+
+	bodies := hr.Bodies()
+	assert.Len(t, bodies, 1)
+	assert.Eq(t, handler.LokiPushPath, hr.path)
+	assert.Eq(t, "team-a", hr.header.Get("X-Scope-OrgID"))
+	assert.Eq(t, "application/json", hr.header.Get("Content-Type"))
+	assert.StrContains(t, hr.header.Get("Authorization"), "Basic ")
+
+	var body struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	assert.NoErr(t, json.Unmarshal([]byte(bodies[0]), &body))
+	assert.Len(t, body.Streams, 3)
+
+	st := body.Streams[0]
+	assert.Eq(t, map[string]string{"app": "test", "channel": "handler_test", "level": "info"}, st.Stream)
+	assert.Eq(t, strconv.FormatInt(r1.Time.UnixNano(), 10), st.Values[0][0])
+	assert.Eq(t, "info message", st.Values[0][1])
+	assert.Eq(t, "error", body.Streams[1].Stream["level"])
+	assert.Eq(t, "inhere", body.Streams[2].Stream["user"])
+
+	// same labels in one stream
+	assert.NoErr(t, h.Handle(newLogRecord("message 1")))
+	assert.NoErr(t, h.Handle(newLogRecord("message 2")))
+	assert.NoErr(t, h.Close())
+
+	bodies = hr.Bodies()
+	assert.Len(t, bodies, 2)
+	assert.NoErr(t, json.Unmarshal([]byte(bodies[1]), &body))
+	assert.Len(t, body.Streams, 1)
+	assert.Len(t, body.Streams[0].Values, 2)
+}
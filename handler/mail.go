@@ -0,0 +1,328 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gookit/slog"
+)
+
+// DefaultMailSubject the default subject template for MailHandler
+const DefaultMailSubject = "[{{channel}}] {{count}} log records, max level: {{level}}"
+
+// MailConfig struct for the MailHandler
+type MailConfig struct {
+	// Host the SMTP server host. eg: "smtp.gmail.com"
+	Host string `json:"host" yaml:"host"`
+	// Port the SMTP server port. eg: 587, 465
+	Port int `json:"port" yaml:"port"`
+	// Username and Password for SMTP auth. skip auth on Username is empty
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+	// UseTLS connect to the server by implicit TLS(usually port 465).
+	//
+	// if is false, will upgrade by STARTTLS when the server supports it.
+	UseTLS bool `json:"use_tls" yaml:"use_tls"`
+	// TLSConfig custom the tls config. default will set the ServerName by Host
+	TLSConfig *tls.Config `json:"-" yaml:"-"`
+
+	// From the sender address
+	From string `json:"from" yaml:"from"`
+	// To the receiver addresses
+	To []string `json:"to" yaml:"to"`
+	// Subject template for the email. default is DefaultMailSubject
+	//
+	// allow vars: {{channel}}, {{level}}, {{count}}, {{message}}.
+	// {{level}} is the max level name in the batch, {{message}} is the first record message.
+	Subject string `json:"subject" yaml:"subject"`
+
+	// BatchWindow collect the records in the window after first record, then send as one email.
+	// default is 10s, set 0 to send each record immediately.
+	BatchWindow time.Duration `json:"batch_window" yaml:"batch_window"`
+	// MaxBatch send the batch immediately when pending records reach the number. default is 100
+	MaxBatch int `json:"max_batch" yaml:"max_batch"`
+	// Timeout for connect and send one email by SMTP. default is 30s
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+
+	// SendFunc custom the send logic. default is send by the SMTP config
+	SendFunc func(c *MailConfig, msg []byte) error `json:"-" yaml:"-"`
+}
+
+// Addr get the SMTP server address
+func (c *MailConfig) Addr() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+// MailHandler send the log records by email, useful for alert the critical errors.
+//
+// The records in a BatchWindow will be collected into one email, to avoid the email storms.
+// The email body is the formatted records joined by the handler formatter.
+//
+// The send errors on the window timer will be printed to stderr, and returned by next Handle/Flush/Close call.
+type MailHandler struct {
+	slog.LevelWithFormatter
+	cfg *MailConfig
+
+	mu sync.Mutex
+	// pending formatted records
+	batch [][]byte
+	// info for build subject
+	channel  string
+	message  string
+	maxLevel slog.Level
+	// the window timer
+	timer *time.Timer
+	// last send error on the window timer
+	err    error
+	failed uint64
+}
+
+// NewMailHandler create new MailHandler, default handle the ErrorLevel and above records.
+//
+// Usage:
+//
+//	h := handler.NewMailHandler(func(c *handler.MailConfig) {
+//		c.Host, c.Port = "smtp.example.com", 587
+//		c.Username, c.Password = "alert@example.com", "PASSWORD"
+//		c.From = "alert@example.com"
+//		c.To = []string{"dev@example.com"}
+//	})
+func NewMailHandler(fns ...func(c *MailConfig)) *MailHandler {
+	cfg := &MailConfig{
+		Subject:     DefaultMailSubject,
+		BatchWindow: 10 * time.Second,
+		MaxBatch:    100,
+		Timeout:     30 * time.Second,
+	}
+	for _, fn := range fns {
+		fn(cfg)
+	}
+
+	if cfg.MaxBatch < 1 {
+		cfg.MaxBatch = 1
+	}
+	if cfg.SendFunc == nil {
+		cfg.SendFunc = smtpSendMail
+	}
+
+	h := &MailHandler{cfg: cfg}
+	// init default log level
+	h.Level = slog.ErrorLevel
+	return h
+}
+
+// Config get the handler config
+func (h *MailHandler) Config() *MailConfig {
+	return h.cfg
+}
+
+// Failed get the number of records that failed to send
+func (h *MailHandler) Failed() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.failed
+}
+
+// Handle a log record. will send the batch on reach the MaxBatch or window is 0
+func (h *MailHandler) Handle(r *slog.Record) error {
+	bts, err := h.Format(r)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	if len(h.batch) == 0 {
+		h.channel, h.message, h.maxLevel = r.Channel, r.Message, r.Level
+	} else if r.Level < h.maxLevel {
+		h.maxLevel = r.Level
+	}
+	// the formatted bytes maybe reused by formatter
+	h.batch = append(h.batch, append([]byte(nil), bts...))
+
+	if len(h.batch) >= h.cfg.MaxBatch || h.cfg.BatchWindow <= 0 {
+		msg, num := h.takeBatch()
+		h.mu.Unlock()
+		return h.send(msg, num)
+	}
+
+	if h.timer == nil {
+		h.timer = time.AfterFunc(h.cfg.BatchWindow, h.onWindowEnd)
+	}
+	err = h.takeErr()
+	h.mu.Unlock()
+	return err
+}
+
+func (h *MailHandler) onWindowEnd() {
+	h.mu.Lock()
+	h.timer = nil
+	msg, num := h.takeBatch()
+	h.mu.Unlock()
+
+	if err := h.send(msg, num); err != nil {
+		h.mu.Lock()
+		h.err = err
+		h.mu.Unlock()
+		printErrln("slog: mail handler send error:", err)
+	}
+}
+
+// Flush returns the last send error on the window timer.
+//
+// NOTICE: it does not send the pending batch, the Logger flushes the handlers after each
+// error record, will break the BatchWindow. use Send() for send the pending batch now.
+func (h *MailHandler) Flush() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.takeErr()
+}
+
+// Send force send the pending batch now
+func (h *MailHandler) Send() error {
+	h.mu.Lock()
+	msg, num := h.takeBatch()
+	err := h.takeErr()
+	h.mu.Unlock()
+
+	if sendErr := h.send(msg, num); sendErr != nil {
+		return sendErr
+	}
+	return err
+}
+
+// Close the handler. will send the pending batch
+func (h *MailHandler) Close() error {
+	return h.Send()
+}
+
+// get and clear the last async send error. should be in lock
+func (h *MailHandler) takeErr() error {
+	err := h.err
+	h.err = nil
+	return err
+}
+
+// take the pending batch and build the message, stop the window timer. should be in lock
+func (h *MailHandler) takeBatch() (msg []byte, num int) {
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+
+	num = len(h.batch)
+	if num == 0 {
+		return nil, 0
+	}
+
+	msg = h.buildMessage()
+	// reset batch, the failed batch will be dropped
+	h.batch = h.batch[:0]
+	return msg, num
+}
+
+// send the message by SendFunc. should be out of the lock, the SMTP session may be slow.
+func (h *MailHandler) send(msg []byte, num int) error {
+	if num == 0 {
+		return nil
+	}
+
+	if err := h.cfg.SendFunc(h.cfg, msg); err != nil {
+		h.mu.Lock()
+		h.failed += uint64(num)
+		h.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// build the email message, includes headers and body. should be in lock
+func (h *MailHandler) buildMessage() []byte {
+	subject := strings.NewReplacer(
+		"{{channel}}", h.channel,
+		"{{level}}", h.maxLevel.Name(),
+		"{{count}}", strconv.Itoa(len(h.batch)),
+		"{{message}}", h.message,
+	).Replace(h.cfg.Subject)
+	// avoid the header injection by the message
+	subject = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(subject)
+
+	var buf bytes.Buffer
+	buf.WriteString("From: " + h.cfg.From + "\r\n")
+	buf.WriteString("To: " + strings.Join(h.cfg.To, ", ") + "\r\n")
+	buf.WriteString("Subject: " + subject + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+
+	for _, bts := range h.batch {
+		buf.Write(bts)
+	}
+	return buf.Bytes()
+}
+
+// send the message by SMTP, support implicit TLS and STARTTLS.
+func smtpSendMail(c *MailConfig, msg []byte) error {
+	tlsCfg := c.TLSConfig
+	if tlsCfg == nil {
+		tlsCfg = &tls.Config{ServerName: c.Host}
+	}
+
+	var connTLS *tls.Config
+	if c.UseTLS {
+		connTLS = tlsCfg
+	}
+
+	conn, err := dialTimeout("tcp", c.Addr(), c.Timeout, connTLS)
+	if err != nil {
+		return err
+	}
+	// the deadline for the whole SMTP session
+	_ = setConnDeadline(conn, c.Timeout)
+
+	cli, err := smtp.NewClient(conn, c.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer cli.Close()
+
+	if !c.UseTLS {
+		if ok, _ := cli.Extension("STARTTLS"); ok {
+			if err = cli.StartTLS(tlsCfg); err != nil {
+				return err
+			}
+		}
+	}
+
+	if c.Username != "" {
+		if err = cli.Auth(smtp.PlainAuth("", c.Username, c.Password, c.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err = cli.Mail(c.From); err != nil {
+		return err
+	}
+	for _, to := range c.To {
+		if err = cli.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := cli.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return cli.Quit()
+}
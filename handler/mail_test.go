@@ -0,0 +1,156 @@
+package handler_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+type mailRecv struct {
+	mu   sync.Mutex
+	msgs []string
+	err  error
+}
+
+func (mr *mailRecv) send(_ *handler.MailConfig, msg []byte) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	if mr.err != nil {
+		return mr.err
+	}
+	mr.msgs = append(mr.msgs, string(msg))
+	return nil
+}
+
+func (mr *mailRecv) Msgs() []string {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	return mr.msgs
+}
+
+func TestMailHandler_batch(t *testing.T) {
+	mr := &mailRecv{}
+	h := handler.NewMailHandler(func(c *handler.MailConfig) {
+		c.From = "alert@example.com"
+		c.To = []string{"dev@example.com", "ops@example.com"}
+		c.Subject = "[{{channel}}] {{count}} errors: {{message}} ({{level}})"
+		c.BatchWindow = time.Minute
+		c.MaxBatch = 3
+		c.SendFunc = mr.send
+	})
+	h.SetFormatter(newTestFormatter())
+	assert.True(t, h.IsHandling(slog.ErrorLevel))
+	assert.False(t, h.IsHandling(slog.WarnLevel))
+
+	r1 := newLogRecord("error message1")
+	r1.Level = slog.ErrorLevel
+	r2 := newLogRecord("fatal message")
+	r2.Level = slog.FatalLevel
+
+	assert.NoErr(t, h.Handle(r1))
+	assert.NoErr(t, h.Handle(r2))
+	assert.Empty(t, mr.Msgs())
+
+	// flush does not send the pending batch
+	assert.NoErr(t, h.Flush())
+	assert.Empty(t, mr.Msgs())
+
+	// force send the pending batch
+	assert.NoErr(t, h.Send())
+	msgs := mr.Msgs()
+	assert.Len(t, msgs, 1)
+	assert.StrContains(t, msgs[0], "From: alert@example.com\r\n")
+	assert.StrContains(t, msgs[0], "To: dev@example.com, ops@example.com\r\n")
+	assert.StrContains(t, msgs[0], "Subject: [handler_test] 2 errors: error message1 (FATAL)\r\n")
+	assert.StrContains(t, msgs[0], "\r\n\r\nerror message1fatal message")
+
+	// send on reach MaxBatch
+	for i := 0; i < 3; i++ {
+		assert.NoErr(t, h.Handle(r1))
+	}
+	assert.Len(t, mr.Msgs(), 2)
+	assert.NoErr(t, h.Close())
+	assert.Len(t, mr.Msgs(), 2)
+}
+
+func TestMailHandler_window(t *testing.T) {
+	mr := &mailRecv{}
+	h := handler.NewMailHandler(func(c *handler.MailConfig) {
+		c.BatchWindow = 20 * time.Millisecond
+		c.SendFunc = mr.send
+	})
+	h.SetFormatter(newTestFormatter())
+
+	r := newLogRecord("error message")
+	r.Level = slog.ErrorLevel
+	assert.NoErr(t, h.Handle(r))
+	assert.NoErr(t, h.Handle(r))
+	assert.Empty(t, mr.Msgs())
+
+	time.Sleep(60 * time.Millisecond)
+	msgs := mr.Msgs()
+	assert.Len(t, msgs, 1)
+	assert.StrContains(t, msgs[0], "Subject: [handler_test] 2 log records, max level: ERROR\r\n")
+
+	// send failed on the window timer
+	mr.err = errors.New("smtp error")
+	assert.NoErr(t, h.Handle(r))
+	time.Sleep(60 * time.Millisecond)
+	assert.Eq(t, uint64(1), h.Failed())
+
+	err := h.Flush()
+	assert.Err(t, err)
+	assert.Eq(t, "smtp error", err.Error())
+	assert.NoErr(t, h.Flush())
+}
+
+func TestMailHandler_byLogger(t *testing.T) {
+	mr := &mailRecv{}
+	h := handler.NewMailHandler(func(c *handler.MailConfig) {
+		c.BatchWindow = time.Minute
+		c.SendFunc = mr.send
+	})
+	h.SetFormatter(newTestFormatter())
+
+	// the logger flush the handlers after each error record
+	l := slog.NewWithHandlers(h)
+	for i := 0; i < 5; i++ {
+		l.Error("db down\r\nBcc: evil@example.com")
+	}
+	assert.Empty(t, mr.Msgs())
+
+	assert.NoErr(t, l.Close())
+	msgs := mr.Msgs()
+	assert.Len(t, msgs, 1)
+	assert.StrContains(t, msgs[0], "Subject: [application] 5 log records, max level: ERROR\r\n")
+
+	// the CR, LF in subject are replaced
+	mr.msgs = nil
+	h.Config().Subject = "{{message}}"
+	l = slog.NewWithHandlers(h)
+	l.Error("db down\r\nBcc: evil@example.com")
+	assert.NoErr(t, h.Send())
+	assert.StrContains(t, mr.Msgs()[0], "Subject: db down Bcc: evil@example.com\r\n")
+}
+
+func TestMailHandler_noWindow(t *testing.T) {
+	mr := &mailRecv{err: errors.New("smtp error")}
+	h := handler.NewMailHandler(func(c *handler.MailConfig) {
+		c.BatchWindow = 0
+		c.SendFunc = mr.send
+	})
+
+	r := newLogRecord("error message")
+	r.Level = slog.ErrorLevel
+	assert.Err(t, h.Handle(r))
+	assert.Eq(t, uint64(1), h.Failed())
+
+	mr.err = nil
+	assert.NoErr(t, h.Handle(r))
+	assert.Len(t, mr.Msgs(), 1)
+}
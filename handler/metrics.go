@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"github.com/gookit/slog"
+)
+
+// MetricsCounter increment the records counter by level and channel.
+type MetricsCounter interface {
+	Inc(level, channel string)
+}
+
+// MetricsCounterFunc wrapper definition
+type MetricsCounterFunc func(level, channel string)
+
+// Inc the records counter
+func (fn MetricsCounterFunc) Inc(level, channel string) {
+	fn(level, channel)
+}
+
+// MetricsHandler an observe-only handler, only count the handled records by level and channel.
+// it does not write logs anywhere.
+//
+// For Prometheus, use prometheus.NewMetricsHandler() in the module github.com/gookit/slog/prometheus.
+type MetricsHandler struct {
+	NopFlushClose
+	slog.LevelHandling
+	counter MetricsCounter
+}
+
+// NewMetricsHandler create new MetricsHandler, will handle all levels by default.
+//
+// Usage:
+//
+//	h := handler.NewMetricsHandler(handler.MetricsCounterFunc(func(level, channel string) {
+//		counts[level+"/"+channel]++
+//	}))
+func NewMetricsHandler(counter MetricsCounter) *MetricsHandler {
+	h := &MetricsHandler{counter: counter}
+	h.SetLimitLevels(slog.AllLevels)
+	return h
+}
+
+// Handle a log record, increment the counter by the lower level name and channel.
+func (h *MetricsHandler) Handle(r *slog.Record) error {
+	h.counter.Inc(r.Level.LowerName(), r.Channel)
+	return nil
+}
@@ -0,0 +1,34 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+func TestNewMetricsHandler(t *testing.T) {
+	counts := make(map[string]int)
+	h := handler.NewMetricsHandler(handler.MetricsCounterFunc(func(level, channel string) {
+		counts[level+"/"+channel]++
+	}))
+	assert.True(t, h.IsHandling(slog.DebugLevel))
+	assert.True(t, h.IsHandling(slog.ErrorLevel))
+
+	l := slog.NewWithHandlers(h)
+	l.Info("info message")
+	l.Error("error message")
+	l.Error("error message 2")
+	l.WithField("user", "inhere").Info("info message 2")
+
+	r := newLogRecord("channel message")
+	assert.NoErr(t, h.Handle(r))
+	assert.NoErr(t, l.Close())
+
+	assert.Eq(t, map[string]int{
+		"info/application":  2,
+		"error/application": 2,
+		"info/handler_test": 1,
+	}, counts)
+}
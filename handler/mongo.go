@@ -0,0 +1,396 @@
+package handler
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gookit/slog"
+)
+
+// MongoConfig struct for the MongoHandler
+type MongoConfig struct {
+	// Addr the mongod address. default is "127.0.0.1:27017"
+	Addr string `json:"addr" yaml:"addr"`
+	// DB the database name. default is "logs"
+	DB string `json:"db" yaml:"db"`
+	// Collection the collection name. default is "slog"
+	Collection string `json:"collection" yaml:"collection"`
+	// CappedSize create the collection as capped collection with the max size in bytes. default is 0, not create.
+	CappedSize int64 `json:"capped_size" yaml:"capped_size"`
+	// CappedMax the max documents number of the capped collection. default is 0, no limit.
+	CappedMax int64 `json:"capped_max" yaml:"capped_max"`
+	// TTL create the TTL index on the time key for retention. default is 0, not create.
+	//
+	// NOTICE: the TTL index is not supported on capped collection, should not use with CappedSize.
+	TTL time.Duration `json:"ttl" yaml:"ttl"`
+	// BatchSize insert the batch when pending records reach the number. default is 100
+	BatchSize int `json:"batch_size" yaml:"batch_size"`
+	// FlushInterval insert the pending batch on each interval. default is 3s, set 0 to disable
+	FlushInterval time.Duration `json:"flush_interval" yaml:"flush_interval"`
+	// Timeout for connect, write and wait reply. default is 3s
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// MaxRetry max retry times on insert failed, will reconnect before retry. default is 3
+	MaxRetry int `json:"max_retry" yaml:"max_retry"`
+	// RetryWait the first wait time before retry, will double it on each retry. default is 500ms
+	RetryWait time.Duration `json:"retry_wait" yaml:"retry_wait"`
+}
+
+// the key name for the numeric level in the document
+const mongoLevelNoKey = "level_no"
+
+// OP_MSG op code of the MongoDB wire protocol
+const mongoOpMsg = 2013
+
+// MongoHandler insert log records as BSON documents to MongoDB collection, support batching.
+//
+// Each record will be inserted as a document includes: time(BSON date), level name, level_no,
+// channel, message, the Record.Fields, and Record.Data, Record.Extra on they are not empty.
+//
+// The capped collection and the TTL index will be created on first connect, by the CappedSize and TTL.
+//
+// NOTICE: the formatter is not used, the record is inserted as structured data.
+// the authentication is not supported, should connect to a trusted mongod or a local proxy.
+//
+// refer: https://www.mongodb.com/docs/manual/reference/mongodb-wire-protocol/
+type MongoHandler struct {
+	slog.LevelHandling
+	cfg *MongoConfig
+
+	mu    sync.Mutex
+	conn  net.Conn
+	reqID int32
+	// mark the collection and indexes are created
+	inited bool
+	// pending documents
+	batch  []any
+	closed bool
+	// for stop the flush ticker
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewMongoHandler create new MongoHandler. the connection will be created on first insert.
+//
+// Usage:
+//
+//	h := handler.NewMongoHandler("127.0.0.1:27017", func(c *handler.MongoConfig) {
+//		c.DB = "myapp"
+//		c.Collection = "logs"
+//		c.TTL = 7 * 24 * time.Hour
+//	})
+func NewMongoHandler(addr string, fns ...func(c *MongoConfig)) *MongoHandler {
+	cfg := &MongoConfig{
+		Addr:          addr,
+		DB:            "logs",
+		Collection:    "slog",
+		BatchSize:     100,
+		FlushInterval: 3 * time.Second,
+		Timeout:       3 * time.Second,
+		MaxRetry:      3,
+		RetryWait:     500 * time.Millisecond,
+	}
+	for _, fn := range fns {
+		fn(cfg)
+	}
+
+	if cfg.Addr == "" {
+		cfg.Addr = "127.0.0.1:27017"
+	}
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = 1
+	}
+
+	h := &MongoHandler{cfg: cfg}
+	// init default log level
+	h.SetMaxLevel(slog.InfoLevel)
+
+	if cfg.FlushInterval > 0 {
+		h.stopCh = make(chan struct{})
+		h.wg.Add(1)
+		go h.flushDaemon()
+	}
+	return h
+}
+
+// Config get the handler config
+func (h *MongoHandler) Config() *MongoConfig {
+	return h.cfg
+}
+
+// Handle a log record. will insert the batch on reach the BatchSize
+func (h *MongoHandler) Handle(r *slog.Record) error {
+	doc := buildMongoDoc(r)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.batch = append(h.batch, doc)
+	if len(h.batch) >= h.cfg.BatchSize {
+		return h.insertBatch()
+	}
+	return nil
+}
+
+// build the record to document. the Record.Fields are sorted, and will not overwrite the standard keys.
+func buildMongoDoc(r *slog.Record) bsonDoc {
+	ts := r.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	doc := make(bsonDoc, 0, len(r.Fields)+7)
+	doc = append(doc,
+		bsonElem{Key: slog.FieldKeyTime, Val: ts},
+		bsonElem{Key: slog.FieldKeyLevel, Val: r.Level.Name()},
+		bsonElem{Key: mongoLevelNoKey, Val: int(r.Level)},
+		bsonElem{Key: slog.FieldKeyChannel, Val: r.Channel},
+		bsonElem{Key: slog.FieldKeyMessage, Val: r.Message},
+	)
+
+	keys := make([]string, 0, len(r.Fields))
+	for k := range r.Fields {
+		switch k {
+		case slog.FieldKeyTime, slog.FieldKeyLevel, mongoLevelNoKey, slog.FieldKeyChannel, slog.FieldKeyMessage:
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		doc = append(doc, bsonElem{Key: k, Val: r.Fields[k]})
+	}
+
+	if len(r.Data) > 0 {
+		doc = append(doc, bsonElem{Key: slog.FieldKeyData, Val: r.Data})
+	}
+	if len(r.Extra) > 0 {
+		doc = append(doc, bsonElem{Key: slog.FieldKeyExtra, Val: r.Extra})
+	}
+	return doc
+}
+
+// Flush force insert the pending batch
+func (h *MongoHandler) Flush() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.insertBatch()
+}
+
+// Close the handler. will flush the pending batch, then stop the flush ticker and close the connection.
+func (h *MongoHandler) Close() error {
+	err := h.Flush()
+
+	h.mu.Lock()
+	if !h.closed && h.stopCh != nil {
+		close(h.stopCh)
+	}
+	h.closed = true
+	h.mu.Unlock()
+
+	h.wg.Wait()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.conn != nil {
+		if cErr := h.conn.Close(); err == nil {
+			err = cErr
+		}
+		h.conn = nil
+	}
+	return err
+}
+
+func (h *MongoHandler) flushDaemon() {
+	defer h.wg.Done()
+	tk := time.NewTicker(h.cfg.FlushInterval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			printErrln("slog: mongo handler flush error:", h.Flush())
+		case <-h.stopCh:
+			return
+		}
+	}
+}
+
+// insert pending batch by InsertMany, will reconnect and retry on failed. should be in lock
+func (h *MongoHandler) insertBatch() (err error) {
+	if len(h.batch) == 0 {
+		return nil
+	}
+
+	cmd := bsonDoc{
+		{Key: "insert", Val: h.cfg.Collection},
+		{Key: "documents", Val: h.batch},
+		{Key: "$db", Val: h.cfg.DB},
+	}
+	msg := h.buildMessage(cmd)
+	// reset batch, the failed batch will be dropped
+	h.batch = h.batch[:0]
+
+	wait := h.cfg.RetryWait
+	for i := 0; i <= h.cfg.MaxRetry; i++ {
+		if i > 0 {
+			time.Sleep(wait)
+			wait *= 2
+		}
+
+		if err = h.ensureConn(); err == nil {
+			var reply map[string]any
+			reply, err = h.roundTrip(msg)
+			// the server replied the error, not retry
+			if err == nil || reply != nil {
+				return err
+			}
+		}
+
+		// close the broken connection, will reconnect on next insert
+		if h.conn != nil {
+			_ = h.conn.Close()
+			h.conn = nil
+		}
+	}
+	return err
+}
+
+// connect to the server, and create the capped collection and TTL index on first connect.
+func (h *MongoHandler) ensureConn() (err error) {
+	if h.conn == nil {
+		h.conn, err = dialTimeout("tcp", h.cfg.Addr, h.cfg.Timeout, nil)
+		if err != nil {
+			return err
+		}
+	}
+	if h.inited {
+		return nil
+	}
+
+	if h.cfg.CappedSize > 0 {
+		cmd := bsonDoc{
+			{Key: "create", Val: h.cfg.Collection},
+			{Key: "capped", Val: true},
+			{Key: "size", Val: h.cfg.CappedSize},
+		}
+		if h.cfg.CappedMax > 0 {
+			cmd = append(cmd, bsonElem{Key: "max", Val: h.cfg.CappedMax})
+		}
+		cmd = append(cmd, bsonElem{Key: "$db", Val: h.cfg.DB})
+
+		// ignore the error on collection exists
+		reply, err := h.roundTrip(h.buildMessage(cmd))
+		if err != nil && bsonNumber(reply["code"]) != 48 {
+			return err
+		}
+	}
+
+	if h.cfg.TTL > 0 {
+		index := bsonDoc{
+			{Key: "key", Val: bsonDoc{{Key: slog.FieldKeyTime, Val: 1}}},
+			{Key: "name", Val: "slog_time_ttl"},
+			{Key: "expireAfterSeconds", Val: int64(h.cfg.TTL / time.Second)},
+		}
+		cmd := bsonDoc{
+			{Key: "createIndexes", Val: h.cfg.Collection},
+			{Key: "indexes", Val: []any{index}},
+			{Key: "$db", Val: h.cfg.DB},
+		}
+		if _, err = h.roundTrip(h.buildMessage(cmd)); err != nil {
+			return err
+		}
+	}
+
+	h.inited = true
+	return nil
+}
+
+// build the OP_MSG message with the command document as body section.
+func (h *MongoHandler) buildMessage(cmd bsonDoc) []byte {
+	h.reqID++
+
+	b := make([]byte, 16, 512)
+	binary.LittleEndian.PutUint32(b[4:], uint32(h.reqID))
+	binary.LittleEndian.PutUint32(b[12:], mongoOpMsg)
+
+	b = binary.LittleEndian.AppendUint32(b, 0) // flag bits
+	b = append(b, 0x00)                        // section kind: body
+	b = appendBSONDoc(b, cmd)
+
+	binary.LittleEndian.PutUint32(b, uint32(len(b)))
+	return b
+}
+
+// send the message and read the reply document. will return error on the reply is not ok.
+func (h *MongoHandler) roundTrip(msg []byte) (map[string]any, error) {
+	_ = setConnDeadline(h.conn, h.cfg.Timeout)
+	if _, err := h.conn.Write(msg); err != nil {
+		return nil, err
+	}
+
+	reply, err := readMongoReply(h.conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if bsonNumber(reply["ok"]) != 1 {
+		return reply, fmt.Errorf("slog: mongo command error(code=%v): %v", reply["code"], reply["errmsg"])
+	}
+	if wErrs, ok := reply["writeErrors"].([]any); ok && len(wErrs) > 0 {
+		wErr, _ := wErrs[0].(map[string]any)
+		return reply, fmt.Errorf("slog: mongo write error(count=%d): %v", len(wErrs), wErr["errmsg"])
+	}
+	return reply, nil
+}
+
+// read the OP_MSG reply, returns the body document
+func readMongoReply(r io.Reader) (map[string]any, error) {
+	var head [16]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, err
+	}
+
+	size := int(binary.LittleEndian.Uint32(head[:]))
+	if size < 21 || size > 48*1024*1024 {
+		return nil, errBSONFormat
+	}
+
+	bs := make([]byte, size-16)
+	if _, err := io.ReadFull(r, bs); err != nil {
+		return nil, err
+	}
+
+	if op := binary.LittleEndian.Uint32(head[12:]); op != mongoOpMsg {
+		return nil, fmt.Errorf("slog: unexpected mongo reply op code %d", op)
+	}
+
+	// skip flag bits, the section kind must be body
+	if len(bs) < 9 || bs[4] != 0x00 {
+		return nil, errBSONFormat
+	}
+
+	bs = bs[5:]
+	docLen := int(binary.LittleEndian.Uint32(bs))
+	if docLen > len(bs) {
+		return nil, errBSONFormat
+	}
+	return readBSONDoc(bs[:docLen])
+}
+
+// get the number value as float64. eg: the "ok" value can be double or int32
+func bsonNumber(v any) float64 {
+	switch tv := v.(type) {
+	case float64:
+		return tv
+	case int32:
+		return float64(tv)
+	case int64:
+		return float64(tv)
+	}
+	return 0
+}
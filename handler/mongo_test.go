@@ -0,0 +1,261 @@
+package handler_test
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+// a fake mongod server, only handle the OP_MSG commands
+type mongoRecv struct {
+	ln   net.Listener
+	mu   sync.Mutex
+	cmds []map[string]any
+	// reply the error on the command name
+	failOn string
+}
+
+func newMongoServer(t *testing.T) *mongoRecv {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoErr(t, err)
+
+	mr := &mongoRecv{ln: ln}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go mr.serve(conn)
+		}
+	}()
+	return mr
+}
+
+func (mr *mongoRecv) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		var head [16]byte
+		if _, err := io.ReadFull(conn, head[:]); err != nil {
+			return
+		}
+
+		bs := make([]byte, binary.LittleEndian.Uint32(head[:])-16)
+		if _, err := io.ReadFull(conn, bs); err != nil {
+			return
+		}
+
+		// skip flag bits and section kind
+		cmd, _ := decodeBSON(bs[5:])
+		mr.mu.Lock()
+		mr.cmds = append(mr.cmds, cmd)
+		mr.mu.Unlock()
+
+		// reply: {ok: 1.0} or {ok: 0.0, errmsg: "fail", code: 2}
+		var doc []byte
+		if _, ok := cmd[mr.failOn]; ok {
+			doc = append(doc, 0x01, 'o', 'k', 0)
+			doc = binary.LittleEndian.AppendUint64(doc, math.Float64bits(0))
+			doc = append(doc, 0x02, 'e', 'r', 'r', 'm', 's', 'g', 0, 5, 0, 0, 0, 'f', 'a', 'i', 'l', 0)
+			doc = append(doc, 0x10, 'c', 'o', 'd', 'e', 0, 2, 0, 0, 0)
+		} else {
+			doc = append(doc, 0x01, 'o', 'k', 0)
+			doc = binary.LittleEndian.AppendUint64(doc, math.Float64bits(1))
+		}
+
+		var msg []byte
+		msg = binary.LittleEndian.AppendUint32(msg, uint32(16+4+1+4+len(doc)+1))
+		msg = append(msg, 0, 0, 0, 0)
+		msg = append(msg, head[4:8]...) // response to
+		msg = binary.LittleEndian.AppendUint32(msg, 2013)
+		msg = append(msg, 0, 0, 0, 0, 0)
+		msg = binary.LittleEndian.AppendUint32(msg, uint32(4+len(doc)+1))
+		msg = append(append(msg, doc...), 0)
+		_, _ = conn.Write(msg)
+	}
+}
+
+func (mr *mongoRecv) Cmds() []map[string]any {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	return mr.cmds
+}
+
+func TestMongoHandler_insert(t *testing.T) {
+	mr := newMongoServer(t)
+	defer mr.ln.Close()
+
+	h := handler.NewMongoHandler(mr.ln.Addr().String(), func(c *handler.MongoConfig) {
+		c.DB = "app"
+		c.Collection = "logs"
+		c.BatchSize = 2
+		c.FlushInterval = 0
+	})
+	assert.True(t, h.IsHandling(slog.InfoLevel))
+	assert.False(t, h.IsHandling(slog.DebugLevel))
+
+	r1 := newLogRecord("info message")
+	r1.Time = time.UnixMilli(1700000000123)
+	r1.Fields = slog.M{"user_id": 23, "ratio": 0.5, "tags": []string{"a", "b"}, "message": "ignored"}
+	r2 := newLogRecord("error message")
+	r2.Level = slog.ErrorLevel
+
+	assert.NoErr(t, h.Handle(r1))
+	assert.NoErr(t, h.Handle(r2))
+	assert.Len(t, mr.Cmds(), 1)
+	assert.NoErr(t, h.Handle(r1))
+	assert.NoErr(t, h.Close())
+
+	cmds := mr.Cmds()
+	assert.Len(t, cmds, 2)
+	cmd := cmds[0]
+	assert.Eq(t, "logs", cmd["insert"])
+	assert.Eq(t, "app", cmd["$db"])
+
+	docs := cmd["documents"].([]any)
+	assert.Len(t, docs, 2)
+	doc := docs[0].(map[string]any)
+	assert.Eq(t, time.UnixMilli(1700000000123), doc["time"])
+	assert.Eq(t, "info message", doc["message"])
+	assert.Eq(t, "INFO", doc["level"])
+	assert.Eq(t, int32(slog.InfoLevel), doc["level_no"])
+	assert.Eq(t, "handler_test", doc["channel"])
+	assert.Eq(t, int32(23), doc["user_id"])
+	assert.Eq(t, 0.5, doc["ratio"])
+	assert.Eq(t, []any{"a", "b"}, doc["tags"])
+	assert.Eq(t, "linux", doc["extra"].(map[string]any)["source"])
+	assert.Eq(t, "ERROR", docs[1].(map[string]any)["level"])
+
+	// the remaining record is inserted on close
+	assert.Len(t, cmds[1]["documents"].([]any), 1)
+}
+
+func TestMongoHandler_setup(t *testing.T) {
+	mr := newMongoServer(t)
+	defer mr.ln.Close()
+
+	h := handler.NewMongoHandler(mr.ln.Addr().String(), func(c *handler.MongoConfig) {
+		c.CappedSize = 1024 * 1024
+		c.CappedMax = 1000
+		c.TTL = 24 * time.Hour
+		c.FlushInterval = 0
+	})
+
+	assert.NoErr(t, h.Handle(newLogRecord("message1")))
+	assert.NoErr(t, h.Flush())
+	assert.NoErr(t, h.Handle(newLogRecord("message2")))
+	assert.NoErr(t, h.Flush())
+
+	// create and createIndexes only on first connect
+	cmds := mr.Cmds()
+	assert.Len(t, cmds, 4)
+	assert.Eq(t, "slog", cmds[0]["create"])
+	assert.Eq(t, "logs", cmds[0]["$db"])
+	assert.Eq(t, true, cmds[0]["capped"])
+	assert.Eq(t, int32(1024*1024), cmds[0]["size"])
+	assert.Eq(t, int32(1000), cmds[0]["max"])
+
+	assert.Eq(t, "slog", cmds[1]["createIndexes"])
+	index := cmds[1]["indexes"].([]any)[0].(map[string]any)
+	assert.Eq(t, map[string]any{"time": int32(1)}, index["key"])
+	assert.Eq(t, int32(86400), index["expireAfterSeconds"])
+
+	assert.Eq(t, "slog", cmds[2]["insert"])
+	assert.Eq(t, "slog", cmds[3]["insert"])
+	assert.NoErr(t, h.Close())
+}
+
+func TestMongoHandler_error(t *testing.T) {
+	mr := newMongoServer(t)
+	mr.failOn = "insert"
+
+	h := handler.NewMongoHandler(mr.ln.Addr().String(), func(c *handler.MongoConfig) {
+		c.FlushInterval = 0
+		c.Timeout = 100 * time.Millisecond
+		c.MaxRetry = 1
+		c.RetryWait = time.Millisecond
+	})
+
+	// the server replied error, not retry
+	assert.NoErr(t, h.Handle(newLogRecord("message")))
+	err := h.Flush()
+	assert.ErrSubMsg(t, err, "fail")
+	assert.Len(t, mr.Cmds(), 1)
+
+	// server is down
+	assert.NoErr(t, mr.ln.Close())
+	assert.NoErr(t, h.Close())
+	h = handler.NewMongoHandler(mr.ln.Addr().String(), func(c *handler.MongoConfig) {
+		c.FlushInterval = 0
+		c.MaxRetry = 1
+		c.RetryWait = time.Millisecond
+	})
+	assert.NoErr(t, h.Handle(newLogRecord("message")))
+	assert.Err(t, h.Close())
+}
+
+// a minimal BSON decoder for testing. the array is decoded as []any
+func decodeBSON(bs []byte) (map[string]any, int) {
+	size := int(binary.LittleEndian.Uint32(bs))
+	mp := make(map[string]any)
+
+	pos := 4
+	for pos < size-1 {
+		typ := bs[pos]
+		end := pos + 1
+		for bs[end] != 0 {
+			end++
+		}
+		key := string(bs[pos+1 : end])
+		pos = end + 1
+
+		switch typ {
+		case 0x01:
+			mp[key] = math.Float64frombits(binary.LittleEndian.Uint64(bs[pos:]))
+			pos += 8
+		case 0x02:
+			n := int(binary.LittleEndian.Uint32(bs[pos:]))
+			mp[key] = string(bs[pos+4 : pos+4+n-1])
+			pos += 4 + n
+		case 0x03, 0x04:
+			sub, n := decodeBSON(bs[pos:])
+			pos += n
+			if typ == 0x03 {
+				mp[key] = sub
+				break
+			}
+
+			arr := make([]any, len(sub))
+			for i := range arr {
+				arr[i] = sub[strconv.Itoa(i)]
+			}
+			mp[key] = arr
+		case 0x08:
+			mp[key] = bs[pos] == 1
+			pos++
+		case 0x09:
+			mp[key] = time.UnixMilli(int64(binary.LittleEndian.Uint64(bs[pos:])))
+			pos += 8
+		case 0x0a:
+			mp[key] = nil
+		case 0x10:
+			mp[key] = int32(binary.LittleEndian.Uint32(bs[pos:]))
+			pos += 4
+		case 0x12:
+			mp[key] = int64(binary.LittleEndian.Uint64(bs[pos:]))
+			pos += 8
+		default:
+			panic("unsupported bson type in test: " + strconv.Itoa(int(typ)))
+		}
+	}
+	return mp, size
+}
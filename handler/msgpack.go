@@ -0,0 +1,299 @@
+package handler
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/gookit/slog"
+)
+
+// a minimal MessagePack encoder and decoder for the FluentHandler.
+//
+// refer: https://github.com/msgpack/msgpack/blob/master/spec.md
+
+func appendMsgpackNil(b []byte) []byte { return append(b, 0xc0) }
+
+func appendMsgpackBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 0xc3)
+	}
+	return append(b, 0xc2)
+}
+
+func appendMsgpackInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return appendMsgpackUint(b, uint64(v))
+	case v >= -32:
+		return append(b, byte(v))
+	case v >= math.MinInt8:
+		return append(b, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v))
+	}
+}
+
+func appendMsgpackUint(b []byte, v uint64) []byte {
+	switch {
+	case v <= 0x7f:
+		return append(b, byte(v))
+	case v <= math.MaxUint8:
+		return append(b, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xcf), v)
+	}
+}
+
+func appendMsgpackFloat(b []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v))
+}
+
+func appendMsgpackStr(b []byte, s string) []byte {
+	n := len(s)
+	switch {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+func appendMsgpackBin(b []byte, bs []byte) []byte {
+	n := len(bs)
+	switch {
+	case n <= math.MaxUint8:
+		b = append(b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+	}
+	return append(b, bs...)
+}
+
+func appendMsgpackArrayHeader(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+	}
+}
+
+func appendMsgpackMapHeader(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xde), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(n))
+	}
+}
+
+// append the time as fluentd EventTime ext type. see the Fluent Forward protocol.
+func appendMsgpackEventTime(b []byte, t time.Time) []byte {
+	b = append(b, 0xd7, 0x00)
+	b = binary.BigEndian.AppendUint32(b, uint32(t.Unix()))
+	return binary.BigEndian.AppendUint32(b, uint32(t.Nanosecond()))
+}
+
+// append the map, the keys are sorted.
+func appendMsgpackMap(b []byte, mp map[string]any) []byte {
+	keys := make([]string, 0, len(mp))
+	for k := range mp {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b = appendMsgpackMapHeader(b, len(keys))
+	for _, k := range keys {
+		b = appendMsgpackStr(b, k)
+		b = appendMsgpack(b, mp[k])
+	}
+	return b
+}
+
+// append any value. the unsupported value will be encoded as string by fmt.
+func appendMsgpack(b []byte, v any) []byte {
+	switch tv := v.(type) {
+	case nil:
+		return appendMsgpackNil(b)
+	case bool:
+		return appendMsgpackBool(b, tv)
+	case string:
+		return appendMsgpackStr(b, tv)
+	case []byte:
+		return appendMsgpackBin(b, tv)
+	case int:
+		return appendMsgpackInt(b, int64(tv))
+	case int8:
+		return appendMsgpackInt(b, int64(tv))
+	case int16:
+		return appendMsgpackInt(b, int64(tv))
+	case int32:
+		return appendMsgpackInt(b, int64(tv))
+	case int64:
+		return appendMsgpackInt(b, tv)
+	case uint:
+		return appendMsgpackUint(b, uint64(tv))
+	case uint8:
+		return appendMsgpackUint(b, uint64(tv))
+	case uint16:
+		return appendMsgpackUint(b, uint64(tv))
+	case uint32:
+		return appendMsgpackUint(b, uint64(tv))
+	case uint64:
+		return appendMsgpackUint(b, tv)
+	case float32:
+		return appendMsgpackFloat(b, float64(tv))
+	case float64:
+		return appendMsgpackFloat(b, tv)
+	case time.Time:
+		return appendMsgpackStr(b, tv.Format(time.RFC3339Nano))
+	case error:
+		return appendMsgpackStr(b, tv.Error())
+	case map[string]any:
+		return appendMsgpackMap(b, tv)
+	case slog.M:
+		return appendMsgpackMap(b, tv)
+	case map[string]string:
+		b = appendMsgpackMapHeader(b, len(tv))
+		for k, s := range tv {
+			b = appendMsgpackStr(appendMsgpackStr(b, k), s)
+		}
+		return b
+	case []any:
+		b = appendMsgpackArrayHeader(b, len(tv))
+		for _, item := range tv {
+			b = appendMsgpack(b, item)
+		}
+		return b
+	case []string:
+		b = appendMsgpackArrayHeader(b, len(tv))
+		for _, s := range tv {
+			b = appendMsgpackStr(b, s)
+		}
+		return b
+	case fmt.Stringer:
+		return appendMsgpackStr(b, tv.String())
+	}
+
+	// other slice and map types
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		b = appendMsgpackArrayHeader(b, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			b = appendMsgpack(b, rv.Index(i).Interface())
+		}
+		return b
+	case reflect.Map:
+		b = appendMsgpackMapHeader(b, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			b = appendMsgpackStr(b, fmt.Sprint(iter.Key().Interface()))
+			b = appendMsgpack(b, iter.Value().Interface())
+		}
+		return b
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return appendMsgpackNil(b)
+		}
+		return appendMsgpack(b, rv.Elem().Interface())
+	}
+	return appendMsgpackStr(b, fmt.Sprint(v))
+}
+
+var errMsgpackType = errors.New("slog: unsupported msgpack type")
+
+// read a map with string keys and values. eg: the fluentd ack response {"ack": "chunk-id"}
+func readMsgpackStrMap(r io.Reader) (map[string]string, error) {
+	var head [1]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, err
+	}
+
+	var n int
+	switch c := head[0]; {
+	case c&0xf0 == 0x80:
+		n = int(c & 0x0f)
+	case c == 0xde:
+		var bs [2]byte
+		if _, err := io.ReadFull(r, bs[:]); err != nil {
+			return nil, err
+		}
+		n = int(binary.BigEndian.Uint16(bs[:]))
+	default:
+		return nil, errMsgpackType
+	}
+
+	mp := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		key, err := readMsgpackStr(r)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := readMsgpackStr(r)
+		if err != nil {
+			return nil, err
+		}
+		mp[key] = val
+	}
+	return mp, nil
+}
+
+func readMsgpackStr(r io.Reader) (string, error) {
+	var head [1]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return "", err
+	}
+
+	var n int
+	switch c := head[0]; {
+	case c&0xe0 == 0xa0:
+		n = int(c & 0x1f)
+	case c == 0xd9:
+		var bs [1]byte
+		if _, err := io.ReadFull(r, bs[:]); err != nil {
+			return "", err
+		}
+		n = int(bs[0])
+	case c == 0xda:
+		var bs [2]byte
+		if _, err := io.ReadFull(r, bs[:]); err != nil {
+			return "", err
+		}
+		n = int(binary.BigEndian.Uint16(bs[:]))
+	default:
+		return "", errMsgpackType
+	}
+
+	bs := make([]byte, n)
+	if _, err := io.ReadFull(r, bs); err != nil {
+		return "", err
+	}
+	return string(bs), nil
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"github.com/gookit/goutil/errorx"
+	"github.com/gookit/slog"
+)
+
+// MultiHandler fan-out the records to multiple handlers, as one logical handler.
+//
+// Usage:
+//
+//	h := handler.NewMultiHandler(fileHandler, consoleHandler)
+//	logger := slog.NewWithHandlers(h)
+type MultiHandler struct {
+	handlers []slog.Handler
+}
+
+// NewMultiHandler create new MultiHandler
+func NewMultiHandler(hs ...slog.Handler) *MultiHandler {
+	return &MultiHandler{handlers: hs}
+}
+
+// AddHandler add a child handler. should be called before logging.
+func (h *MultiHandler) AddHandler(sub slog.Handler) *MultiHandler {
+	h.handlers = append(h.handlers, sub)
+	return h
+}
+
+// Handlers get the child handlers
+func (h *MultiHandler) Handlers() []slog.Handler {
+	return h.handlers
+}
+
+// IsHandling returns true if any child handler can handle the level
+func (h *MultiHandler) IsHandling(level slog.Level) bool {
+	for _, sub := range h.handlers {
+		if sub.IsHandling(level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle forward the record to all child handlers that can handle it.
+func (h *MultiHandler) Handle(r *slog.Record) error {
+	var es errorx.Errors
+	for _, sub := range h.handlers {
+		if !sub.IsHandling(r.Level) {
+			continue
+		}
+		if err := sub.Handle(r); err != nil {
+			es = append(es, err)
+		}
+	}
+	return es.ErrorOrNil()
+}
+
+// Flush all child handlers
+func (h *MultiHandler) Flush() error {
+	var es errorx.Errors
+	for _, sub := range h.handlers {
+		if err := sub.Flush(); err != nil {
+			es = append(es, err)
+		}
+	}
+	return es.ErrorOrNil()
+}
+
+// Close all child handlers
+func (h *MultiHandler) Close() error {
+	var es errorx.Errors
+	for _, sub := range h.handlers {
+		if err := sub.Close(); err != nil {
+			es = append(es, err)
+		}
+	}
+	return es.ErrorOrNil()
+}
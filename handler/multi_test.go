@@ -0,0 +1,60 @@
+package handler_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gookit/goutil/errorx"
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+func TestMultiHandler(t *testing.T) {
+	textBuf := new(bytes.Buffer)
+	jsonBuf := new(bytes.Buffer)
+
+	h1 := handler.NewIOWriter(textBuf, slog.AllLevels)
+	h2 := handler.IOWriterWithMaxLevel(jsonBuf, slog.ErrorLevel)
+	h2.SetFormatter(slog.NewJSONFormatter())
+
+	h := handler.NewMultiHandler(h1).AddHandler(h2)
+	assert.Len(t, h.Handlers(), 2)
+	assert.True(t, h.IsHandling(slog.DebugLevel))
+
+	l := slog.NewWithHandlers(h)
+	l.Info("info message")
+	l.Error("error message")
+
+	assert.StrContains(t, textBuf.String(), "info message")
+	assert.StrContains(t, textBuf.String(), "error message")
+	assert.NotContains(t, jsonBuf.String(), "info message")
+	assert.StrContains(t, jsonBuf.String(), `"message":"error message"`)
+
+	assert.NoErr(t, h.Flush())
+	assert.NoErr(t, h.Close())
+
+	h = handler.NewMultiHandler(h2)
+	assert.False(t, h.IsHandling(slog.InfoLevel))
+}
+
+func TestMultiHandler_errors(t *testing.T) {
+	th := newTestHandler()
+	th.errOnHandle = true
+	th.errOnFlush = true
+	th.errOnClose = true
+
+	w := new(syncMsgs)
+	h := handler.NewMultiHandler(th, w, th)
+
+	err := h.Handle(newLogRecord("error message"))
+	assert.Err(t, err)
+	es, ok := err.(errorx.Errors)
+	assert.True(t, ok)
+	assert.Len(t, es, 2)
+	// other handlers still be called
+	assert.Eq(t, []string{"error message"}, w.Messages())
+
+	assert.ErrMsg(t, h.Flush(), "flush error\nflush error\n")
+	assert.Err(t, h.Close())
+}
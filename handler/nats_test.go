@@ -0,0 +1,210 @@
+package handler_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+type natsPub struct {
+	subject string
+	data    string
+}
+
+// a fake nats server, handle the CONNECT, PING, SUB and PUB ops.
+// will reply the JetStream ack on PUB with reply subject.
+type natsRecv struct {
+	ln net.Listener
+	// reply "no responders" on JetStream publish
+	noStream bool
+	// reply the -ERR on publish
+	pubErr string
+
+	mu      sync.Mutex
+	connect map[string]any
+	pubs    []natsPub
+}
+
+func newNatsServer(t *testing.T) *natsRecv {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoErr(t, err)
+
+	nr := &natsRecv{ln: ln}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go nr.serve(conn)
+		}
+	}()
+	return nr
+}
+
+func (nr *natsRecv) serve(conn net.Conn) {
+	defer conn.Close()
+	_, _ = conn.Write([]byte(`INFO {"server_id":"test","max_payload":1048576}` + "\r\n"))
+
+	sid := ""
+	rd := bufio.NewReader(conn)
+	for {
+		line, err := rd.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		args := strings.Fields(line)
+		switch args[0] {
+		case "CONNECT":
+			nr.mu.Lock()
+			_ = json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(line), "CONNECT ")), &nr.connect)
+			nr.mu.Unlock()
+		case "PING":
+			_, _ = conn.Write([]byte("PONG\r\n"))
+		case "SUB":
+			sid = args[2]
+		case "PUB":
+			size, _ := strconv.Atoi(args[len(args)-1])
+			data := make([]byte, size+2)
+			if _, err = io.ReadFull(rd, data); err != nil {
+				return
+			}
+
+			if nr.pubErr != "" {
+				_, _ = conn.Write([]byte("-ERR '" + nr.pubErr + "'\r\n"))
+				continue
+			}
+
+			nr.mu.Lock()
+			nr.pubs = append(nr.pubs, natsPub{subject: args[1], data: string(data[:size])})
+			seq, noStream := len(nr.pubs), nr.noStream
+			nr.mu.Unlock()
+
+			// JetStream publish, reply the ack
+			if len(args) == 4 {
+				if noStream {
+					hdr := "NATS/1.0 503\r\n\r\n"
+					_, _ = fmt.Fprintf(conn, "HMSG %s %s %d %d\r\n%s\r\n", args[2], sid, len(hdr), len(hdr), hdr)
+				} else {
+					ack := fmt.Sprintf(`{"stream":"LOGS","seq":%d}`, seq)
+					_, _ = fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", args[2], sid, len(ack), ack)
+				}
+			}
+		}
+	}
+}
+
+func (nr *natsRecv) Pubs() []natsPub {
+	nr.mu.Lock()
+	defer nr.mu.Unlock()
+	return nr.pubs
+}
+
+func TestNatsHandler_publish(t *testing.T) {
+	nr := newNatsServer(t)
+	defer nr.ln.Close()
+
+	h := handler.NewNatsHandler("nats://user:pass@"+nr.ln.Addr().String(), "logs.{channel}.{level}")
+	h.SetFormatter(slog.NewTextFormatter("{{message}}\n"))
+	assert.True(t, h.IsHandling(slog.InfoLevel))
+	assert.False(t, h.IsHandling(slog.DebugLevel))
+
+	assert.NoErr(t, h.Handle(newLogRecord("message1")))
+	r := newLogRecord("message2")
+	r.Channel = "my app"
+	r.Level = slog.ErrorLevel
+	assert.NoErr(t, h.Handle(r))
+	assert.NoErr(t, h.Flush())
+
+	pubs := nr.Pubs()
+	assert.Len(t, pubs, 2)
+	assert.Eq(t, natsPub{subject: "logs.handler_test.info", data: "message1"}, pubs[0])
+	assert.Eq(t, natsPub{subject: "logs.my_app.error", data: "message2"}, pubs[1])
+
+	nr.mu.Lock()
+	assert.Eq(t, "user", nr.connect["user"])
+	assert.Eq(t, "pass", nr.connect["pass"])
+	assert.Eq(t, "slog", nr.connect["name"])
+	nr.mu.Unlock()
+
+	// the queued records are published on close
+	assert.NoErr(t, h.Handle(newLogRecord("message3")))
+	assert.NoErr(t, h.Close())
+	assert.Len(t, nr.Pubs(), 3)
+	assert.ErrIs(t, h.Handle(newLogRecord("closed")), handler.ErrNatsClosed)
+}
+
+func TestNatsHandler_jetStream(t *testing.T) {
+	nr := newNatsServer(t)
+	defer nr.ln.Close()
+
+	var dropped int
+	h := handler.NewNatsHandler(nr.ln.Addr().String(), "", func(c *handler.NatsConfig) {
+		c.JetStream = true
+		c.Token = "s3cret"
+		c.MaxRetry = 0
+		c.OnError = func(err error, n int) {
+			dropped += n
+		}
+	})
+	h.SetFormatter(slog.NewTextFormatter("{{message}}"))
+
+	for i := 0; i < 3; i++ {
+		assert.NoErr(t, h.Handle(newLogRecord("message"+strconv.Itoa(i))))
+	}
+	assert.NoErr(t, h.Flush())
+	assert.Len(t, nr.Pubs(), 3)
+	assert.Eq(t, "logs", nr.Pubs()[0].subject)
+
+	nr.mu.Lock()
+	assert.Eq(t, "s3cret", nr.connect["auth_token"])
+	assert.Eq(t, true, nr.connect["headers"])
+	nr.noStream = true
+	nr.mu.Unlock()
+
+	assert.NoErr(t, h.Handle(newLogRecord("no stream")))
+	assert.ErrSubMsg(t, h.Flush(), "no responders")
+	assert.Eq(t, 1, dropped)
+	assert.NoErr(t, h.Close())
+}
+
+func TestNatsHandler_error(t *testing.T) {
+	nr := newNatsServer(t)
+	nr.pubErr = "Permissions Violation for Publish to logs"
+
+	var dropped int
+	var lastErr error
+	h := handler.NewNatsHandler(nr.ln.Addr().String(), "logs", func(c *handler.NatsConfig) {
+		c.MaxRetry = 1
+		c.RetryWait = time.Millisecond
+		c.OnError = func(err error, n int) {
+			lastErr = err
+			dropped += n
+		}
+	})
+
+	assert.NoErr(t, h.Handle(newLogRecord("message1")))
+	err := h.Flush()
+	assert.ErrSubMsg(t, err, "Permissions Violation")
+	assert.Eq(t, err, lastErr)
+	assert.Eq(t, 1, dropped)
+
+	// server is down
+	assert.NoErr(t, nr.ln.Close())
+	assert.NoErr(t, h.Handle(newLogRecord("message2")))
+	err = h.Close()
+	assert.Err(t, err)
+	assert.Eq(t, 2, dropped)
+}
@@ -0,0 +1,315 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gookit/slog"
+)
+
+// the OTLP export protocols
+const (
+	// OTLPProtocolGRPC export by gRPC, the default port is 4317
+	OTLPProtocolGRPC = "grpc"
+	// OTLPProtocolHTTP export by HTTP with protobuf body, the default port is 4318
+	OTLPProtocolHTTP = "http/protobuf"
+)
+
+// the instrumentation scope name of the exported logs
+const otlpScopeName = "github.com/gookit/slog"
+
+const otlpGRPCPath = "/opentelemetry.proto.collector.logs.v1.LogsService/Export"
+
+// ErrOTLPInsecureGRPC the gRPC protocol requires HTTP/2, it is only supported over TLS.
+var ErrOTLPInsecureGRPC = errors.New("slog: the otlp grpc protocol requires TLS, please use the http/protobuf protocol for insecure endpoint")
+
+// OTLPConfig struct for the OTLPHandler
+type OTLPConfig struct {
+	// Endpoint the collector address. default is "localhost:4317"
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+	// Protocol the export protocol. allow: OTLPProtocolGRPC, OTLPProtocolHTTP. default is OTLPProtocolGRPC
+	Protocol string `json:"protocol" yaml:"protocol"`
+	// Insecure use plaintext connection. only for the OTLPProtocolHTTP.
+	Insecure bool `json:"insecure" yaml:"insecure"`
+	// Headers custom request headers. eg: {"Authorization": "Bearer TOKEN"}
+	Headers map[string]string `json:"headers" yaml:"headers"`
+	// TLSConfig custom the TLS config. eg: client certificate, custom CA
+	TLSConfig *tls.Config `json:"-" yaml:"-"`
+	// ServiceName the resource attribute "service.name". default is "unknown_service"
+	ServiceName string `json:"service_name" yaml:"service_name"`
+	// Resource the other resource attributes. eg: {"deployment.environment": "prod"}
+	Resource map[string]any `json:"resource" yaml:"resource"`
+	// TraceExtract extract the trace ID and span ID from the Record.Ctx. eg: use the OpenTelemetry SpanContext.
+	//
+	// default will use the "trace_id" and "span_id" in Record.Fields. see slog.AddTraceIDs()
+	TraceExtract slog.TraceExtractFunc `json:"-" yaml:"-"`
+	// BatchSize export the batch when pending records reach the number. default is 100
+	BatchSize int `json:"batch_size" yaml:"batch_size"`
+	// FlushInterval export the pending batch on each interval. default is 3s, set 0 to disable
+	FlushInterval time.Duration `json:"flush_interval" yaml:"flush_interval"`
+	// Timeout for each export request. default is 10s
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// MaxRetry max retry times on the export failed by retryable error. default is 3
+	MaxRetry int `json:"max_retry" yaml:"max_retry"`
+	// RetryWait the first wait time before retry, will double it on each retry. default is 500ms
+	RetryWait time.Duration `json:"retry_wait" yaml:"retry_wait"`
+}
+
+// OTLPHandler export log records to the OpenTelemetry collector by OTLP, support batching.
+//
+// Each record is exported as a LogRecord: the level is mapped to severity number, the message
+// is the body, and the channel, Record.Fields are the attributes. the trace ids will be set by
+// the OTLPConfig.TraceExtract or the "trace_id", "span_id" fields.
+//
+// NOTICE: the formatter is not used, the record is exported as structured data.
+//
+// refer: https://opentelemetry.io/docs/specs/otlp/
+type OTLPHandler struct {
+	slog.LevelHandling
+	cfg    *OTLPConfig
+	client *http.Client
+	// the export url and resource attributes
+	url      string
+	resource map[string]any
+
+	mu sync.Mutex
+	// pending encoded LogRecord messages
+	batch  [][]byte
+	closed bool
+	// for stop the flush ticker
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewOTLPHandler create new OTLPHandler
+//
+// Usage:
+//
+//	h := handler.NewOTLPHandler("otel-collector:4317", func(c *handler.OTLPConfig) {
+//		c.ServiceName = "order-api"
+//		c.Headers = map[string]string{"Authorization": "Bearer TOKEN"}
+//	})
+func NewOTLPHandler(endpoint string, fns ...func(c *OTLPConfig)) *OTLPHandler {
+	cfg := &OTLPConfig{
+		Endpoint:      endpoint,
+		Protocol:      OTLPProtocolGRPC,
+		ServiceName:   "unknown_service",
+		BatchSize:     100,
+		FlushInterval: 3 * time.Second,
+		Timeout:       10 * time.Second,
+		MaxRetry:      3,
+		RetryWait:     500 * time.Millisecond,
+	}
+	for _, fn := range fns {
+		fn(cfg)
+	}
+
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "localhost:4317"
+	}
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = 1
+	}
+
+	h := &OTLPHandler{cfg: cfg}
+	// init default log level
+	h.SetMaxLevel(slog.InfoLevel)
+	h.init()
+
+	if cfg.FlushInterval > 0 {
+		h.stopCh = make(chan struct{})
+		h.wg.Add(1)
+		go h.flushDaemon()
+	}
+	return h
+}
+
+func (h *OTLPHandler) init() {
+	scheme := "https://"
+	if h.cfg.Insecure {
+		scheme = "http://"
+	}
+
+	h.url = scheme + h.cfg.Endpoint + "/v1/logs"
+	if h.cfg.Protocol == OTLPProtocolGRPC {
+		h.url = scheme + h.cfg.Endpoint + otlpGRPCPath
+	}
+
+	tr := &http.Transport{
+		Proxy:             http.ProxyFromEnvironment,
+		ForceAttemptHTTP2: true,
+	}
+	if h.cfg.TLSConfig != nil {
+		tr.TLSClientConfig = h.cfg.TLSConfig.Clone()
+	}
+	h.client = &http.Client{Transport: tr, Timeout: h.cfg.Timeout}
+
+	h.resource = make(map[string]any, len(h.cfg.Resource)+1)
+	for k, v := range h.cfg.Resource {
+		h.resource[k] = v
+	}
+	h.resource["service.name"] = h.cfg.ServiceName
+}
+
+// Config get the handler config
+func (h *OTLPHandler) Config() *OTLPConfig {
+	return h.cfg
+}
+
+// Handle a log record. will export the batch on reach the BatchSize
+func (h *OTLPHandler) Handle(r *slog.Record) error {
+	rec := buildOTLPLogRecord(r, h.cfg.TraceExtract)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.batch = append(h.batch, rec)
+	if len(h.batch) >= h.cfg.BatchSize {
+		return h.exportBatch()
+	}
+	return nil
+}
+
+// Flush force export the pending batch
+func (h *OTLPHandler) Flush() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.exportBatch()
+}
+
+// Close the handler. will flush the pending batch, then stop the flush ticker.
+func (h *OTLPHandler) Close() error {
+	err := h.Flush()
+
+	h.mu.Lock()
+	if !h.closed && h.stopCh != nil {
+		close(h.stopCh)
+	}
+	h.closed = true
+	h.mu.Unlock()
+
+	h.wg.Wait()
+	h.client.CloseIdleConnections()
+	return err
+}
+
+func (h *OTLPHandler) flushDaemon() {
+	defer h.wg.Done()
+	tk := time.NewTicker(h.cfg.FlushInterval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			printErrln("slog: otlp handler flush error:", h.Flush())
+		case <-h.stopCh:
+			return
+		}
+	}
+}
+
+// export pending batch. should be in lock
+func (h *OTLPHandler) exportBatch() error {
+	if len(h.batch) == 0 {
+		return nil
+	}
+
+	body := buildOTLPExportRequest(h.resource, h.batch)
+	// reset batch, the failed batch will be dropped
+	h.batch = h.batch[:0]
+
+	if h.cfg.Protocol == OTLPProtocolGRPC {
+		if h.cfg.Insecure {
+			return ErrOTLPInsecureGRPC
+		}
+
+		// gRPC message: compressed flag, length, message
+		msg := make([]byte, 5, 5+len(body))
+		binary.BigEndian.PutUint32(msg[1:], uint32(len(body)))
+		body = append(msg, body...)
+	}
+
+	var err error
+	wait := h.cfg.RetryWait
+	for i := 0; i <= h.cfg.MaxRetry; i++ {
+		if i > 0 {
+			time.Sleep(wait)
+			wait *= 2
+		}
+
+		var retryable bool
+		if retryable, err = h.export(body); err == nil || !retryable {
+			return err
+		}
+	}
+	return err
+}
+
+// send the export request. returns the error is retryable.
+func (h *OTLPHandler) export(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	if h.cfg.Protocol == OTLPProtocolGRPC {
+		req.Header.Set("Content-Type", "application/grpc")
+		req.Header.Set("TE", "trailers")
+	} else {
+		req.Header.Set("Content-Type", "application/x-protobuf")
+	}
+	for k, v := range h.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+
+	// must read the body before get the trailers
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		err = fmt.Errorf("slog: otlp export got response status %s", resp.Status)
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true, err
+		}
+		return false, err
+	}
+
+	if h.cfg.Protocol == OTLPProtocolGRPC {
+		return checkGRPCStatus(resp)
+	}
+	return false, nil
+}
+
+// check the grpc-status in the trailers, or the headers for the trailers-only response.
+func checkGRPCStatus(resp *http.Response) (bool, error) {
+	status, msg := resp.Trailer.Get("grpc-status"), resp.Trailer.Get("grpc-message")
+	if status == "" {
+		status, msg = resp.Header.Get("grpc-status"), resp.Header.Get("grpc-message")
+	}
+	if status == "" || status == "0" {
+		return false, nil
+	}
+
+	code, _ := strconv.Atoi(status)
+	err := fmt.Errorf("slog: otlp export got grpc status %d: %s", code, msg)
+	switch code {
+	// CANCELLED, DEADLINE_EXCEEDED, RESOURCE_EXHAUSTED, ABORTED, OUT_OF_RANGE, UNAVAILABLE, DATA_LOSS
+	case 1, 4, 8, 10, 11, 14, 15:
+		return true, err
+	}
+	return false, err
+}
@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/gookit/slog"
+)
+
+// a minimal protobuf encoder for the OTLP logs ExportLogsServiceRequest.
+//
+// refer: https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/logs/v1/logs.proto
+
+// protobuf wire types
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+)
+
+// OTLP severity numbers
+const (
+	otlpSeverityTrace = 1
+	otlpSeverityDebug = 5
+	otlpSeverityInfo  = 9
+	otlpSeverityInfo2 = 10
+	otlpSeverityWarn  = 13
+	otlpSeverityError = 17
+	otlpSeverityFatal = 21
+	// the panic level is more severe than fatal in slog
+	otlpSeverityFatal4 = 24
+)
+
+// otlpSeverity map the slog level to OTLP severity number
+func otlpSeverity(level slog.Level) int {
+	switch {
+	case level <= slog.PanicLevel:
+		return otlpSeverityFatal4
+	case level <= slog.FatalLevel:
+		return otlpSeverityFatal
+	case level <= slog.ErrorLevel:
+		return otlpSeverityError
+	case level <= slog.WarnLevel:
+		return otlpSeverityWarn
+	case level <= slog.NoticeLevel:
+		return otlpSeverityInfo2
+	case level <= slog.InfoLevel:
+		return otlpSeverityInfo
+	case level <= slog.DebugLevel:
+		return otlpSeverityDebug
+	default:
+		return otlpSeverityTrace
+	}
+}
+
+func appendProtoTag(b []byte, field, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wireType))
+}
+
+func appendProtoVarint(b []byte, field int, v uint64) []byte {
+	b = appendProtoTag(b, field, protoVarint)
+	return binary.AppendUvarint(b, v)
+}
+
+func appendProtoFixed64(b []byte, field int, v uint64) []byte {
+	b = appendProtoTag(b, field, protoFixed64)
+	return binary.LittleEndian.AppendUint64(b, v)
+}
+
+func appendProtoBytes(b []byte, field int, bs []byte) []byte {
+	b = appendProtoTag(b, field, protoBytes)
+	b = binary.AppendUvarint(b, uint64(len(bs)))
+	return append(b, bs...)
+}
+
+func appendProtoString(b []byte, field int, s string) []byte {
+	b = appendProtoTag(b, field, protoBytes)
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// append the KeyValue message as field
+func appendOTLPKeyValue(b []byte, field int, key string, val any) []byte {
+	kv := appendProtoString(nil, 1, key)
+	kv = appendProtoBytes(kv, 2, appendOTLPAnyValue(nil, val))
+	return appendProtoBytes(b, field, kv)
+}
+
+// append the sorted KeyValue list as repeated field
+func appendOTLPKeyValues(b []byte, field int, mp map[string]any) []byte {
+	keys := make([]string, 0, len(mp))
+	for k := range mp {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		b = appendOTLPKeyValue(b, field, k, mp[k])
+	}
+	return b
+}
+
+// append the AnyValue message fields
+func appendOTLPAnyValue(b []byte, val any) []byte {
+	switch v := val.(type) {
+	case nil:
+		return b
+	case string:
+		return appendProtoString(b, 1, v)
+	case bool:
+		if v {
+			return appendProtoVarint(b, 2, 1)
+		}
+		return appendProtoVarint(b, 2, 0)
+	case int:
+		return appendProtoVarint(b, 3, uint64(v))
+	case int8:
+		return appendProtoVarint(b, 3, uint64(v))
+	case int16:
+		return appendProtoVarint(b, 3, uint64(v))
+	case int32:
+		return appendProtoVarint(b, 3, uint64(v))
+	case int64:
+		return appendProtoVarint(b, 3, uint64(v))
+	case uint:
+		return appendProtoVarint(b, 3, uint64(v))
+	case uint8:
+		return appendProtoVarint(b, 3, uint64(v))
+	case uint16:
+		return appendProtoVarint(b, 3, uint64(v))
+	case uint32:
+		return appendProtoVarint(b, 3, uint64(v))
+	case uint64:
+		return appendProtoVarint(b, 3, v)
+	case float32:
+		return appendProtoFixed64(b, 4, math.Float64bits(float64(v)))
+	case float64:
+		return appendProtoFixed64(b, 4, math.Float64bits(v))
+	case []byte:
+		return appendProtoBytes(b, 7, v)
+	case time.Time:
+		return appendProtoString(b, 1, v.Format(time.RFC3339Nano))
+	case time.Duration:
+		return appendProtoString(b, 1, v.String())
+	case error:
+		return appendProtoString(b, 1, v.Error())
+	case fmt.Stringer:
+		return appendProtoString(b, 1, v.String())
+	case slog.M:
+		return appendProtoBytes(b, 6, appendOTLPKeyValues(nil, 1, v))
+	case map[string]any:
+		return appendProtoBytes(b, 6, appendOTLPKeyValues(nil, 1, v))
+	case map[string]string:
+		var kvs []byte
+		for _, k := range sortedKeys(v) {
+			kvs = appendOTLPKeyValue(kvs, 1, k, v[k])
+		}
+		return appendProtoBytes(b, 6, kvs)
+	}
+
+	// array value
+	rv := reflect.ValueOf(val)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		var arr []byte
+		for i := 0; i < rv.Len(); i++ {
+			arr = appendProtoBytes(arr, 1, appendOTLPAnyValue(nil, rv.Index(i).Interface()))
+		}
+		return appendProtoBytes(b, 5, arr)
+	}
+	return appendProtoString(b, 1, fmt.Sprint(val))
+}
+
+func sortedKeys(mp map[string]string) []string {
+	keys := make([]string, 0, len(mp))
+	for k := range mp {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// decode the hex trace id or span id, returns nil on invalid.
+func otlpTraceID(s string, size int) []byte {
+	if len(s) != size*2 {
+		return nil
+	}
+
+	bs, err := hex.DecodeString(s)
+	if err != nil {
+		return nil
+	}
+
+	// the all zero id is invalid
+	for _, c := range bs {
+		if c != 0 {
+			return bs
+		}
+	}
+	return nil
+}
+
+// build the LogRecord message.
+func buildOTLPLogRecord(r *slog.Record, extract slog.TraceExtractFunc) []byte {
+	// the trace ids from Record.Ctx, or the fields added by the slog.AddTraceIDs() processor.
+	var traceID, spanID string
+	if extract != nil && r.Ctx != nil {
+		traceID, spanID, _ = extract(r.Ctx)
+	}
+	if traceID == "" {
+		traceID, _ = r.Fields["trace_id"].(string)
+		spanID, _ = r.Fields["span_id"].(string)
+	}
+	tid, sid := otlpTraceID(traceID, 16), otlpTraceID(spanID, 8)
+
+	b := make([]byte, 0, 256)
+	b = appendProtoFixed64(b, 1, uint64(r.Time.UnixNano()))
+	b = appendProtoVarint(b, 2, uint64(otlpSeverity(r.Level)))
+	b = appendProtoString(b, 3, r.LevelName())
+	b = appendProtoBytes(b, 5, appendProtoString(nil, 1, r.Message))
+
+	if r.Channel != "" {
+		b = appendOTLPKeyValue(b, 6, "channel", r.Channel)
+	}
+
+	attrs := r.Fields
+	if tid != nil && len(attrs) > 0 {
+		// the trace ids are not added as attributes
+		attrs = make(map[string]any, len(r.Fields))
+		for k, v := range r.Fields {
+			if k != "trace_id" && k != "span_id" {
+				attrs[k] = v
+			}
+		}
+	}
+	b = appendOTLPKeyValues(b, 6, attrs)
+
+	if tid != nil {
+		b = appendProtoBytes(b, 9, tid)
+		if sid != nil {
+			b = appendProtoBytes(b, 10, sid)
+		}
+	}
+	return appendProtoFixed64(b, 11, uint64(time.Now().UnixNano()))
+}
+
+// build the ExportLogsServiceRequest message, with one ResourceLogs and one ScopeLogs.
+func buildOTLPExportRequest(resource map[string]any, records [][]byte) []byte {
+	scope := appendProtoString(nil, 1, otlpScopeName)
+
+	scopeLogs := appendProtoBytes(nil, 1, scope)
+	for _, rec := range records {
+		scopeLogs = appendProtoBytes(scopeLogs, 2, rec)
+	}
+
+	resLogs := appendProtoBytes(nil, 1, appendOTLPKeyValues(nil, 1, resource))
+	resLogs = appendProtoBytes(resLogs, 2, scopeLogs)
+	return appendProtoBytes(nil, 1, resLogs)
+}
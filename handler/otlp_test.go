@@ -0,0 +1,277 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+// protoMsg a decoded protobuf message, the value is uint64 or []byte
+type protoMsg map[int][]any
+
+func decodeProto(bs []byte) protoMsg {
+	msg := make(protoMsg)
+	for len(bs) > 0 {
+		tag, n := binary.Uvarint(bs)
+		bs = bs[n:]
+
+		field := int(tag >> 3)
+		switch tag & 7 {
+		case 0:
+			v, n := binary.Uvarint(bs)
+			bs = bs[n:]
+			msg[field] = append(msg[field], v)
+		case 1:
+			msg[field] = append(msg[field], binary.LittleEndian.Uint64(bs))
+			bs = bs[8:]
+		case 2:
+			size, n := binary.Uvarint(bs)
+			msg[field] = append(msg[field], bs[n:n+int(size)])
+			bs = bs[n+int(size):]
+		default:
+			panic("unsupported wire type")
+		}
+	}
+	return msg
+}
+
+func (m protoMsg) msg(field int) protoMsg { return decodeProto(m[field][0].([]byte)) }
+func (m protoMsg) str(field int) string   { return string(m[field][0].([]byte)) }
+func (m protoMsg) num(field int) uint64   { return m[field][0].(uint64) }
+
+// decode the KeyValue list to map, only support the string, bool, int, double values
+func (m protoMsg) attrs(field int) map[string]any {
+	mp := make(map[string]any)
+	for _, v := range m[field] {
+		kv := decodeProto(v.([]byte))
+		av := kv.msg(2)
+
+		var val any
+		switch {
+		case av[1] != nil:
+			val = av.str(1)
+		case av[2] != nil:
+			val = av.num(2) == 1
+		case av[3] != nil:
+			val = int64(av.num(3))
+		case av[4] != nil:
+			val = math.Float64frombits(av.num(4))
+		case av[5] != nil:
+			val = "array"
+		}
+		mp[kv.str(1)] = val
+	}
+	return mp
+}
+
+type otlpRecv struct {
+	mu sync.Mutex
+	// the decoded LogRecord list
+	records  []protoMsg
+	resource map[string]any
+	header   http.Header
+	path     string
+	proto    int
+	// reply the grpc status
+	grpcStatus string
+	hits       int
+}
+
+func (or *otlpRecv) Records() []protoMsg {
+	or.mu.Lock()
+	defer or.mu.Unlock()
+	return append([]protoMsg(nil), or.records...)
+}
+
+func (or *otlpRecv) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	or.mu.Lock()
+	defer or.mu.Unlock()
+
+	or.hits++
+	or.header = r.Header.Clone()
+	or.path = r.URL.Path
+	or.proto = r.ProtoMajor
+
+	bs, _ := io.ReadAll(r.Body)
+	if r.Header.Get("Content-Type") == "application/grpc" {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "grpc-status, grpc-message")
+		w.WriteHeader(http.StatusOK)
+		if or.grpcStatus != "" {
+			w.Header().Set("grpc-status", or.grpcStatus)
+			w.Header().Set("grpc-message", "server is busy")
+			return
+		}
+
+		w.Header().Set("grpc-status", "0")
+		// skip the compressed flag and length
+		bs = bs[5:]
+	}
+
+	req := decodeProto(bs)
+	resLogs := req.msg(1)
+	or.resource = resLogs.msg(1).attrs(1)
+
+	scopeLogs := resLogs.msg(2)
+	if scopeLogs.msg(1).str(1) != "github.com/gookit/slog" {
+		panic("invalid scope name")
+	}
+	for _, rec := range scopeLogs[2] {
+		or.records = append(or.records, decodeProto(rec.([]byte)))
+	}
+}
+
+func newOTLPTLSServer(or *otlpRecv) *httptest.Server {
+	srv := httptest.NewUnstartedServer(or)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	return srv
+}
+
+func TestOTLPHandler_grpc(t *testing.T) {
+	or := &otlpRecv{}
+	srv := newOTLPTLSServer(or)
+	defer srv.Close()
+
+	h := handler.NewOTLPHandler(strings.TrimPrefix(srv.URL, "https://"), func(c *handler.OTLPConfig) {
+		c.BatchSize = 2
+		c.FlushInterval = 0
+		c.ServiceName = "order-api"
+		c.Resource = map[string]any{"env": "test"}
+		c.Headers = map[string]string{"X-Token": "abc"}
+		c.TLSConfig = srv.Client().Transport.(*http.Transport).TLSClientConfig
+	})
+	assert.True(t, h.IsHandling(slog.InfoLevel))
+	assert.False(t, h.IsHandling(slog.DebugLevel))
+
+	r := newLogRecord("message 1")
+	r.Level = slog.WarnLevel
+	r.Time = time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	r.Fields = slog.M{"user": "inhere", "age": 23, "ok": true, "score": 9.5, "tags": []string{"a"}}
+	assert.NoErr(t, h.Handle(r))
+	assert.Empty(t, or.Records())
+
+	assert.NoErr(t, h.Handle(newLogRecord("message 2")))
+	records := or.Records()
+	assert.Len(t, records, 2)
+	assert.Eq(t, "/opentelemetry.proto.collector.logs.v1.LogsService/Export", or.path)
+	assert.Eq(t, 2, or.proto)
+	assert.Eq(t, "abc", or.header.Get("X-Token"))
+	assert.Eq(t, map[string]any{"service.name": "order-api", "env": "test"}, or.resource)
+
+	rec := records[0]
+	assert.Eq(t, uint64(r.Time.UnixNano()), rec.num(1))
+	assert.Eq(t, uint64(13), rec.num(2))
+	assert.Eq(t, "message 1", rec.msg(5).str(1))
+	assert.Eq(t, map[string]any{
+		"channel": "handler_test",
+		"user":    "inhere",
+		"age":     int64(23),
+		"ok":      true,
+		"score":   9.5,
+		"tags":    "array",
+	}, rec.attrs(6))
+	assert.Nil(t, rec[9])
+
+	assert.Eq(t, uint64(9), records[1].num(2))
+	assert.NoErr(t, h.Close())
+}
+
+func TestOTLPHandler_traceContext(t *testing.T) {
+	or := &otlpRecv{}
+	srv := newOTLPTLSServer(or)
+	defer srv.Close()
+
+	type traceKey struct{}
+	traceID, spanID := "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	h := handler.NewOTLPHandler(strings.TrimPrefix(srv.URL, "https://"), func(c *handler.OTLPConfig) {
+		c.FlushInterval = 0
+		c.TLSConfig = srv.Client().Transport.(*http.Transport).TLSClientConfig
+		c.TraceExtract = func(ctx context.Context) (string, string, bool) {
+			ids, ok := ctx.Value(traceKey{}).([2]string)
+			return ids[0], ids[1], ok
+		}
+	})
+
+	// from the context
+	r := newLogRecord("with ctx")
+	r.Ctx = context.WithValue(context.Background(), traceKey{}, [2]string{traceID, spanID})
+	assert.NoErr(t, h.Handle(r))
+
+	// from the fields
+	r = newLogRecord("with fields")
+	r.Fields = slog.M{"trace_id": traceID, "span_id": spanID, "user": "inhere"}
+	assert.NoErr(t, h.Handle(r))
+	assert.NoErr(t, h.Close())
+
+	records := or.Records()
+	assert.Len(t, records, 2)
+	for _, rec := range records {
+		assert.Eq(t, traceID, hex.EncodeToString(rec[9][0].([]byte)))
+		assert.Eq(t, spanID, hex.EncodeToString(rec[10][0].([]byte)))
+	}
+	assert.Eq(t, map[string]any{"channel": "handler_test", "user": "inhere"}, records[1].attrs(6))
+}
+
+func TestOTLPHandler_httpProtobuf(t *testing.T) {
+	or := &otlpRecv{}
+	srv := httptest.NewServer(or)
+	defer srv.Close()
+
+	h := handler.NewOTLPHandler(strings.TrimPrefix(srv.URL, "http://"), func(c *handler.OTLPConfig) {
+		c.Protocol = handler.OTLPProtocolHTTP
+		c.Insecure = true
+		c.FlushInterval = 0
+	})
+
+	assert.NoErr(t, h.Handle(newLogRecord("message")))
+	assert.NoErr(t, h.Flush())
+	assert.Eq(t, "/v1/logs", or.path)
+	assert.Eq(t, "application/x-protobuf", or.header.Get("Content-Type"))
+	assert.Len(t, or.Records(), 1)
+	assert.Eq(t, "message", or.Records()[0].msg(5).str(1))
+	assert.NoErr(t, h.Close())
+}
+
+func TestOTLPHandler_error(t *testing.T) {
+	or := &otlpRecv{grpcStatus: "14"} // UNAVAILABLE
+	srv := newOTLPTLSServer(or)
+	defer srv.Close()
+
+	h := handler.NewOTLPHandler(strings.TrimPrefix(srv.URL, "https://"), func(c *handler.OTLPConfig) {
+		c.FlushInterval = 0
+		c.MaxRetry = 1
+		c.RetryWait = time.Millisecond
+		c.TLSConfig = srv.Client().Transport.(*http.Transport).TLSClientConfig
+	})
+
+	assert.NoErr(t, h.Handle(newLogRecord("message")))
+	err := h.Flush()
+	assert.ErrSubMsg(t, err, "grpc status 14: server is busy")
+	// retried once
+	or.mu.Lock()
+	assert.Eq(t, 2, or.hits)
+	or.mu.Unlock()
+
+	// the grpc protocol requires TLS
+	h2 := handler.NewOTLPHandler("127.0.0.1:4317", func(c *handler.OTLPConfig) {
+		c.Insecure = true
+		c.FlushInterval = 0
+	})
+	assert.NoErr(t, h2.Handle(newLogRecord("message")))
+	assert.ErrIs(t, h2.Flush(), handler.ErrOTLPInsecureGRPC)
+	assert.NoErr(t, h2.Close())
+	assert.NoErr(t, h.Close())
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/gookit/slog"
+)
+
+// RetryConfig for the RetryHandler
+type RetryConfig struct {
+	// MaxRetry max retry times after the first failed Handle. default is 3
+	MaxRetry int `json:"max_retry" yaml:"max_retry"`
+	// Wait the first wait time before retry, will double it on each retry. default is 100ms
+	Wait time.Duration `json:"wait" yaml:"wait"`
+	// MaxWait the max wait time between retries. default is 5s
+	MaxWait time.Duration `json:"max_wait" yaml:"max_wait"`
+	// Jitter add random wait time to each wait, range is [0, wait*Jitter). default is 0.2
+	Jitter float64 `json:"jitter" yaml:"jitter"`
+	// Retryable check the error can be retried. default is all errors can be retried.
+	Retryable func(err error) bool `json:"-" yaml:"-"`
+}
+
+// RetryHandler wrap a handler, retry the Handle with exponential backoff and jitter on it returns error.
+//
+// NOTICE: the retrying will block the caller, it is best to use it with the AsyncHandler. eg:
+//
+//	h := handler.NewAsyncHandler(handler.NewRetryHandler(httpHandler))
+type RetryHandler struct {
+	cfg   RetryConfig
+	inner slog.Handler
+}
+
+// NewRetryHandler create new RetryHandler
+//
+// Usage:
+//
+//	h := handler.NewRetryHandler(httpHandler, func(c *handler.RetryConfig) {
+//		c.MaxRetry = 5
+//		c.Retryable = func(err error) bool { return !errors.Is(err, errBadFormat) }
+//	})
+func NewRetryHandler(inner slog.Handler, fns ...func(c *RetryConfig)) *RetryHandler {
+	cfg := RetryConfig{
+		MaxRetry: 3,
+		Wait:     100 * time.Millisecond,
+		MaxWait:  5 * time.Second,
+		Jitter:   0.2,
+	}
+	for _, fn := range fns {
+		fn(&cfg)
+	}
+
+	if cfg.MaxRetry < 0 {
+		cfg.MaxRetry = 0
+	}
+	if cfg.MaxWait < cfg.Wait {
+		cfg.MaxWait = cfg.Wait
+	}
+
+	return &RetryHandler{cfg: cfg, inner: inner}
+}
+
+// Handler get the inner handler
+func (h *RetryHandler) Handler() slog.Handler {
+	return h.inner
+}
+
+// IsHandling Check if the current level can be handling
+func (h *RetryHandler) IsHandling(level slog.Level) bool {
+	return h.inner.IsHandling(level)
+}
+
+// Handle a log record, retry on the inner handler returns a retryable error.
+// will return the last error after all retries failed.
+func (h *RetryHandler) Handle(r *slog.Record) (err error) {
+	wait := h.cfg.Wait
+	for i := 0; i <= h.cfg.MaxRetry; i++ {
+		if i > 0 {
+			time.Sleep(h.jitter(wait))
+			if wait *= 2; wait > h.cfg.MaxWait {
+				wait = h.cfg.MaxWait
+			}
+		}
+
+		if err = h.inner.Handle(r); err == nil {
+			return nil
+		}
+		if h.cfg.Retryable != nil && !h.cfg.Retryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func (h *RetryHandler) jitter(wait time.Duration) time.Duration {
+	if max := int64(float64(wait) * h.cfg.Jitter); max > 0 {
+		return wait + time.Duration(rand.Int63n(max))
+	}
+	return wait
+}
+
+// Flush the inner handler
+func (h *RetryHandler) Flush() error {
+	return h.inner.Flush()
+}
+
+// Close the inner handler
+func (h *RetryHandler) Close() error {
+	return h.inner.Close()
+}
@@ -0,0 +1,76 @@
+package handler_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+var errTemp = errors.New("temporary error")
+
+// fails the first N Handle calls
+type flakyHandler struct {
+	testHandler
+	fails int
+	calls int
+	err   error
+}
+
+func (h *flakyHandler) Handle(_ *slog.Record) error {
+	h.calls++
+	if h.calls <= h.fails {
+		return h.err
+	}
+	return nil
+}
+
+func TestRetryHandler_Handle(t *testing.T) {
+	fh := &flakyHandler{fails: 2, err: errTemp}
+	h := handler.NewRetryHandler(fh, func(c *handler.RetryConfig) {
+		c.Wait = time.Millisecond
+	})
+	assert.Eq(t, fh, h.Handler())
+	assert.True(t, h.IsHandling(slog.InfoLevel))
+
+	st := time.Now()
+	assert.NoErr(t, h.Handle(newLogRecord("message")))
+	assert.Eq(t, 3, fh.calls)
+	// waited 1ms + 2ms
+	assert.Gt(t, time.Since(st), 3*time.Millisecond)
+
+	// all retries failed
+	fh = &flakyHandler{fails: 10, err: errTemp}
+	h = handler.NewRetryHandler(fh, func(c *handler.RetryConfig) {
+		c.MaxRetry = 2
+		c.Wait = time.Millisecond
+		c.Jitter = 0
+	})
+	assert.ErrMsg(t, h.Handle(newLogRecord("message")), "temporary error")
+	assert.Eq(t, 3, fh.calls)
+
+	assert.NoErr(t, h.Flush())
+	assert.NoErr(t, h.Close())
+}
+
+func TestRetryHandler_Retryable(t *testing.T) {
+	errFormat := errors.New("format error")
+	fh := &flakyHandler{fails: 10, err: errFormat}
+	h := handler.NewRetryHandler(fh, func(c *handler.RetryConfig) {
+		c.Wait = time.Millisecond
+		c.Retryable = func(err error) bool {
+			return !errors.Is(err, errFormat)
+		}
+	})
+
+	assert.ErrMsg(t, h.Handle(newLogRecord("message")), "format error")
+	assert.Eq(t, 1, fh.calls)
+
+	fh.err = errTemp
+	fh.calls = 0
+	assert.Err(t, h.Handle(newLogRecord("message")))
+	assert.Eq(t, 4, fh.calls)
+}
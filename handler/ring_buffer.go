@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"io"
+	"sync"
+
+	"github.com/gookit/goutil/basefn"
+	"github.com/gookit/slog"
+)
+
+// DefaultRingSize the default records number for the RingBufferHandler
+const DefaultRingSize = 100
+
+// RingBufferHandler keep the last N formatted records in memory, the oldest record is overwritten on full.
+//
+// It is useful for surfacing the recent logs on the admin/debug endpoint, without reading the log files.
+// it is safe for concurrent use. the Flush() and Close() are no-op.
+type RingBufferHandler struct {
+	NopFlushClose
+	slog.LevelWithFormatter
+
+	mu      sync.RWMutex
+	entries []string
+	// the next write position
+	next int
+	full bool
+}
+
+// NewRingBufferHandler create new RingBufferHandler, keep the last size records. default log level is InfoLevel
+//
+// Usage:
+//
+//	h := handler.NewRingBufferHandler(200, slog.DebugLevel)
+//	slog.PushHandler(h)
+//
+//	http.HandleFunc("/debug/logs", func(w http.ResponseWriter, r *http.Request) {
+//		_ = h.Dump(w)
+//	})
+func NewRingBufferHandler(size int, maxLv ...slog.Level) *RingBufferHandler {
+	if size <= 0 {
+		size = DefaultRingSize
+	}
+
+	h := &RingBufferHandler{entries: make([]string, size)}
+	h.Level = basefn.FirstOr(maxLv, slog.InfoLevel)
+	return h
+}
+
+// Handle a log record, format it and insert to the ring buffer.
+func (h *RingBufferHandler) Handle(r *slog.Record) error {
+	bts, err := h.Format(r)
+	if err != nil {
+		return err
+	}
+
+	// copy to string, the formatter buffer will be reused
+	entry := string(bts)
+
+	h.mu.Lock()
+	h.entries[h.next] = entry
+	h.next++
+	if h.next == len(h.entries) {
+		h.next = 0
+		h.full = true
+	}
+	h.mu.Unlock()
+	return nil
+}
+
+// Size get the max records number of the ring buffer
+func (h *RingBufferHandler) Size() int {
+	return len(h.entries)
+}
+
+// Len get the current records number
+func (h *RingBufferHandler) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.full {
+		return len(h.entries)
+	}
+	return h.next
+}
+
+// Entries get the formatted records, ordered from oldest to newest.
+func (h *RingBufferHandler) Entries() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if !h.full {
+		return append([]string(nil), h.entries[:h.next]...)
+	}
+
+	ss := make([]string, 0, len(h.entries))
+	ss = append(ss, h.entries[h.next:]...)
+	return append(ss, h.entries[:h.next]...)
+}
+
+// Dump write the formatted records to the writer, ordered from oldest to newest.
+func (h *RingBufferHandler) Dump(w io.Writer) error {
+	for _, entry := range h.Entries() {
+		if _, err := io.WriteString(w, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset clear all records in the ring buffer
+func (h *RingBufferHandler) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i := range h.entries {
+		h.entries[i] = ""
+	}
+	h.next, h.full = 0, false
+}
@@ -0,0 +1,69 @@
+package handler_test
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+func TestNewRingBufferHandler(t *testing.T) {
+	h := handler.NewRingBufferHandler(3)
+	h.SetFormatter(slog.NewTextFormatter("{{message}}\n"))
+	assert.Eq(t, 3, h.Size())
+	assert.True(t, h.IsHandling(slog.InfoLevel))
+	assert.False(t, h.IsHandling(slog.DebugLevel))
+	assert.Empty(t, h.Entries())
+
+	assert.NoErr(t, h.Handle(newLogRecord("message1")))
+	assert.NoErr(t, h.Handle(newLogRecord("message2")))
+	assert.Eq(t, 2, h.Len())
+	assert.Eq(t, []string{"message1\n", "message2\n"}, h.Entries())
+
+	// the oldest are overwritten
+	for i := 3; i <= 5; i++ {
+		assert.NoErr(t, h.Handle(newLogRecord("message"+strconv.Itoa(i))))
+	}
+	assert.Eq(t, 3, h.Len())
+	assert.Eq(t, []string{"message3\n", "message4\n", "message5\n"}, h.Entries())
+
+	buf := new(bytes.Buffer)
+	assert.NoErr(t, h.Dump(buf))
+	assert.Eq(t, "message3\nmessage4\nmessage5\n", buf.String())
+
+	assert.NoErr(t, h.Flush())
+	assert.NoErr(t, h.Close())
+	assert.Eq(t, 3, h.Len())
+
+	h.Reset()
+	assert.Eq(t, 0, h.Len())
+	assert.Empty(t, h.Entries())
+
+	// default size
+	assert.Eq(t, handler.DefaultRingSize, handler.NewRingBufferHandler(0).Size())
+}
+
+func TestRingBufferHandler_concurrent(t *testing.T) {
+	h := handler.NewRingBufferHandler(10, slog.DebugLevel)
+	l := slog.NewWithHandlers(h)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				l.Debug("message", j)
+				_ = h.Entries()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Eq(t, 10, h.Len())
+	assert.Len(t, h.Entries(), 10)
+}
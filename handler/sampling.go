@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gookit/slog"
+)
+
+// SamplingConfig for the SamplingHandler
+type SamplingConfig struct {
+	// Initial the first N records with same level and message in each Tick will be forwarded.
+	// the message is replaced by the sampling key on it set by Record.WithSampleKey()
+	// default is 100
+	Initial int `json:"initial" yaml:"initial"`
+	// Thereafter after Initial, every Mth record with same level and message will be forwarded.
+	// set 0 to drop all records after Initial. default is 100
+	Thereafter int `json:"thereafter" yaml:"thereafter"`
+	// Tick the window for reset the counters. default is 1s
+	Tick time.Duration `json:"tick" yaml:"tick"`
+}
+
+// SamplingHandler wrap a handler, sampling the records for reduce log volume.
+//
+// Records are counted by level and message(or the Record.SampleKey()) in each Tick window, the first Initial
+// records will be forwarded, then only forward every Thereafter-th record.
+//
+// refer the zap sampler: https://github.com/uber-go/zap/blob/master/zapcore/sampler.go
+type SamplingHandler struct {
+	cfg   SamplingConfig
+	inner slog.Handler
+
+	mu sync.Mutex
+	// counters for current window. key is "level:message"
+	counters map[string]int
+	resetAt  time.Time
+	dropped  uint64
+}
+
+// NewSamplingHandler create new SamplingHandler
+//
+// Usage:
+//
+//	h := handler.NewSamplingHandler(fileHandler, func(c *handler.SamplingConfig) {
+//		c.Initial = 10
+//		c.Thereafter = 50
+//	})
+func NewSamplingHandler(inner slog.Handler, fns ...func(c *SamplingConfig)) *SamplingHandler {
+	cfg := SamplingConfig{
+		Initial:    100,
+		Thereafter: 100,
+		Tick:       time.Second,
+	}
+	for _, fn := range fns {
+		fn(&cfg)
+	}
+
+	if cfg.Tick <= 0 {
+		cfg.Tick = time.Second
+	}
+
+	return &SamplingHandler{
+		cfg:      cfg,
+		inner:    inner,
+		counters: make(map[string]int),
+	}
+}
+
+// Handler get the inner handler
+func (h *SamplingHandler) Handler() slog.Handler {
+	return h.inner
+}
+
+// IsHandling Check if the current level can be handling
+func (h *SamplingHandler) IsHandling(level slog.Level) bool {
+	return h.inner.IsHandling(level)
+}
+
+// Handle a log record, drop it if not be sampled
+func (h *SamplingHandler) Handle(r *slog.Record) error {
+	if !h.sample(r) {
+		atomic.AddUint64(&h.dropped, 1)
+		return nil
+	}
+	return h.inner.Handle(r)
+}
+
+func (h *SamplingHandler) sample(r *slog.Record) bool {
+	now := r.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// reset counters on new window
+	if !now.Before(h.resetAt) {
+		if len(h.counters) > 0 {
+			h.counters = make(map[string]int)
+		}
+		h.resetAt = now.Add(h.cfg.Tick)
+	}
+
+	// the sampling key set by Record.WithSampleKey() takes precedence
+	key := r.SampleKey()
+	if key == "" {
+		key = r.Message
+	}
+
+	key = strconv.Itoa(int(r.Level)) + ":" + key
+	n := h.counters[key] + 1
+	h.counters[key] = n
+
+	if n <= h.cfg.Initial {
+		return true
+	}
+	return h.cfg.Thereafter > 0 && (n-h.cfg.Initial)%h.cfg.Thereafter == 0
+}
+
+// Dropped get the dropped records number
+func (h *SamplingHandler) Dropped() uint64 {
+	return atomic.LoadUint64(&h.dropped)
+}
+
+// Flush the inner handler
+func (h *SamplingHandler) Flush() error {
+	return h.inner.Flush()
+}
+
+// Close the inner handler
+func (h *SamplingHandler) Close() error {
+	return h.inner.Close()
+}
+
+// LevelSamplingHandler wrap a handler, forward the record with the probability of the level rate.
+//
+// eg: keep all errors, but only 1% of the debug records. the levels not listed are always forwarded.
+type LevelSamplingHandler struct {
+	inner slog.Handler
+	// sample rate by level, range is 0.0 - 1.0
+	rates map[slog.Level]float64
+	// the state of the random number generator
+	seed    uint64
+	dropped uint64
+}
+
+// NewLevelSamplingHandler create new LevelSamplingHandler
+//
+// Usage:
+//
+//	h := handler.NewLevelSamplingHandler(fileHandler, map[slog.Level]float64{
+//		slog.DebugLevel: 0.01,
+//		slog.InfoLevel:  0.1,
+//	})
+func NewLevelSamplingHandler(inner slog.Handler, rates map[slog.Level]float64) *LevelSamplingHandler {
+	h := &LevelSamplingHandler{
+		inner: inner,
+		rates: make(map[slog.Level]float64, len(rates)),
+		seed:  uint64(time.Now().UnixNano()),
+	}
+
+	for level, rate := range rates {
+		h.rates[level] = rate
+	}
+	return h
+}
+
+// Handler get the inner handler
+func (h *LevelSamplingHandler) Handler() slog.Handler {
+	return h.inner
+}
+
+// IsHandling Check if the current level can be handling
+func (h *LevelSamplingHandler) IsHandling(level slog.Level) bool {
+	return h.inner.IsHandling(level)
+}
+
+// Handle a log record, drop it if not be sampled
+func (h *LevelSamplingHandler) Handle(r *slog.Record) error {
+	if !h.sample(r.Level) {
+		atomic.AddUint64(&h.dropped, 1)
+		return nil
+	}
+	return h.inner.Handle(r)
+}
+
+func (h *LevelSamplingHandler) sample(level slog.Level) bool {
+	rate, ok := h.rates[level]
+	if !ok || rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	return h.random() < rate
+}
+
+// random returns a float64 in [0.0, 1.0). by the splitmix64 generator, it is lock-free.
+func (h *LevelSamplingHandler) random() float64 {
+	z := atomic.AddUint64(&h.seed, 0x9e3779b97f4a7c15)
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return float64(z>>11) / (1 << 53)
+}
+
+// Dropped get the dropped records number
+func (h *LevelSamplingHandler) Dropped() uint64 {
+	return atomic.LoadUint64(&h.dropped)
+}
+
+// Flush the inner handler
+func (h *LevelSamplingHandler) Flush() error {
+	return h.inner.Flush()
+}
+
+// Close the inner handler
+func (h *LevelSamplingHandler) Close() error {
+	return h.inner.Close()
+}
@@ -0,0 +1,152 @@
+package handler_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+func TestSamplingHandler_Handle(t *testing.T) {
+	w := new(syncMsgs)
+	h := handler.NewSamplingHandler(w, func(c *handler.SamplingConfig) {
+		c.Initial = 2
+		c.Thereafter = 3
+		c.Tick = time.Second
+	})
+	assert.True(t, h.IsHandling(slog.InfoLevel))
+
+	now := time.Now()
+	newRecord := func(msg string, level slog.Level, at time.Time) *slog.Record {
+		r := newLogRecord(msg)
+		r.Level = level
+		r.Time = at
+		return r
+	}
+
+	// 1, 2 initial. then 5, 8 forwarded
+	for i := 0; i < 8; i++ {
+		assert.NoErr(t, h.Handle(newRecord("repeated", slog.InfoLevel, now)))
+	}
+	assert.Len(t, w.Messages(), 4)
+	assert.Eq(t, uint64(4), h.Dropped())
+
+	// counted by level
+	assert.NoErr(t, h.Handle(newRecord("repeated", slog.ErrorLevel, now)))
+	assert.Len(t, w.Messages(), 5)
+
+	// new window, reset counters
+	assert.NoErr(t, h.Handle(newRecord("repeated", slog.InfoLevel, now.Add(time.Second))))
+	assert.Len(t, w.Messages(), 6)
+
+	assert.NoErr(t, h.Flush())
+	assert.NoErr(t, h.Close())
+}
+
+func TestSamplingHandler_sampleKey(t *testing.T) {
+	w := new(syncMsgs)
+	h := handler.NewSamplingHandler(w, func(c *handler.SamplingConfig) {
+		c.Initial = 2
+		c.Thereafter = 0
+		c.Tick = time.Hour
+	})
+
+	l := slog.NewWithHandlers(h)
+	// the different messages with same sampling key are the same event
+	for i := 0; i < 5; i++ {
+		l.WithSampleKey("order-created").Infof("order %d created", i)
+	}
+	assert.Eq(t, []string{"order 0 created", "order 1 created"}, w.Messages())
+	assert.Eq(t, uint64(3), h.Dropped())
+
+	// counted by the message on no sampling key
+	l.Info("order 9 created")
+	assert.Len(t, w.Messages(), 3)
+
+	r := newLogRecord("message").WithSampleKey("key1")
+	assert.Eq(t, "key1", r.SampleKey())
+	assert.Eq(t, "", newLogRecord("message").SampleKey())
+}
+
+func TestSamplingHandler_concurrent(t *testing.T) {
+	w := new(syncMsgs)
+	h := handler.NewSamplingHandler(w, func(c *handler.SamplingConfig) {
+		c.Initial = 10
+		c.Thereafter = 0
+		c.Tick = time.Hour
+	})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				_ = h.Handle(newLogRecord("concurrent message"))
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, w.Messages(), 10)
+	assert.Eq(t, uint64(90), h.Dropped())
+}
+
+func TestLevelSamplingHandler_Handle(t *testing.T) {
+	w := new(syncMsgs)
+	h := handler.NewLevelSamplingHandler(w, map[slog.Level]float64{
+		slog.DebugLevel: 0.1,
+		slog.TraceLevel: 0,
+		slog.InfoLevel:  1,
+	})
+	assert.True(t, h.IsHandling(slog.InfoLevel))
+
+	newRecord := func(level slog.Level) *slog.Record {
+		r := newLogRecord("sampling message")
+		r.Level = level
+		return r
+	}
+
+	// not listed and rate=1 levels are always forwarded
+	for i := 0; i < 100; i++ {
+		assert.NoErr(t, h.Handle(newRecord(slog.ErrorLevel)))
+		assert.NoErr(t, h.Handle(newRecord(slog.InfoLevel)))
+		assert.NoErr(t, h.Handle(newRecord(slog.TraceLevel)))
+	}
+	assert.Len(t, w.Messages(), 200)
+	assert.Eq(t, uint64(100), h.Dropped())
+
+	// about 10% forwarded
+	for i := 0; i < 10000; i++ {
+		assert.NoErr(t, h.Handle(newRecord(slog.DebugLevel)))
+	}
+	forwarded := len(w.Messages()) - 200
+	assert.Gt(t, forwarded, 800)
+	assert.Lt(t, forwarded, 1200)
+
+	assert.NoErr(t, h.Flush())
+	assert.NoErr(t, h.Close())
+}
+
+func TestLevelSamplingHandler_concurrent(t *testing.T) {
+	w := new(syncMsgs)
+	h := handler.NewLevelSamplingHandler(w, map[slog.Level]float64{slog.InfoLevel: 0.5})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = h.Handle(newLogRecord("concurrent message"))
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Eq(t, 1000, len(w.Messages())+int(h.Dropped()))
+	assert.Gt(t, h.Dropped(), uint64(300))
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gookit/goutil/errorx"
+	"github.com/gookit/goutil/strutil"
+	"github.com/gookit/slog"
+)
+
+type splitWriter struct {
+	value string
+	out   SyncCloseWriter
+}
+
+// SplitFileHandler split the records to multiple files by a field value. eg: per-tenant log files.
+//
+// The writer of each file is created lazily by the Config, and cached in LRU. the least recently used
+// writer will be closed on the opened files exceed the MaxOpen.
+//
+// The field value is read from Record.Fields, then Record.Data. the records missing the field
+// will write to the file of DefaultValue. the characters not in [A-Za-z0-9._-] in value are replaced by "_".
+type SplitFileHandler struct {
+	slog.LevelFormattable
+	// MaxOpen the max number of the opened files. default is 64
+	MaxOpen int
+	// DefaultValue for the records missing the field. default is "default"
+	DefaultValue string
+
+	cfg     *Config
+	field   string
+	pathTpl string
+
+	mu sync.Mutex
+	// the LRU list of *splitWriter, the front is the most recently used
+	lru     *list.List
+	writers map[string]*list.Element
+}
+
+// NewSplitFileHandler create new SplitFileHandler. the pathTpl must contain the placeholder "{field}".
+//
+// Usage:
+//
+//	h, err := handler.NewSplitFileHandler("tenant", "/logs/{tenant}.log", handler.WithRotateTime(rotatefile.EveryDay))
+//
+//	l.WithField("tenant", "acme").Info("message") // write to /logs/acme.log
+//	l.Info("message") // write to /logs/default.log
+func NewSplitFileHandler(field, pathTpl string, fns ...ConfigFn) (*SplitFileHandler, error) {
+	if !strings.Contains(pathTpl, "{"+field+"}") {
+		return nil, fmt.Errorf("slog: the path template %q must contain the placeholder {%s}", pathTpl, field)
+	}
+
+	cfg := NewConfig(fns...)
+	h := &SplitFileHandler{
+		MaxOpen:      64,
+		DefaultValue: "default",
+		// with log level and formatter
+		LevelFormattable: cfg.newLevelFormattable(),
+		cfg:              cfg,
+		field:            field,
+		pathTpl:          pathTpl,
+		lru:              list.New(),
+		writers:          make(map[string]*list.Element),
+	}
+
+	if cfg.UseJSON {
+		h.SetFormatter(slog.NewJSONFormatter())
+	}
+	return h, nil
+}
+
+// OpenNum get the number of the opened files
+func (h *SplitFileHandler) OpenNum() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.lru.Len()
+}
+
+// InheritFormatter use the logger default formatter on not set formatter. see slog.FormatterInheritor
+func (h *SplitFileHandler) InheritFormatter(f slog.Formatter) {
+	inheritFormatter(h.LevelFormattable, f)
+}
+
+// Handle a log record, write to the file of the field value.
+func (h *SplitFileHandler) Handle(r *slog.Record) error {
+	bts, err := h.Formatter().Format(r)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	out, err := h.writerFor(h.fieldValue(r))
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(bts)
+	return err
+}
+
+func (h *SplitFileHandler) fieldValue(r *slog.Record) string {
+	val, ok := r.Fields[h.field]
+	if !ok {
+		val, ok = r.Data[h.field]
+	}
+
+	var s string
+	if ok && val != nil {
+		s = sanitizeFileName(strutil.SafeString(val))
+	}
+	if s == "" {
+		return h.DefaultValue
+	}
+	return s
+}
+
+// get or create the writer for the value. should be in lock
+func (h *SplitFileHandler) writerFor(value string) (SyncCloseWriter, error) {
+	if el, ok := h.writers[value]; ok {
+		h.lru.MoveToFront(el)
+		return el.Value.(*splitWriter).out, nil
+	}
+
+	// close the least recently used writers
+	for h.MaxOpen > 0 && h.lru.Len() >= h.MaxOpen {
+		if err := h.closeWriter(h.lru.Back()); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := *h.cfg
+	cfg.Logfile = strings.ReplaceAll(h.pathTpl, "{"+h.field+"}", value)
+
+	out, err := cfg.CreateWriter()
+	if err != nil {
+		return nil, err
+	}
+
+	h.writers[value] = h.lru.PushFront(&splitWriter{value: value, out: out})
+	return out, nil
+}
+
+// close the writer and remove it. should be in lock
+func (h *SplitFileHandler) closeWriter(el *list.Element) error {
+	sw := h.lru.Remove(el).(*splitWriter)
+	delete(h.writers, sw.value)
+
+	if err := sw.out.Sync(); err != nil {
+		_ = sw.out.Close()
+		return err
+	}
+	return sw.out.Close()
+}
+
+// Flush all opened files
+func (h *SplitFileHandler) Flush() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var es errorx.Errors
+	for el := h.lru.Front(); el != nil; el = el.Next() {
+		if err := el.Value.(*splitWriter).out.Sync(); err != nil {
+			es = append(es, err)
+		}
+	}
+	return es.ErrorOrNil()
+}
+
+// Close all opened files
+func (h *SplitFileHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var es errorx.Errors
+	for h.lru.Len() > 0 {
+		if err := h.closeWriter(h.lru.Front()); err != nil {
+			es = append(es, err)
+		}
+	}
+	return es.ErrorOrNil()
+}
+
+// replace the chars not in [A-Za-z0-9._-] by "_", avoid the path traversal.
+func sanitizeFileName(s string) string {
+	bs := []byte(s)
+	for i, c := range bs {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '_' || c == '-') {
+			bs[i] = '_'
+		}
+	}
+
+	s = string(bs)
+	if s == "." || s == ".." {
+		return "_"
+	}
+	return s
+}
@@ -0,0 +1,65 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/gookit/goutil/fsutil"
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+func TestNewSplitFileHandler(t *testing.T) {
+	_, err := handler.NewSplitFileHandler("tenant", "./testdata/split/app.log")
+	assert.ErrSubMsg(t, err, "must contain the placeholder {tenant}")
+
+	h, err := handler.NewSplitFileHandler("tenant", "./testdata/split/{tenant}.log", handler.WithBuffSize(0))
+	assert.NoErr(t, err)
+	h.MaxOpen = 2
+
+	l := slog.NewWithHandlers(h)
+	l.WithField("tenant", "acme").Info("acme message 1")
+	l.WithField("tenant", "globex").Info("globex message")
+	l.WithData(slog.M{"tenant": "acme"}).Info("acme message 2")
+	l.Info("no tenant message")
+	assert.Eq(t, 2, h.OpenNum())
+
+	// the path traversal is not allowed
+	l.WithField("tenant", "../escape").Info("escape message")
+	assert.Eq(t, 2, h.OpenNum())
+	assert.NoErr(t, l.Close())
+	assert.Eq(t, 0, h.OpenNum())
+
+	str := string(fsutil.MustReadFile("./testdata/split/acme.log"))
+	assert.StrContains(t, str, "acme message 1")
+	assert.StrContains(t, str, "acme message 2")
+	assert.NotContains(t, str, "globex message")
+
+	str = string(fsutil.MustReadFile("./testdata/split/globex.log"))
+	assert.StrContains(t, str, "globex message")
+
+	str = string(fsutil.MustReadFile("./testdata/split/default.log"))
+	assert.StrContains(t, str, "no tenant message")
+
+	assert.True(t, fsutil.IsFile("./testdata/split/.._escape.log"))
+	assert.False(t, fsutil.IsFile("./testdata/escape.log"))
+}
+
+func TestSplitFileHandler_reopen(t *testing.T) {
+	h, err := handler.NewSplitFileHandler("user", "./testdata/split-reopen/{user}.log", handler.WithUseJSON(true))
+	assert.NoErr(t, err)
+	h.MaxOpen = 1
+
+	l := slog.NewWithHandlers(h)
+	l.WithField("user", "tom").Info("message 1")
+	l.WithField("user", "john").Info("message 2")
+	// the tom writer is closed, will reopen it
+	l.WithField("user", "tom").Info("message 3")
+	assert.Eq(t, 1, h.OpenNum())
+	assert.NoErr(t, l.FlushAll())
+	assert.NoErr(t, l.Close())
+
+	str := string(fsutil.MustReadFile("./testdata/split-reopen/tom.log"))
+	assert.StrContains(t, str, `"message":"message 1"`)
+	assert.StrContains(t, str, `"message":"message 3"`)
+}
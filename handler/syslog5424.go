@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gookit/slog"
+)
+
+// SyslogFacility for RFC5424 syslog message
+type SyslogFacility uint8
+
+// built-in syslog facility consts
+const (
+	FacilityKern SyslogFacility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+)
+
+// local use syslog facility consts
+const (
+	FacilityLocal0 SyslogFacility = iota + 16
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// syslog severity consts
+const (
+	SeverityEmergency uint8 = iota
+	SeverityAlert
+	SeverityCritical
+	SeverityError
+	SeverityWarning
+	SeverityNotice
+	SeverityInfo
+	SeverityDebug
+)
+
+// SyslogSeverity map slog level to syslog severity
+func SyslogSeverity(level slog.Level) uint8 {
+	switch {
+	case level <= slog.FatalLevel:
+		return SeverityEmergency
+	case level <= slog.ErrorLevel:
+		return SeverityError
+	case level <= slog.WarnLevel:
+		return SeverityWarning
+	case level <= slog.NoticeLevel:
+		return SeverityNotice
+	case level <= slog.InfoLevel:
+		return SeverityInfo
+	default:
+		return SeverityDebug
+	}
+}
+
+// SyslogConfig for the SyslogHandler
+type SyslogConfig struct {
+	// Network allow: udp, tcp, unix, unixgram. if empty, will connect to the local syslog socket.
+	Network string `json:"network" yaml:"network"`
+	// Addr syslog server address. eg: "127.0.0.1:514", "/dev/log"
+	Addr string `json:"addr" yaml:"addr"`
+	// Facility for the message. default is FacilityUser
+	Facility SyslogFacility `json:"facility" yaml:"facility"`
+	// AppName for the message. default is the program name.
+	// the non-printable chars and spaces are replaced by "_", and it is truncated to 48 chars by RFC5424.
+	AppName string `json:"app_name" yaml:"app_name"`
+	// Hostname for the message. default is os.Hostname()
+	Hostname string `json:"hostname" yaml:"hostname"`
+	// Timeout for connect and write each message. default is 3s, set negative value to disable
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+// SyslogHandler write RFC5424 formatted messages to syslog server by network.
+//
+// Difference to the SysLogHandler, it does not depend on the log/syslog, and the
+// message is formatted by RFC5424:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+//
+// The MSG part is formatted by the handler formatter.
+type SyslogHandler struct {
+	slog.LevelWithFormatter
+	cfg SyslogConfig
+
+	mu   sync.Mutex
+	conn net.Conn
+	pid  string
+	// use octet counting framing on the stream connection. see RFC6587
+	framing bool
+}
+
+// NewSyslogHandler create new SyslogHandler, will connect to the syslog server.
+//
+// Usage:
+//
+//	h, err := handler.NewSyslogHandler(handler.SyslogConfig{
+//		Network:  "udp",
+//		Addr:     "127.0.0.1:514",
+//		Facility: handler.FacilityLocal0,
+//		AppName:  "myapp",
+//	})
+func NewSyslogHandler(cfg SyslogConfig) (*SyslogHandler, error) {
+	if cfg.Facility == FacilityKern {
+		cfg.Facility = FacilityUser
+	}
+	if cfg.AppName == "" {
+		cfg.AppName = filepath.Base(os.Args[0])
+	}
+	cfg.AppName = syslogAppName(cfg.AppName)
+	if cfg.Hostname == "" {
+		cfg.Hostname, _ = os.Hostname()
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 3 * time.Second
+	}
+
+	h := &SyslogHandler{
+		cfg: cfg,
+		pid: strconv.Itoa(os.Getpid()),
+	}
+	// init default log level
+	h.Level = slog.InfoLevel
+
+	if err := h.connect(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Config get the config
+func (h *SyslogHandler) Config() SyslogConfig {
+	return h.cfg
+}
+
+var localSyslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+func (h *SyslogHandler) connect() (err error) {
+	if h.cfg.Network != "" {
+		h.conn, err = dialTimeout(h.cfg.Network, h.cfg.Addr, h.cfg.Timeout, nil)
+		h.framing = isStreamNetwork(h.cfg.Network)
+		return err
+	}
+
+	// connect to local syslog socket
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range localSyslogPaths {
+			if h.conn, err = dialTimeout(network, path, h.cfg.Timeout, nil); err == nil {
+				h.framing = isStreamNetwork(network)
+				return nil
+			}
+		}
+	}
+	return errors.New("slog: unix syslog delivery error")
+}
+
+// Handle a log record
+func (h *SyslogHandler) Handle(r *slog.Record) error {
+	bts, err := h.Format(r)
+	if err != nil {
+		return err
+	}
+
+	msg := h.buildMessage(r, bts)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.conn != nil {
+		if err = h.write(msg); err == nil {
+			return nil
+		}
+		_ = h.conn.Close()
+
+		// the server is stalled, don't reconnect and block again. will reconnect on next write.
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			h.conn = nil
+			return err
+		}
+	}
+
+	// reconnect and write again
+	if err = h.connect(); err != nil {
+		h.conn = nil
+		return err
+	}
+
+	return h.write(msg)
+}
+
+func (h *SyslogHandler) write(msg []byte) error {
+	if h.framing {
+		frame := strconv.AppendInt(make([]byte, 0, len(msg)+8), int64(len(msg)), 10)
+		frame = append(frame, ' ')
+		msg = append(frame, msg...)
+	}
+
+	_, err := writeTimeout(h.conn, msg, h.cfg.Timeout)
+	return err
+}
+
+func (h *SyslogHandler) buildMessage(r *slog.Record, body []byte) []byte {
+	pri := int(h.cfg.Facility)*8 + int(SyslogSeverity(r.Level))
+	ts := r.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	buf := make([]byte, 0, len(body)+96)
+	buf = append(buf, '<')
+	buf = strconv.AppendInt(buf, int64(pri), 10)
+	buf = append(buf, ">1 "...)
+	buf = ts.AppendFormat(buf, "2006-01-02T15:04:05.000000Z07:00")
+	buf = append(buf, ' ')
+	buf = append(buf, nilValue(h.cfg.Hostname)...)
+	buf = append(buf, ' ')
+	buf = append(buf, nilValue(h.cfg.AppName)...)
+	buf = append(buf, ' ')
+	buf = append(buf, h.pid...)
+	// MSGID and STRUCTURED-DATA are nil
+	buf = append(buf, " - - "...)
+	return append(buf, bytes.TrimRight(body, "\r\n")...)
+}
+
+func isStreamNetwork(network string) bool {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	}
+	return false
+}
+
+// the RFC5424 APP-NAME is at most 48 printable US-ASCII chars, no space.
+func syslogAppName(s string) string {
+	bs := []byte(s)
+	if len(bs) > 48 {
+		bs = bs[:48]
+	}
+
+	for i, c := range bs {
+		if c < 33 || c > 126 {
+			bs[i] = '_'
+		}
+	}
+	return string(bs)
+}
+
+func nilValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// Flush handler
+func (h *SyslogHandler) Flush() error {
+	return nil
+}
+
+// Close handler
+func (h *SyslogHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.conn == nil {
+		return nil
+	}
+
+	err := h.conn.Close()
+	h.conn = nil
+	return err
+}
@@ -0,0 +1,224 @@
+package handler_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+func TestSyslogSeverity(t *testing.T) {
+	assert.Eq(t, handler.SeverityEmergency, handler.SyslogSeverity(slog.PanicLevel))
+	assert.Eq(t, handler.SeverityEmergency, handler.SyslogSeverity(slog.FatalLevel))
+	assert.Eq(t, handler.SeverityError, handler.SyslogSeverity(slog.ErrorLevel))
+	assert.Eq(t, handler.SeverityWarning, handler.SyslogSeverity(slog.WarnLevel))
+	assert.Eq(t, handler.SeverityNotice, handler.SyslogSeverity(slog.NoticeLevel))
+	assert.Eq(t, handler.SeverityInfo, handler.SyslogSeverity(slog.InfoLevel))
+	assert.Eq(t, handler.SeverityDebug, handler.SyslogSeverity(slog.DebugLevel))
+	assert.Eq(t, handler.SeverityDebug, handler.SyslogSeverity(slog.TraceLevel))
+}
+
+func TestSyslogHandler_udp(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoErr(t, err)
+	defer pc.Close()
+
+	h, err := handler.NewSyslogHandler(handler.SyslogConfig{
+		Network:  "udp",
+		Addr:     pc.LocalAddr().String(),
+		Facility: handler.FacilityLocal0,
+		AppName:  "testapp",
+		Hostname: "myhost",
+	})
+	assert.NoErr(t, err)
+	h.SetFormatter(newTestFormatter())
+	assert.False(t, h.IsHandling(slog.DebugLevel))
+
+	r := newLogRecord("syslog message")
+	r.Level = slog.ErrorLevel
+	assert.NoErr(t, h.Handle(r))
+
+	buf := make([]byte, 1024)
+	n, _, err := pc.ReadFrom(buf)
+	assert.NoErr(t, err)
+
+	// local0(16)*8 + error(3)
+	msg := string(buf[:n])
+	assert.True(t, strings.HasPrefix(msg, "<131>1 "))
+	assert.StrContains(t, msg, " myhost testapp ")
+	assert.True(t, strings.HasSuffix(msg, " - - syslog message"))
+
+	assert.NoErr(t, h.Flush())
+	assert.NoErr(t, h.Close())
+}
+
+func TestSyslogHandler_tcp(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoErr(t, err)
+	defer ln.Close()
+
+	msgs := readFramedSyslog(ln)
+	h, err := handler.NewSyslogHandler(handler.SyslogConfig{
+		Network: "tcp",
+		Addr:    ln.Addr().String(),
+		AppName: "testapp",
+	})
+	assert.NoErr(t, err)
+	assert.Eq(t, handler.FacilityUser, h.Config().Facility)
+	h.SetFormatter(newTestFormatter())
+
+	assert.NoErr(t, h.Handle(newLogRecord("tcp message1\n")))
+	assert.NoErr(t, h.Handle(newLogRecord("tcp message2")))
+
+	// user(1)*8 + info(6)
+	msg := <-msgs
+	assert.True(t, strings.HasPrefix(msg, "<14>1 "))
+	assert.True(t, strings.HasSuffix(msg, " - - tcp message1"))
+	msg = <-msgs
+	assert.True(t, strings.HasSuffix(msg, " - - tcp message2"))
+
+	assert.NoErr(t, h.Close())
+	assert.NoErr(t, h.Close())
+}
+
+func TestSyslogHandler_unix(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skip on windows")
+	}
+
+	ln, err := net.Listen("unix", filepath.Join(t.TempDir(), "syslog.sock"))
+	assert.NoErr(t, err)
+	defer ln.Close()
+
+	// the messages on unix stream is framed too
+	msgs := readFramedSyslog(ln)
+	h, err := handler.NewSyslogHandler(handler.SyslogConfig{
+		Network: "unix",
+		Addr:    ln.Addr().String(),
+	})
+	assert.NoErr(t, err)
+	h.SetFormatter(newTestFormatter())
+
+	assert.NoErr(t, h.Handle(newLogRecord("unix message1")))
+	assert.NoErr(t, h.Handle(newLogRecord("unix message2")))
+	assert.True(t, strings.HasSuffix(<-msgs, " - - unix message1"))
+	assert.True(t, strings.HasSuffix(<-msgs, " - - unix message2"))
+	assert.NoErr(t, h.Close())
+}
+
+func TestSyslogHandler_appName(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoErr(t, err)
+	defer pc.Close()
+
+	// default is the program name, not the full path
+	h, err := handler.NewSyslogHandler(handler.SyslogConfig{
+		Network: "udp",
+		Addr:    pc.LocalAddr().String(),
+	})
+	assert.NoErr(t, err)
+	assert.Eq(t, filepath.Base(os.Args[0]), h.Config().AppName)
+	assert.NoErr(t, h.Close())
+
+	h, err = handler.NewSyslogHandler(handler.SyslogConfig{
+		Network: "udp",
+		Addr:    pc.LocalAddr().String(),
+		AppName: "my app " + strings.Repeat("a", 60),
+	})
+	assert.NoErr(t, err)
+	assert.Eq(t, "my_app_"+strings.Repeat("a", 41), h.Config().AppName)
+	assert.NoErr(t, h.Close())
+}
+
+// read octet counting framed messages: "LEN MSG"
+func readFramedSyslog(ln net.Listener) <-chan string {
+	msgs := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		rd := bufio.NewReader(conn)
+		for {
+			size, err := rd.ReadString(' ')
+			if err != nil {
+				return
+			}
+
+			n, _ := strconv.Atoi(strings.TrimSpace(size))
+			buf := make([]byte, n)
+			if _, err = io.ReadFull(rd, buf); err != nil {
+				return
+			}
+			msgs <- string(buf)
+		}
+	}()
+	return msgs
+}
+
+func TestSyslogHandler_writeTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoErr(t, err)
+	defer ln.Close()
+
+	// accept but never read, the write will stall on the buffers is full
+	conns := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			conns <- conn
+		}
+	}()
+
+	h, err := handler.NewSyslogHandler(handler.SyslogConfig{
+		Network: "tcp",
+		Addr:    ln.Addr().String(),
+		Timeout: 50 * time.Millisecond,
+	})
+	assert.NoErr(t, err)
+	h.SetFormatter(newTestFormatter())
+	defer func() {
+		_ = h.Close()
+		if conn := <-conns; conn != nil {
+			_ = conn.Close()
+		}
+	}()
+
+	msg := strings.Repeat("a", 1<<20)
+	for i := 0; i < 200 && err == nil; i++ {
+		err = h.Handle(newLogRecord(msg))
+	}
+
+	assert.Err(t, err)
+	nErr, ok := err.(net.Error)
+	assert.True(t, ok)
+	assert.True(t, nErr.Timeout())
+}
+
+func TestSyslogHandler_noTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoErr(t, err)
+	defer ln.Close()
+
+	// negative value to disable timeout, should not fail on connect
+	h, err := handler.NewSyslogHandler(handler.SyslogConfig{
+		Network: "tcp",
+		Addr:    ln.Addr().String(),
+		Timeout: -1,
+	})
+	assert.NoErr(t, err)
+	h.SetFormatter(newTestFormatter())
+	assert.NoErr(t, h.Handle(newLogRecord("message")))
+	assert.NoErr(t, h.Close())
+}
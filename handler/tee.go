@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"io"
+	"sync"
+
+	"github.com/gookit/slog"
+)
+
+// TeeHandler wrap a handler, write every record to the inner handler,
+// and additionally write the records with level >= minLevel to the mirror writer.
+//
+// The mirror records are formatted by the inner handler formatter, if the inner is not
+// slog.Formattable, will use the slog.TextFormatter.
+type TeeHandler struct {
+	inner    slog.Handler
+	minLevel slog.Level
+
+	mu        sync.Mutex
+	mirror    io.Writer
+	formatter slog.Formatter
+}
+
+// TeeOnLevel create new TeeHandler. eg: write all records to file, and mirror the error records to stderr.
+//
+// Usage:
+//
+//	h := handler.TeeOnLevel(fileHandler, slog.ErrorLevel, os.Stderr)
+func TeeOnLevel(inner slog.Handler, minLevel slog.Level, mirror io.Writer) *TeeHandler {
+	return &TeeHandler{
+		inner:    inner,
+		minLevel: minLevel,
+		mirror:   mirror,
+	}
+}
+
+// Handler get the inner handler
+func (h *TeeHandler) Handler() slog.Handler {
+	return h.inner
+}
+
+// IsHandling Check if the current level can be handling by the inner handler or the mirror
+func (h *TeeHandler) IsHandling(level slog.Level) bool {
+	return h.inner.IsHandling(level) || h.minLevel.ShouldHandling(level)
+}
+
+// Handle a log record. write to the inner handler, then write to the mirror on level matched.
+func (h *TeeHandler) Handle(r *slog.Record) (err error) {
+	if h.inner.IsHandling(r.Level) {
+		err = h.inner.Handle(r)
+	}
+
+	if h.minLevel.ShouldHandling(r.Level) {
+		if mErr := h.writeMirror(r); err == nil {
+			err = mErr
+		}
+	}
+	return err
+}
+
+func (h *TeeHandler) writeMirror(r *slog.Record) error {
+	bts, err := h.getFormatter().Format(r)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err = h.mirror.Write(bts)
+	return err
+}
+
+func (h *TeeHandler) getFormatter() slog.Formatter {
+	if f, ok := h.inner.(slog.Formattable); ok {
+		return f.Formatter()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.formatter == nil {
+		h.formatter = slog.NewTextFormatter()
+	}
+	return h.formatter
+}
+
+// Flush the inner handler and the mirror writer(if it is a flusher or syncer)
+func (h *TeeHandler) Flush() error {
+	err := h.inner.Flush()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if mErr := flushWriter(h.mirror); err == nil {
+		err = mErr
+	}
+	return err
+}
+
+// Close the inner handler and the mirror writer(if it is a closer).
+//
+// NOTICE: the os.Stdout and os.Stderr will not be closed.
+func (h *TeeHandler) Close() error {
+	err := h.Flush()
+	if cErr := h.inner.Close(); err == nil {
+		err = cErr
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if cErr := closeWriter(h.mirror); err == nil {
+		err = cErr
+	}
+	return err
+}
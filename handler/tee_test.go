@@ -0,0 +1,53 @@
+package handler_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+type closeBuffer struct {
+	bytes.Buffer
+	flushed, closed bool
+}
+
+func (b *closeBuffer) Flush() error {
+	b.flushed = true
+	return nil
+}
+
+func (b *closeBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestTeeOnLevel(t *testing.T) {
+	inBuf := new(bytes.Buffer)
+	inner := handler.NewIOWriterHandler(inBuf, []slog.Level{slog.InfoLevel, slog.ErrorLevel})
+	inner.SetFormatter(slog.NewTextFormatter("{{level}}: {{message}}\n"))
+
+	mirror := new(closeBuffer)
+	h := handler.TeeOnLevel(inner, slog.ErrorLevel, mirror)
+	assert.Eq(t, inner, h.Handler())
+	assert.True(t, h.IsHandling(slog.InfoLevel))
+	assert.True(t, h.IsHandling(slog.FatalLevel))
+	assert.False(t, h.IsHandling(slog.DebugLevel))
+
+	l := slog.NewWithHandlers(h)
+	l.DoNothingOnPanicFatal()
+	l.Info("info message")
+	l.Error("error message")
+	l.Fatal("fatal message")
+
+	assert.Eq(t, "INFO: info message\nERROR: error message\n", inBuf.String())
+	// use the inner formatter
+	assert.Eq(t, "ERROR: error message\nFATAL: fatal message\n", mirror.String())
+
+	assert.NoErr(t, h.Flush())
+	assert.True(t, mirror.flushed)
+	assert.NoErr(t, h.Close())
+	assert.True(t, mirror.closed)
+}
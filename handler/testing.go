@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/gookit/slog"
+)
+
+// TestHandler capture the handled records in memory, for assert the logs in the unit tests.
+//
+// The records are cloned on handle, so they can be read after the logger reuses the record.
+// it is safe for concurrent use. the Flush() and Close() are no-op.
+type TestHandler struct {
+	NopFlushClose
+	slog.LevelHandling
+
+	mu      sync.RWMutex
+	records []*slog.Record
+}
+
+// NewTestHandler create new TestHandler, will handle all levels by default.
+//
+// Usage:
+//
+//	h := handler.NewTestHandler()
+//	l := slog.NewWithHandlers(h)
+//
+//	doSomething(l)
+//	assert.True(t, h.HasMessage("connect failed"))
+//	assert.Eq(t, 1, h.CountLevel(slog.ErrorLevel))
+//	assert.NotNil(t, h.LastRecord().Field("error"))
+func NewTestHandler() *TestHandler {
+	h := &TestHandler{}
+	h.SetLimitLevels(slog.AllLevels)
+	return h
+}
+
+// Handle a log record, clone and append it to the records.
+func (h *TestHandler) Handle(r *slog.Record) error {
+	nr := r.Clone()
+
+	h.mu.Lock()
+	h.records = append(h.records, nr)
+	h.mu.Unlock()
+	return nil
+}
+
+// Records get a copy of the handled records, ordered by the handle time.
+func (h *TestHandler) Records() []*slog.Record {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append([]*slog.Record(nil), h.records...)
+}
+
+// LastRecord get the last handled record. returns nil if no record.
+func (h *TestHandler) LastRecord() *slog.Record {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.records) == 0 {
+		return nil
+	}
+	return h.records[len(h.records)-1]
+}
+
+// Len get the handled records number
+func (h *TestHandler) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.records)
+}
+
+// HasMessage check any handled record message contains the substr
+func (h *TestHandler) HasMessage(substr string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, r := range h.records {
+		if strings.Contains(r.Message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// CountLevel count the handled records of the level
+func (h *TestHandler) CountLevel(level slog.Level) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var n int
+	for _, r := range h.records {
+		if r.Level == level {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset clear all handled records
+func (h *TestHandler) Reset() {
+	h.mu.Lock()
+	h.records = nil
+	h.mu.Unlock()
+}
@@ -0,0 +1,73 @@
+package handler_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+func TestNewTestHandler(t *testing.T) {
+	h := handler.NewTestHandler()
+	assert.True(t, h.IsHandling(slog.TraceLevel))
+	assert.True(t, h.IsHandling(slog.PanicLevel))
+	assert.Nil(t, h.LastRecord())
+	assert.Empty(t, h.Records())
+	assert.False(t, h.HasMessage("message"))
+
+	l := slog.NewWithHandlers(h)
+	l.Info("info message")
+	l.WithField("error", errors.New("connect failed")).Errorf("request %s failed", "/api/users")
+	l.Error("error message")
+
+	assert.Eq(t, 3, h.Len())
+	assert.Len(t, h.Records(), 3)
+	assert.Eq(t, "info message", h.Records()[0].Message)
+	assert.True(t, h.HasMessage("/api/users"))
+	assert.False(t, h.HasMessage("not-exists"))
+	assert.Eq(t, 1, h.CountLevel(slog.InfoLevel))
+	assert.Eq(t, 2, h.CountLevel(slog.ErrorLevel))
+	assert.Eq(t, 0, h.CountLevel(slog.DebugLevel))
+
+	// the record is kept after the logger reuse it
+	r := h.LastRecord()
+	assert.Eq(t, "error message", r.Message)
+	assert.False(t, r.Time.IsZero())
+
+	r = h.Records()[1]
+	assert.Eq(t, slog.ErrorLevel, r.Level)
+	assert.Err(t, r.Field("error").(error), "connect failed")
+
+	assert.NoErr(t, h.Flush())
+	assert.NoErr(t, h.Close())
+	assert.Eq(t, 3, h.Len())
+
+	h.Reset()
+	assert.Eq(t, 0, h.Len())
+	assert.Nil(t, h.LastRecord())
+}
+
+func TestTestHandler_concurrent(t *testing.T) {
+	h := handler.NewTestHandler()
+	l := slog.NewWithHandlers(h)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				l.Warn("message", j)
+				_ = h.HasMessage("message")
+				_ = h.CountLevel(slog.WarnLevel)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Eq(t, 200, h.Len())
+	assert.Eq(t, 200, h.CountLevel(slog.WarnLevel))
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gookit/slog"
+)
+
+// ThrottleConfig for the ThrottleHandler
+type ThrottleConfig struct {
+	// Rate the max records per second to forward. default is 100
+	Rate float64 `json:"rate" yaml:"rate"`
+	// Burst the max records can be forwarded at once. default is equals Rate
+	Burst int `json:"burst" yaml:"burst"`
+}
+
+// ThrottleHandler wrap a handler, limit the global forward rate by a token bucket.
+//
+// Unlike the SamplingHandler, all records share one bucket. the excess records will be dropped.
+type ThrottleHandler struct {
+	cfg   ThrottleConfig
+	inner slog.Handler
+
+	mu     sync.Mutex
+	tokens float64
+	// last refill time
+	lastAt  time.Time
+	dropped uint64
+}
+
+// NewThrottleHandler create new ThrottleHandler
+//
+// Usage:
+//
+//	h := handler.NewThrottleHandler(fileHandler, func(c *handler.ThrottleConfig) {
+//		c.Rate = 500
+//		c.Burst = 1000
+//	})
+func NewThrottleHandler(inner slog.Handler, fns ...func(c *ThrottleConfig)) *ThrottleHandler {
+	cfg := ThrottleConfig{Rate: 100}
+	for _, fn := range fns {
+		fn(&cfg)
+	}
+
+	if cfg.Rate <= 0 {
+		cfg.Rate = 100
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = int(cfg.Rate)
+		if cfg.Burst < 1 {
+			cfg.Burst = 1
+		}
+	}
+
+	return &ThrottleHandler{
+		cfg:    cfg,
+		inner:  inner,
+		tokens: float64(cfg.Burst),
+	}
+}
+
+// Handler get the inner handler
+func (h *ThrottleHandler) Handler() slog.Handler {
+	return h.inner
+}
+
+// IsHandling Check if the current level can be handling
+func (h *ThrottleHandler) IsHandling(level slog.Level) bool {
+	return h.inner.IsHandling(level)
+}
+
+// Handle a log record, drop it if exceeds the rate limit
+func (h *ThrottleHandler) Handle(r *slog.Record) error {
+	if !h.allow(r.Time) {
+		atomic.AddUint64(&h.dropped, 1)
+		return nil
+	}
+	return h.inner.Handle(r)
+}
+
+func (h *ThrottleHandler) allow(now time.Time) bool {
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// refill tokens by elapsed time
+	if now.After(h.lastAt) {
+		if !h.lastAt.IsZero() {
+			h.tokens += now.Sub(h.lastAt).Seconds() * h.cfg.Rate
+			if burst := float64(h.cfg.Burst); h.tokens > burst {
+				h.tokens = burst
+			}
+		}
+		h.lastAt = now
+	}
+
+	if h.tokens < 1 {
+		return false
+	}
+
+	h.tokens--
+	return true
+}
+
+// Dropped get the dropped records number
+func (h *ThrottleHandler) Dropped() uint64 {
+	return atomic.LoadUint64(&h.dropped)
+}
+
+// Flush the inner handler
+func (h *ThrottleHandler) Flush() error {
+	return h.inner.Flush()
+}
+
+// Close the inner handler
+func (h *ThrottleHandler) Close() error {
+	return h.inner.Close()
+}
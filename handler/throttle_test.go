@@ -0,0 +1,75 @@
+package handler_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+func TestThrottleHandler_Handle(t *testing.T) {
+	w := new(syncMsgs)
+	h := handler.NewThrottleHandler(w, func(c *handler.ThrottleConfig) {
+		c.Rate = 10
+		c.Burst = 3
+	})
+	assert.True(t, h.IsHandling(slog.InfoLevel))
+
+	now := time.Now()
+	handle := func(msg string, at time.Time) {
+		r := newLogRecord(msg)
+		r.Time = at
+		assert.NoErr(t, h.Handle(r))
+	}
+
+	// burst 3, other messages share the bucket
+	for i := 0; i < 4; i++ {
+		handle("message", now)
+	}
+	handle("other message", now)
+	assert.Len(t, w.Messages(), 3)
+	assert.Eq(t, uint64(2), h.Dropped())
+
+	// refill 2 tokens after 200ms
+	for i := 0; i < 3; i++ {
+		handle("message", now.Add(200*time.Millisecond))
+	}
+	assert.Len(t, w.Messages(), 5)
+	assert.Eq(t, uint64(3), h.Dropped())
+
+	// refill up to burst
+	for i := 0; i < 5; i++ {
+		handle("message", now.Add(10*time.Second))
+	}
+	assert.Len(t, w.Messages(), 8)
+	assert.Eq(t, uint64(5), h.Dropped())
+
+	assert.NoErr(t, h.Flush())
+	assert.NoErr(t, h.Close())
+}
+
+func TestThrottleHandler_concurrent(t *testing.T) {
+	w := new(syncMsgs)
+	h := handler.NewThrottleHandler(w, func(c *handler.ThrottleConfig) {
+		c.Rate = 0.001
+		c.Burst = 10
+	})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				_ = h.Handle(newLogRecord("concurrent message"))
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, w.Messages(), 10)
+	assert.Eq(t, uint64(90), h.Dropped())
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrWriteTimeout error on the write exceeds the timeout
+var ErrWriteTimeout = errors.New("slog: write timeout")
+
+// TimeoutWriter wrap a writer, will run the write in a goroutine and
+// abandon it(returns ErrWriteTimeout) if it exceeds the timeout.
+//
+// NOTICE: the abandoned write goroutine will keep running until the underlying
+// writer returns. While it is blocking, the following writes will fail fast with
+// ErrWriteTimeout, so there is at most one abandoned goroutine at a time.
+type TimeoutWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+	// timeout for each write. if <= 0, will write directly.
+	timeout time.Duration
+	// mark the abandoned write is still running
+	stalled int32
+}
+
+type writeResult struct {
+	n   int
+	err error
+}
+
+// NewTimeoutWriter create new TimeoutWriter
+func NewTimeoutWriter(w io.Writer, timeout time.Duration) *TimeoutWriter {
+	return &TimeoutWriter{w: w, timeout: timeout}
+}
+
+// Writer get the wrapped writer
+func (tw *TimeoutWriter) Writer() io.Writer {
+	return tw.w
+}
+
+// Write data to the writer, returns ErrWriteTimeout if exceeds the timeout
+func (tw *TimeoutWriter) Write(p []byte) (int, error) {
+	if tw.timeout <= 0 {
+		return tw.w.Write(p)
+	}
+
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	// the previous abandoned write is still blocking
+	if atomic.LoadInt32(&tw.stalled) == 1 {
+		return 0, ErrWriteTimeout
+	}
+
+	// the caller may reuse p after the write abandoned, so copy it.
+	buf := append([]byte(nil), p...)
+	done := make(chan writeResult, 1)
+
+	go func() {
+		n, err := tw.w.Write(buf)
+		done <- writeResult{n: n, err: err}
+		atomic.StoreInt32(&tw.stalled, 0)
+	}()
+
+	timer := time.NewTimer(tw.timeout)
+	defer timer.Stop()
+
+	select {
+	case ret := <-done:
+		return ret.n, ret.err
+	case <-timer.C:
+		atomic.StoreInt32(&tw.stalled, 1)
+		// maybe completed at the same time
+		select {
+		case ret := <-done:
+			atomic.StoreInt32(&tw.stalled, 0)
+			return ret.n, ret.err
+		default:
+			return 0, ErrWriteTimeout
+		}
+	}
+}
+
+// Flush the writer, if it implements Flush() or Sync()
+func (tw *TimeoutWriter) Flush() error {
+	switch w := tw.w.(type) {
+	case interface{ Flush() error }:
+		return w.Flush()
+	case interface{ Sync() error }:
+		return w.Sync()
+	}
+	return nil
+}
+
+// Sync the writer, if it implements Sync()
+func (tw *TimeoutWriter) Sync() error {
+	if w, ok := tw.w.(interface{ Sync() error }); ok {
+		return w.Sync()
+	}
+	return nil
+}
+
+// Close the writer, if it implements io.Closer
+func (tw *TimeoutWriter) Close() error {
+	if w, ok := tw.w.(io.Closer); ok {
+		return w.Close()
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package handler_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
+)
+
+// blockWriter will block on Write() until the unblock chan closed.
+type blockWriter struct {
+	buf     bytes.Buffer
+	unblock chan struct{}
+}
+
+func (w *blockWriter) Write(p []byte) (int, error) {
+	<-w.unblock
+	return w.buf.Write(p)
+}
+
+func TestTimeoutWriter_timeout(t *testing.T) {
+	bw := &blockWriter{unblock: make(chan struct{})}
+	tw := handler.NewTimeoutWriter(bw, 30*time.Millisecond)
+
+	h := handler.NewIOWriter(tw, slog.AllLevels)
+	h.SetFormatter(newTestFormatter())
+
+	start := time.Now()
+	err := h.Handle(newLogRecord("blocked message"))
+	assert.Eq(t, handler.ErrWriteTimeout, err)
+	assert.True(t, time.Since(start) < time.Second)
+
+	// the abandoned write is still blocking, will fail fast
+	_, err = tw.Write([]byte("fail fast"))
+	assert.Eq(t, handler.ErrWriteTimeout, err)
+
+	// the abandoned write will be completed after unblock
+	close(bw.unblock)
+	time.Sleep(10 * time.Millisecond)
+
+	n, err := tw.Write([]byte(" next message"))
+	assert.NoErr(t, err)
+	assert.Eq(t, 13, n)
+	assert.Eq(t, "blocked message next message", bw.buf.String())
+
+	assert.NoErr(t, tw.Flush())
+	assert.NoErr(t, tw.Sync())
+	assert.NoErr(t, tw.Close())
+}
+
+func TestTimeoutWriter_noTimeout(t *testing.T) {
+	buf := new(bytes.Buffer)
+	tw := handler.NewTimeoutWriter(buf, 0)
+	assert.Eq(t, buf, tw.Writer())
+
+	_, err := tw.Write([]byte("message"))
+	assert.NoErr(t, err)
+	assert.Eq(t, "message", buf.String())
+}
+
+func TestConfig_CreateWriter_writeTimeout(t *testing.T) {
+	c := handler.NewEmptyConfig(
+		handler.WithLogfile("testdata/write-timeout.log"),
+		handler.WithWriteTimeout(time.Second),
+	)
+	assert.Eq(t, time.Second, c.WriteTimeout)
+
+	w, err := c.CreateWriter()
+	assert.NoErr(t, err)
+
+	_, ok := w.(*handler.TimeoutWriter)
+	assert.True(t, ok)
+	_, err = w.Write([]byte("message\n"))
+	assert.NoErr(t, err)
+	assert.NoErr(t, w.Sync())
+	assert.NoErr(t, w.Close())
+}
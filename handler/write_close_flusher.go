@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"io"
+
 	"github.com/gookit/slog"
 )
 
@@ -68,6 +70,16 @@ func (h *FlushCloseHandler) Flush() error {
 	return h.Output.Flush()
 }
 
+// Writer of the handler
+func (h *FlushCloseHandler) Writer() io.Writer {
+	return h.Output
+}
+
+// InheritFormatter use the logger default formatter on not set formatter. see slog.FormatterInheritor
+func (h *FlushCloseHandler) InheritFormatter(f slog.Formatter) {
+	inheritFormatter(h.LevelFormattable, f)
+}
+
 // Handle log record
 func (h *FlushCloseHandler) Handle(record *slog.Record) error {
 	bts, err := h.Formatter().Format(record)
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"io"
+	"sync"
+	"time"
 
 	"github.com/gookit/slog"
 )
@@ -10,6 +12,11 @@ import (
 type SyncCloseHandler struct {
 	slog.LevelFormattable
 	Output SyncCloseWriter
+
+	// lock the write and flush, the flush daemon calls Flush() in background
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	stopCh chan struct{}
 }
 
 // NewSyncCloserWithLF create new SyncCloseHandler, with custom slog.LevelFormattable
@@ -54,8 +61,53 @@ func NewSyncCloseHandler(out SyncCloseWriter, levels []slog.Level) *SyncCloseHan
 	return NewSyncCloserWithLF(out, slog.NewLvsFormatter(levels))
 }
 
-// Close the handler
+// StartFlusher start a background goroutine to flush the handler on each interval.
+// it bounds the data loss on crash to the interval. the goroutine is stopped on Close().
+//
+// It is disabled by default, avoid the extra syscalls. calls it again will do nothing.
+//
+// Usage:
+//
+//	h, err := handler.NewFileHandler("app.log", handler.WithBuffSize(4096))
+//	h.StartFlusher(time.Second)
+func (h *SyncCloseHandler) StartFlusher(interval time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if interval <= 0 || h.stopCh != nil {
+		return
+	}
+
+	h.stopCh = make(chan struct{})
+	h.wg.Add(1)
+	go h.flushDaemon(interval, h.stopCh)
+}
+
+func (h *SyncCloseHandler) flushDaemon(interval time.Duration, stopCh chan struct{}) {
+	defer h.wg.Done()
+	tk := time.NewTicker(interval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			printErrln("slog: sync close handler flush error:", h.Flush())
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// Close the handler. will stop the flusher, flush and close the output.
 func (h *SyncCloseHandler) Close() error {
+	h.mu.Lock()
+	if h.stopCh != nil {
+		close(h.stopCh)
+		h.stopCh = nil
+	}
+	h.mu.Unlock()
+	h.wg.Wait()
+
 	if err := h.Flush(); err != nil {
 		return err
 	}
@@ -64,14 +116,27 @@ func (h *SyncCloseHandler) Close() error {
 
 // Flush the handler
 func (h *SyncCloseHandler) Flush() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	return h.Output.Sync()
 }
 
+// Lock the write of the handler. for the wrapper handler write the Writer directly. eg: BufferedHandler
+func (h *SyncCloseHandler) Lock() { h.mu.Lock() }
+
+// Unlock the write of the handler
+func (h *SyncCloseHandler) Unlock() { h.mu.Unlock() }
+
 // Writer of the handler
 func (h *SyncCloseHandler) Writer() io.Writer {
 	return h.Output
 }
 
+// InheritFormatter use the logger default formatter on not set formatter. see slog.FormatterInheritor
+func (h *SyncCloseHandler) InheritFormatter(f slog.Formatter) {
+	inheritFormatter(h.LevelFormattable, f)
+}
+
 // Handle log record
 func (h *SyncCloseHandler) Handle(record *slog.Record) error {
 	bts, err := h.Formatter().Format(record)
@@ -79,6 +144,8 @@ func (h *SyncCloseHandler) Handle(record *slog.Record) error {
 		return err
 	}
 
+	h.mu.Lock()
 	_, err = h.Output.Write(bts)
+	h.mu.Unlock()
 	return err
 }
@@ -65,6 +65,16 @@ func (h *WriteCloserHandler) Flush() error {
 	return nil
 }
 
+// Writer of the handler
+func (h *WriteCloserHandler) Writer() io.Writer {
+	return h.Output
+}
+
+// InheritFormatter use the logger default formatter on not set formatter. see slog.FormatterInheritor
+func (h *WriteCloserHandler) InheritFormatter(f slog.Formatter) {
+	inheritFormatter(h.LevelFormattable, f)
+}
+
 // Handle log record
 func (h *WriteCloserHandler) Handle(record *slog.Record) error {
 	bts, err := h.Formatter().Format(record)
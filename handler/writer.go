@@ -6,9 +6,11 @@ import (
 	"github.com/gookit/slog"
 )
 
-// IOWriterHandler definition
+// IOWriterHandler definition. the Output can be any io.Writer.
+//
+// Flush will call the Output Flush() or Sync() method if it has, Close will call the Output Close()
+// if it is an io.Closer. the os.Stdout and os.Stderr will not be closed.
 type IOWriterHandler struct {
-	NopFlushClose
 	slog.LevelFormattable
 	Output io.Writer
 }
@@ -18,6 +20,24 @@ func (h *IOWriterHandler) TextFormatter() *slog.TextFormatter {
 	return h.Formatter().(*slog.TextFormatter)
 }
 
+// SetColorMode set the color mode for the text formatter, by the Output.
+// will be ignored on the formatter is not slog.TextFormatter.
+func (h *IOWriterHandler) SetColorMode(mode slog.ColorMode) {
+	if f, ok := h.Formatter().(*slog.TextFormatter); ok {
+		f.SetColorMode(mode, h.Output)
+	}
+}
+
+// Writer of the handler
+func (h *IOWriterHandler) Writer() io.Writer {
+	return h.Output
+}
+
+// InheritFormatter use the logger default formatter on not set formatter. see slog.FormatterInheritor
+func (h *IOWriterHandler) InheritFormatter(f slog.Formatter) {
+	inheritFormatter(h.LevelFormattable, f)
+}
+
 // Handle log record
 func (h *IOWriterHandler) Handle(record *slog.Record) error {
 	bts, err := h.Formatter().Format(record)
@@ -29,6 +49,19 @@ func (h *IOWriterHandler) Handle(record *slog.Record) error {
 	return err
 }
 
+// Flush the Output if it is a flusher or syncer
+func (h *IOWriterHandler) Flush() error {
+	return flushWriter(h.Output)
+}
+
+// Close the Output if it is an io.Closer. will flush it before close.
+func (h *IOWriterHandler) Close() error {
+	if err := h.Flush(); err != nil {
+		return err
+	}
+	return closeWriter(h.Output)
+}
+
 // NewIOWriterWithLF create new IOWriterHandler, with custom slog.LevelFormattable
 func NewIOWriterWithLF(out io.Writer, lf slog.LevelFormattable) *IOWriterHandler {
 	return &IOWriterHandler{
@@ -28,6 +28,20 @@ func TestNewIOWriter(t *testing.T) {
 	assert.NoErr(t, h.Close())
 }
 
+func TestNewIOWriterHandler_flushClose(t *testing.T) {
+	w := new(closeBuffer)
+	h := handler.NewIOWriterHandler(w, slog.NormalLevels)
+
+	assert.NoErr(t, h.Handle(newLogRecord("test flush and close")))
+	assert.NoErr(t, h.Flush())
+	assert.True(t, w.flushed)
+	assert.False(t, w.closed)
+
+	assert.NoErr(t, h.Close())
+	assert.True(t, w.closed)
+	assert.Contains(t, w.String(), "test flush and close")
+}
+
 func TestNewSyncCloser(t *testing.T) {
 	logfile := "./testdata/sync_closer.log"
 
@@ -16,6 +16,19 @@ func TestNewLvFormatter(t *testing.T) {
 
 	lf.SetMaxLevel(slog.DebugLevel)
 	assert.True(t, lf.IsHandling(slog.DebugLevel))
+
+	lf.SetLevel(slog.WarnLevel)
+	assert.Eq(t, slog.WarnLevel, lf.GetLevel())
+	assert.False(t, lf.IsHandling(slog.InfoLevel))
+
+	// use levels
+	lf.SetLevels([]slog.Level{slog.InfoLevel})
+	assert.True(t, lf.IsHandling(slog.InfoLevel))
+	assert.False(t, lf.IsHandling(slog.ErrorLevel))
+
+	lf.SetMinLevel(slog.ErrorLevel)
+	assert.True(t, lf.IsHandling(slog.ErrorLevel))
+	assert.False(t, lf.IsHandling(slog.InfoLevel))
 }
 
 func TestNewLvsFormatter(t *testing.T) {
@@ -25,6 +38,25 @@ func TestNewLvsFormatter(t *testing.T) {
 
 	lf.SetLimitLevels([]slog.Level{slog.InfoLevel, slog.ErrorLevel, slog.DebugLevel})
 	assert.True(t, lf.IsHandling(slog.DebugLevel))
+
+	lf.SetMinLevel(slog.WarnLevel)
+	assert.Eq(t, []slog.Level{slog.PanicLevel, slog.FatalLevel, slog.ErrorLevel, slog.WarnLevel}, lf.GetLevels())
+	assert.False(t, lf.IsHandling(slog.InfoLevel))
+
+	// concurrent set and check
+	lf.SetLevels([]slog.Level{slog.InfoLevel})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			lf.SetLevels([]slog.Level{slog.InfoLevel})
+			lf.SetMinLevel(slog.DebugLevel)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		assert.True(t, lf.IsHandling(slog.InfoLevel))
+	}
+	<-done
 }
 
 func TestLevelFormatting(t *testing.T) {
@@ -0,0 +1,52 @@
+package slog
+
+import "errors"
+
+// ErrSkipHandle can be returned by the Hook.Fire() for skip the handlers of the record.
+var ErrSkipHandle = errors.New("slog: skip handle the record")
+
+// Hook interface definition. the hooks are fired before the processors and handlers.
+//
+// Use cases: count the records per level, mutate the record, or skip the handlers by return ErrSkipHandle.
+type Hook interface {
+	// Levels the hook fired on. empty is fired on all levels.
+	Levels() []Level
+	// Fire the hook. return ErrSkipHandle will skip the subsequent hooks and handlers.
+	//
+	// NOTICE: it is called in the logger lock, must not write logs by the same logger.
+	Fire(r *Record) error
+}
+
+// HookFunc wrap a func as Hook
+type HookFunc struct {
+	levels []Level
+	fn     func(r *Record) error
+}
+
+// NewHook create a Hook by the func and levels. empty levels is fired on all levels.
+func NewHook(fn func(r *Record) error, levels ...Level) *HookFunc {
+	return &HookFunc{levels: levels, fn: fn}
+}
+
+// Levels the hook fired on
+func (h *HookFunc) Levels() []Level { return h.levels }
+
+// Fire the hook
+func (h *HookFunc) Fire(r *Record) error { return h.fn(r) }
+
+// fire the hooks by level. returns false on the handlers should be skipped.
+func (l *Logger) fireHooks(r *Record) bool {
+	for _, hook := range l.hooks {
+		if levels := hook.Levels(); len(levels) > 0 && !Levels(levels).Contains(r.Level) {
+			continue
+		}
+
+		if err := hook.Fire(r); err != nil {
+			if errors.Is(err, ErrSkipHandle) {
+				return false
+			}
+			l.handleError(err, r)
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+// Package levelhttp provide a http.Handler for get/change the log level on runtime.
+package levelhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gookit/slog"
+)
+
+// LevelAccessor can get and set the log level on runtime.
+//
+// eg: *slog.Logger, *slog.SugaredLogger, *slog.LevelWithFormatter
+type LevelAccessor interface {
+	GetLevel() slog.Level
+	SetLevel(level slog.Level)
+}
+
+// levelBody for request and response
+type levelBody struct {
+	Level string `json:"level"`
+}
+
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// Handler for get and change the log level.
+//
+//   - GET: returns current level. eg: {"level":"info"}
+//   - PUT: change the level. the body can be JSON `{"level":"debug"}` or plain text `debug`
+type Handler struct {
+	lv LevelAccessor
+}
+
+// NewHandler create new Handler
+//
+// Usage:
+//
+//	http.Handle("/loglevel", levelhttp.NewHandler(logger))
+func NewHandler(lv LevelAccessor) *Handler {
+	return &Handler{lv: lv}
+}
+
+// ServeHTTP implements the http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, levelBody{Level: h.lv.GetLevel().LowerName()})
+	case http.MethodPut:
+		name, err := readLevelName(r.Body)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorBody{Error: err.Error()})
+			return
+		}
+
+		level, err := slog.Name2Level(name)
+		if err != nil || name == "" {
+			writeJSON(w, http.StatusBadRequest, errorBody{Error: "invalid log level: " + name})
+			return
+		}
+
+		h.lv.SetLevel(level)
+		writeJSON(w, http.StatusOK, levelBody{Level: level.LowerName()})
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeJSON(w, http.StatusMethodNotAllowed, errorBody{Error: "only support GET and PUT"})
+	}
+}
+
+func readLevelName(body io.Reader) (string, error) {
+	bs, err := io.ReadAll(io.LimitReader(body, 1024))
+	if err != nil {
+		return "", err
+	}
+
+	bs = bytes.TrimSpace(bs)
+	if len(bs) > 0 && bs[0] == '{' {
+		var lb levelBody
+		if err = json.Unmarshal(bs, &lb); err != nil {
+			return "", err
+		}
+		return lb.Level, nil
+	}
+	return string(bs), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
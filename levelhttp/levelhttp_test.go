@@ -0,0 +1,63 @@
+package levelhttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/levelhttp"
+)
+
+func doRequest(h http.Handler, method, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/loglevel", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	l := slog.New()
+	h := levelhttp.NewHandler(l)
+
+	w := doRequest(h, http.MethodGet, "")
+	assert.Eq(t, http.StatusOK, w.Code)
+	assert.Eq(t, `{"level":"trace"}`, strings.TrimSpace(w.Body.String()))
+
+	// json body
+	w = doRequest(h, http.MethodPut, `{"level":"warn"}`)
+	assert.Eq(t, http.StatusOK, w.Code)
+	assert.Eq(t, `{"level":"warn"}`, strings.TrimSpace(w.Body.String()))
+	assert.Eq(t, slog.WarnLevel, l.GetLevel())
+
+	// text body
+	w = doRequest(h, http.MethodPut, "debug\n")
+	assert.Eq(t, http.StatusOK, w.Code)
+	assert.Eq(t, slog.DebugLevel, l.GetLevel())
+
+	// invalid
+	w = doRequest(h, http.MethodPut, "unknown")
+	assert.Eq(t, http.StatusBadRequest, w.Code)
+	assert.StrContains(t, w.Body.String(), "invalid log level")
+	w = doRequest(h, http.MethodPut, `{"level":`)
+	assert.Eq(t, http.StatusBadRequest, w.Code)
+	w = doRequest(h, http.MethodPut, "")
+	assert.Eq(t, http.StatusBadRequest, w.Code)
+	assert.Eq(t, slog.DebugLevel, l.GetLevel())
+
+	w = doRequest(h, http.MethodPost, "info")
+	assert.Eq(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Eq(t, "GET, PUT", w.Header().Get("Allow"))
+}
+
+func TestHandler_handlerLevel(t *testing.T) {
+	lf := slog.NewLvFormatter(slog.InfoLevel)
+	h := levelhttp.NewHandler(lf)
+
+	w := doRequest(h, http.MethodPut, "error")
+	assert.Eq(t, http.StatusOK, w.Code)
+	assert.False(t, lf.IsHandling(slog.WarnLevel))
+	assert.True(t, lf.IsHandling(slog.ErrorLevel))
+}
@@ -2,10 +2,13 @@ package slog
 
 import (
 	"context"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gookit/goutil"
+	"github.com/gookit/goutil/strutil"
 )
 
 // Logger log dispatcher definition.
@@ -20,9 +23,21 @@ type Logger struct {
 	// mark logger is closed
 	closed bool
 
-	// log handlers for logger
-	handlers   []Handler
+	// max level for the logger. 0 is not limited. see SetLevel()
+	level uint32
+
+	// log handlers for logger, sorted by the priorities
+	handlers []Handler
+	// priorities of the handlers, the same length as handlers. see AddHandlerWithPriority()
+	priorities []int
+	// processors run in the registration order. see AddProcessor()
 	processors []Processor
+	// hooks fired before the processors and handlers
+	hooks []Hook
+	// handlers for the channel. key is channel name
+	channelHandlers map[string][]Handler
+	// default formatter for the handlers without formatter. see SetFormatter()
+	formatter Formatter
 
 	// reusable empty record
 	recordPool sync.Pool
@@ -42,15 +57,59 @@ type Logger struct {
 	LowerLevelName bool
 	// ReportCaller on write log record
 	ReportCaller bool
-	CallerSkip   int
-	CallerFlag   uint8
+	// CallerSkip the frames number to skip on resolve the caller. default is 6, the frames are:
+	//
+	//	runtime.Callers, getCaller, Record.beforeHandle, Logger.writeRecord, Record.log/logf, Record.Info
+	//
+	// the internal frames(eg: Logger.handleRecord) are skipped automatically, not need count them.
+	//
+	// the Logger methods(eg: Logger.Info) add 1 more frame(Logger.log/logf) automatically.
+	// use SetCallerSkip() for log through the wrapper funcs.
+	CallerSkip int
+	// CallerFlag the caller format on ReportCaller=true. default is CallerFlagFnlFcn
+	//
+	// see CallerFlagFull, CallerFlagFunc, CallerFlagPkgFile and more.
+	CallerFlag uint8
 	// BackupArgs backup log input args to Record.Args
 	BackupArgs bool
+	// MsgtMissingMark render the missing placeholder as "<name>" in Record.Msgt().
+	//
+	// default is render it literally. eg: "{name}"
+	MsgtMissingMark bool
 	// TimeClock custom time clock, timezone
 	TimeClock ClockFn
+	// TimeLocation convert the record time to the location. default is nil, keep local time.
+	//
+	// eg: time.UTC for render the UTC time in formatters.
+	TimeLocation *time.Location
+	// ErrorHandler will be called when a handler returns error on Handle(), or a hook returns error on Fire().
+	// the other handlers still receive the record. default is print the error to stderr.
+	//
+	// eg: increment the metrics, write the record to a fallback.
+	//
+	// NOTICE: it is called in the logger lock, must not write logs by the same logger.
+	ErrorHandler func(err error, r *Record)
+	// RecoverHandlers recover the panic in the handler Handle(), eg: by a buggy formatter or sink.
+	// the panic is reported as an error to the ErrorHandler, and the other handlers still run.
+	//
+	// default is true, set false to let the panic crash the app.
+	RecoverHandlers bool
 	// custom exit, panic handler.
+	//
+	// ExitFunc will be called after Fatal level record written, default is os.Exit.
+	// PanicFunc will be called with the *Record after Panic level record written, default is panic with the message.
+	//
+	// Tip: use DoNothingOnPanicFatal() to disable them on testing.
 	ExitFunc  func(code int)
 	PanicFunc func(v any)
+	// ExitCode the exit code on the Fatal level record written. default is 1
+	ExitCode int
+	// ExitCodes custom the exit code by level, takes precedence over ExitCode.
+	//
+	// if the PanicLevel is set, the Panic level record will exit with the code instead of calling the PanicFunc.
+	//
+	// eg: {slog.FatalLevel: 3, slog.PanicLevel: 4}
+	ExitCodes map[Level]int
 }
 
 // New create a new logger
@@ -58,6 +117,14 @@ func New(fns ...LoggerFn) *Logger {
 	return NewWithName("logger", fns...)
 }
 
+// NewNopLogger create a no-op logger, it has no handlers and discards all logs.
+// useful for disable logging, and the downstream code can avoid the nil check.
+//
+// NOTICE: the Panic, Fatal level logs still call the PanicFunc, ExitFunc.
+func NewNopLogger() *Logger {
+	return NewWithName("nopLogger")
+}
+
 // NewWithHandlers create a new logger with handlers
 func NewWithHandlers(hs ...Handler) *Logger {
 	logger := NewWithName("logger")
@@ -70,19 +137,29 @@ func NewWithConfig(fns ...LoggerFn) *Logger {
 	return NewWithName("logger", fns...)
 }
 
+// the default Logger.CallerSkip, the frames number from runtime.Callers to the caller of Record.Info
+const defaultCallerSkip = 6
+
+// the internal frames between Logger.writeRecord and Record.beforeHandle(Logger.handleRecord).
+// they are not counted in Logger.CallerSkip, keep the value compatible with the older versions.
+const internalCallerSkip = 1
+
 // NewWithName create a new logger with name
 func NewWithName(name string, fns ...LoggerFn) *Logger {
 	logger := &Logger{
 		name: name,
 		// exit handle
-		// ExitFunc:  os.Exit,
+		ExitFunc:     DefaultExitFn,
 		PanicFunc:    DefaultPanicFn,
+		ExitCode:     1,
 		exitHandlers: []func(){},
 		// options
 		ChannelName:  DefaultChannelName,
 		ReportCaller: true,
-		CallerSkip:   6,
-		TimeClock:    DefaultClockFn,
+		CallerSkip:   defaultCallerSkip,
+		// recover the handler panic
+		RecoverHandlers: true,
+		TimeClock:       DefaultClockFn,
 		// flush interval time
 		FlushInterval: defaultFlushInterval,
 	}
@@ -97,25 +174,36 @@ func NewWithName(name string, fns ...LoggerFn) *Logger {
 func (l *Logger) newRecord() *Record {
 	r := l.recordPool.Get().(*Record)
 	r.freed = false
-	r.Fields = nil
+	r.CallerSkip = l.CallerSkip
+	// reset the values that kept after release
+	r.Ctx = nil
+	r.Fields, r.groups = nil, nil
+	r.Fmt, r.Args = "", nil
 	return r
 }
 
+// release the record to pool after all handlers finished.
+//
+// NOTICE: the handler that retains the record after Handle() returned(eg: AsyncHandler),
+// must take a copy of it by Record.Clone().
 func (l *Logger) releaseRecord(r *Record) {
 	if r.reuse || r.freed {
 		return
 	}
 
-	// reset data
+	// reset data. NOTICE: dont clear the maps, they may be provided by the user.
 	r.Time = emptyTime
-	r.Data = map[string]any{}
+	r.Data = nil
 	r.Extra = nil
+	r.Caller = nil
 	// reset flags
 	r.inited = false
+	r.tplMsg, r.frozen = false, false
 	r.reuse = false
 	r.freed = true
 
 	r.Message = ""
+	r.Channel = strutil.OrElse(l.ChannelName, DefaultChannelName)
 	r.CallerSkip = l.CallerSkip
 	l.recordPool.Put(r)
 }
@@ -160,9 +248,69 @@ func (l *Logger) ExitHandlers() []func() {
 // SetName for logger
 func (l *Logger) SetName(name string) { l.name = name }
 
+// SetCallerSkip set the extra frames number to skip on resolve the caller.
+//
+// Useful for log through the wrapper funcs, the n is the wrapper depth. eg:
+//
+//	logger.SetCallerSkip(1)
+//
+//	func logInfo(msg string) {
+//		logger.Info(msg) // the caller will be the caller of logInfo()
+//	}
+func (l *Logger) SetCallerSkip(n int) { l.CallerSkip = defaultCallerSkip + n }
+
+// UseUTC render the record time as UTC time. alias of set Logger.TimeLocation = time.UTC
+func (l *Logger) UseUTC() { l.TimeLocation = time.UTC }
+
 // Name of the logger
 func (l *Logger) Name() string { return l.name }
 
+// SetLevel set the max level for the logger, the records with higher level
+// will be discarded before dispatch to handlers. it is safe for concurrent use.
+func (l *Logger) SetLevel(level Level) {
+	atomic.StoreUint32(&l.level, uint32(level))
+}
+
+// GetLevel get the max level for the logger. if not set, will return TraceLevel
+func (l *Logger) GetLevel() Level {
+	if lv := atomic.LoadUint32(&l.level); lv > 0 {
+		return Level(lv)
+	}
+	return TraceLevel
+}
+
+// check the level is enabled by the logger level.
+func (l *Logger) levelEnabled(level Level) bool {
+	lv := atomic.LoadUint32(&l.level)
+	return lv == 0 || Level(lv).ShouldHandling(level)
+}
+
+// IsHandling check the level is enabled, and any handler(include the channel handlers) will handle it.
+func (l *Logger) IsHandling(level Level) bool {
+	if !l.levelEnabled(level) {
+		return false
+	}
+
+	for _, h := range l.handlers {
+		if h.IsHandling(level) {
+			return true
+		}
+	}
+	for _, hs := range l.channelHandlers {
+		for _, h := range hs {
+			if h.IsHandling(level) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// check the record with level should be written. the hooks also need the record.
+func (l *Logger) shouldWrite(level Level) bool {
+	return len(l.hooks) > 0 || l.IsHandling(level)
+}
+
 //
 // ---------------------------------------------------------------------------
 // Management logger
@@ -291,7 +439,7 @@ func (l *Logger) Close() error {
 	return l.err
 }
 
-// VisitAll logger handlers
+// VisitAll logger handlers, include the channel handlers
 func (l *Logger) VisitAll(fn func(handler Handler) error) error {
 	for _, handler := range l.handlers {
 		// TIP: you can return nil for ignore error
@@ -299,14 +447,28 @@ func (l *Logger) VisitAll(fn func(handler Handler) error) error {
 			return err
 		}
 	}
+
+	for _, hs := range l.channelHandlers {
+		for _, handler := range hs {
+			if err := fn(handler); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
-// Reset the logger. will reset: handlers, processors, closed=false
+// Reset the logger. will reset: handlers, processors, hooks, closed=false
 func (l *Logger) Reset() {
 	l.closed = false
 	l.ResetHandlers()
 	l.ResetProcessors()
+	l.ResetHooks()
+}
+
+// ResetHooks for the logger
+func (l *Logger) ResetHooks() {
+	l.hooks = nil
 }
 
 // ResetProcessors for the logger
@@ -314,13 +476,18 @@ func (l *Logger) ResetProcessors() {
 	l.processors = make([]Processor, 0)
 }
 
-// ResetHandlers for the logger
+// ResetHandlers for the logger, include the channel handlers
 func (l *Logger) ResetHandlers() {
 	l.handlers = make([]Handler, 0)
+	l.priorities = nil
+	l.channelHandlers = nil
 }
 
-// Exit logger handle
+// Exit logger handle. will flush all handlers, run exit handlers, then call the ExitFunc.
 func (l *Logger) Exit(code int) {
+	if err := l.lockAndFlushAll(); err != nil {
+		printlnStderr("slog: flush logs on exit error:", err)
+	}
 	l.runExitHandlers()
 
 	// global exit handlers
@@ -376,17 +543,114 @@ func (l *Logger) AddHandlers(hs ...Handler) { l.PushHandlers(hs...) }
 // PushHandler to the l. alias of AddHandler()
 func (l *Logger) PushHandler(h Handler) { l.PushHandlers(h) }
 
-// PushHandlers to the logger
+// PushHandlers to the logger, the priority is 0. see AddHandlerWithPriority()
 func (l *Logger) PushHandlers(hs ...Handler) {
 	if len(hs) > 0 {
-		l.handlers = append(l.handlers, hs...)
+		l.inheritFormatter(hs...)
+		for _, h := range hs {
+			l.insertHandler(h, 0)
+		}
 	}
 }
 
-// SetHandlers for the logger
-func (l *Logger) SetHandlers(hs []Handler) { l.handlers = hs }
+// AddHandlerWithPriority add handler with the priority. the handlers are run in the priority order,
+// lower number is run earlier, the handlers with equal priority run in the order added.
+//
+// the handlers added by AddHandler(), PushHandlers() have priority 0.
+// eg: run the fast local handler before the slow network handler:
+//
+//	l.AddHandlerWithPriority(fileHandler, -10)
+//	l.AddHandlerWithPriority(networkHandler, 10)
+//
+// NOTICE: the channel handlers are always run before the global handlers. see AddHandlerForChannel()
+func (l *Logger) AddHandlerWithPriority(h Handler, priority int) {
+	l.inheritFormatter(h)
+	l.insertHandler(h, priority)
+}
+
+// insert the handler after the handlers with priority <= the priority, keep the handlers sorted.
+func (l *Logger) insertHandler(h Handler, priority int) {
+	// the handlers set by SetHandlers() have priority 0
+	if len(l.priorities) != len(l.handlers) {
+		l.priorities = make([]int, len(l.handlers))
+	}
+
+	idx := sort.Search(len(l.priorities), func(i int) bool {
+		return l.priorities[i] > priority
+	})
 
-// AddProcessor to the logger
+	l.handlers = append(l.handlers, nil)
+	copy(l.handlers[idx+1:], l.handlers[idx:])
+	l.handlers[idx] = h
+
+	l.priorities = append(l.priorities, 0)
+	copy(l.priorities[idx+1:], l.priorities[idx:])
+	l.priorities[idx] = priority
+}
+
+// SetHandlers for the logger, the priorities of the handlers are reset to 0.
+func (l *Logger) SetHandlers(hs []Handler) {
+	l.inheritFormatter(hs...)
+	l.handlers = hs
+	l.priorities = make([]int, len(hs))
+}
+
+// SetFormatter set the default formatter for the handlers, include the handlers added later.
+//
+// only the handlers implements FormatterInheritor and without explicit formatter will use it,
+// the formatter set by handler SetFormatter() takes precedence.
+func (l *Logger) SetFormatter(f Formatter) {
+	l.formatter = f
+	_ = l.VisitAll(func(h Handler) error {
+		l.inheritFormatter(h)
+		return nil
+	})
+}
+
+// DefaultFormatter get the default formatter for the handlers. see SetFormatter()
+func (l *Logger) DefaultFormatter() Formatter { return l.formatter }
+
+// apply the default formatter to the handlers, and the inner handlers of the wrapper handler.
+func (l *Logger) inheritFormatter(hs ...Handler) {
+	if l.formatter == nil {
+		return
+	}
+
+	for _, h := range hs {
+		if fi, ok := h.(FormatterInheritor); ok {
+			fi.InheritFormatter(l.formatter)
+		}
+
+		switch wh := h.(type) {
+		case interface{ Handler() Handler }:
+			l.inheritFormatter(wh.Handler())
+		case interface{ Handlers() []Handler }:
+			l.inheritFormatter(wh.Handlers()...)
+		}
+	}
+}
+
+// AddHandlerForChannel add handler for the channel.
+// the records with the channel will be handled by it, then the global handlers.
+//
+// NOTICE: should not add the handler to global handlers at the same time, it will be flushed and closed twice.
+func (l *Logger) AddHandlerForChannel(channel string, h Handler) {
+	if l.channelHandlers == nil {
+		l.channelHandlers = make(map[string][]Handler)
+	}
+	l.inheritFormatter(h)
+	l.channelHandlers[channel] = append(l.channelHandlers[channel], h)
+}
+
+// AddProcessor to the logger.
+//
+// The processors run in the registration order, once for each record:
+//   - after the record is built and the hooks are fired
+//   - only if the record level is enabled and at least one handler will handle it
+//   - before the handlers, the handlers receive the processed record
+//
+// Use PrependProcessor() or SetProcessors() to control the order.
+// eg: the context extraction must run before the masking.
 func (l *Logger) AddProcessor(p Processor) { l.processors = append(l.processors, p) }
 
 // PushProcessor to the logger, alias of AddProcessor()
@@ -395,8 +659,22 @@ func (l *Logger) PushProcessor(p Processor) { l.processors = append(l.processors
 // AddProcessors to the logger. alias of AddProcessor()
 func (l *Logger) AddProcessors(ps ...Processor) { l.processors = append(l.processors, ps...) }
 
-// SetProcessors for the logger
-func (l *Logger) SetProcessors(ps []Processor) { l.processors = ps }
+// PrependProcessor add the processors to the front, they run before the registered processors.
+func (l *Logger) PrependProcessor(ps ...Processor) {
+	l.processors = append(append(make([]Processor, 0, len(ps)+len(l.processors)), ps...), l.processors...)
+}
+
+// SetProcessors replace the processors of the logger, they run in the order of the list.
+func (l *Logger) SetProcessors(ps []Processor) { l.processors = append([]Processor(nil), ps...) }
+
+// Processors get a copy of the processors, in the running order.
+func (l *Logger) Processors() []Processor { return append([]Processor(nil), l.processors...) }
+
+// AddHook to the logger. the hooks are fired before the processors and handlers. see Hook
+func (l *Logger) AddHook(h Hook) { l.hooks = append(l.hooks, h) }
+
+// AddHooks to the logger
+func (l *Logger) AddHooks(hs ...Hook) { l.hooks = append(l.hooks, hs...) }
 
 //
 // ---------------------------------------------------------------------------
@@ -432,14 +710,46 @@ func (l *Logger) WithField(name string, value any) *Record {
 	return r.WithField(name, value)
 }
 
+// Timed start a timer, returns a func to log the elapsed time. see Record.Timed
+func (l *Logger) Timed(key string, level ...Level) func(args ...any) {
+	return l.newRecord().Timed(key, level...)
+}
+
 // WithFields new record with fields
 //
 // TIP: add field need config Formatter template fields.
+// the returned record is released after one log call, use NewEntry() for a reusable child logger.
 func (l *Logger) WithFields(fields M) *Record {
 	r := l.newRecord()
 	return r.WithFields(fields)
 }
 
+// With new record with typed fields. see Field
+//
+// TIP: add field need config Formatter template fields.
+func (l *Logger) With(fields ...Field) *Record {
+	r := l.newRecord()
+	return r.With(fields...)
+}
+
+// Msgt new record with the message template. see Record.Msgt()
+func (l *Logger) Msgt(template string, args M) *Record {
+	r := l.newRecord()
+	return r.Msgt(template, args)
+}
+
+// WithGroup new record, the subsequent fields will be nested under the group name.
+func (l *Logger) WithGroup(name string) *Record {
+	r := l.newRecord()
+	return r.WithGroup(name)
+}
+
+// WithChannel new record with the channel name
+func (l *Logger) WithChannel(name string) *Record {
+	r := l.newRecord()
+	return r.WithChannel(name)
+}
+
 // WithData new record with data
 func (l *Logger) WithData(data M) *Record {
 	r := l.newRecord()
@@ -458,6 +768,13 @@ func (l *Logger) WithExtra(ext M) *Record {
 	return r.SetExtra(ext)
 }
 
+// WithSampleKey new record with the sampling key. see Record.WithSampleKey
+func (l *Logger) WithSampleKey(key string) *Record {
+	r := l.newRecord()
+	r.SetExtraValue(FieldKeySampleKey, key)
+	return r
+}
+
 // WithTime new record with time.Time
 func (l *Logger) WithTime(t time.Time) *Record {
 	r := l.newRecord()
@@ -480,6 +797,11 @@ func (l *Logger) WithContext(ctx context.Context) *Record {
 //
 
 func (l *Logger) log(level Level, args []any) {
+	// short-circuit before get record from pool. see Logger.IsHandling()
+	if level > FatalLevel && !l.shouldWrite(level) {
+		return
+	}
+
 	r := l.newRecord()
 	r.CallerSkip++
 	r.log(level, args)
@@ -487,6 +809,10 @@ func (l *Logger) log(level Level, args []any) {
 
 // Logf a format message with level
 func (l *Logger) logf(level Level, format string, args []any) {
+	if level > FatalLevel && !l.shouldWrite(level) {
+		return
+	}
+
 	r := l.newRecord()
 	r.CallerSkip++
 	r.logf(level, format, args)
@@ -495,6 +821,11 @@ func (l *Logger) logf(level Level, format string, args []any) {
 // Log a message with level
 func (l *Logger) Log(level Level, args ...any) { l.log(level, args) }
 
+// LogBatch write each message as a record with level. see Record.LogAll
+func (l *Logger) LogBatch(level Level, msgs []string) {
+	l.newRecord().logAll(level, msgs)
+}
+
 // Logf a format message with level
 func (l *Logger) Logf(level Level, format string, args ...any) {
 	l.logf(level, format, args)
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -53,6 +56,40 @@ func TestLogger_PushHandler(t *testing.T) {
 	l.Reset()
 }
 
+func TestLogger_AddHandlerWithPriority(t *testing.T) {
+	var order []string
+	newHandler := func(name string) slog.Handler {
+		return handler.NewFilterHandler(func(r *slog.Record) bool {
+			order = append(order, name)
+			return true
+		}, newTestHandler())
+	}
+
+	l := slog.NewWithHandlers(newHandler("h1"))
+	l.AddHandlerWithPriority(newHandler("network"), 10)
+	l.AddHandlerWithPriority(newHandler("local"), -10)
+	l.AddHandler(newHandler("h2"))
+	l.AddHandlerWithPriority(newHandler("network2"), 10)
+	l.AddHandlerWithPriority(newHandler("local2"), -10)
+	assert.Eq(t, 6, l.HandlersNum())
+
+	l.Info("message")
+	assert.Eq(t, []string{"local", "local2", "h1", "h2", "network", "network2"}, order)
+
+	// SetHandlers reset the priorities to 0
+	order = order[:0]
+	l.SetHandlers([]slog.Handler{newHandler("h1"), newHandler("h2")})
+	l.AddHandlerWithPriority(newHandler("first"), -1)
+	l.PushHandler(newHandler("h3"))
+	l.Info("message")
+	assert.Eq(t, []string{"first", "h1", "h2", "h3"}, order)
+
+	l.ResetHandlers()
+	assert.Eq(t, 0, l.HandlersNum())
+	l.AddHandlerWithPriority(newHandler("h1"), 5)
+	assert.Eq(t, 1, l.HandlersNum())
+}
+
 func TestLogger_ReportCaller(t *testing.T) {
 	l := slog.NewWithConfig(func(logger *slog.Logger) {
 		logger.ReportCaller = true
@@ -145,10 +182,9 @@ func TestLogger_panicLevel(t *testing.T) {
 	w := new(bytes.Buffer)
 	l := slog.NewWithHandlers(handler.NewIOWriter(w, slog.AllLevels))
 
-	// assert.PanicsWithValue(t, "slog: panic message", func() {
-	assert.Panics(t, func() {
+	assert.PanicsMsg(t, func() {
 		l.Panicln("panicln message")
-	})
+	}, "panicln message")
 	assert.Contains(t, w.String(), "[PANIC]")
 	assert.Contains(t, w.String(), "panicln message")
 
@@ -167,6 +203,62 @@ func TestLogger_panicLevel(t *testing.T) {
 	assert.NoErr(t, l.FlushAll())
 }
 
+func TestLogger_fatalLevel(t *testing.T) {
+	var flushed []string
+	h := newTestHandler()
+	h.callOnFlush = func() {
+		flushed = append(flushed, h.String())
+	}
+
+	exitCode := 0
+	l := slog.NewWithHandlers(h)
+	l.ExitFunc = func(code int) {
+		exitCode = code
+		// can use the logger on exit
+		l.Info("on exit")
+	}
+	l.Fatal("fatal message")
+
+	assert.Eq(t, 1, exitCode)
+	assert.NotEmpty(t, flushed)
+	assert.StrContains(t, flushed[0], "fatal message")
+
+	// disable exit on testing
+	exitCode = 0
+	l.DoNothingOnPanicFatal()
+	l.Fatal("fatal message")
+	l.Panic("panic message")
+	assert.Eq(t, 0, exitCode)
+}
+
+func TestLogger_ExitCode(t *testing.T) {
+	var panicked any
+	exitCode := 0
+	l := slog.NewWithHandlers(newTestHandler())
+	l.ExitFunc = func(code int) { exitCode = code }
+	l.PanicFunc = func(v any) { panicked = v }
+
+	l.ExitCode = 3
+	l.Fatal("fatal message")
+	assert.Eq(t, 3, exitCode)
+
+	// panic is not affected by ExitCode
+	l.Panic("panic message")
+	pr, ok := panicked.(*slog.Record)
+	assert.True(t, ok)
+	assert.Eq(t, "panic message", pr.Message)
+	assert.Eq(t, slog.PanicLevel, pr.Level)
+
+	// by level
+	panicked = nil
+	l.ExitCodes = map[slog.Level]int{slog.FatalLevel: 5, slog.PanicLevel: 6}
+	l.Fatal("fatal message")
+	assert.Eq(t, 5, exitCode)
+	l.Panic("panic message")
+	assert.Eq(t, 6, exitCode)
+	assert.Nil(t, panicked)
+}
+
 func TestLogger_log_allLevel(t *testing.T) {
 	l := slog.NewWithConfig(func(l *slog.Logger) {
 		l.ReportCaller = true
@@ -198,6 +290,20 @@ func TestLogger_write_error(t *testing.T) {
 	err := l.LastErr()
 	assert.Err(t, err)
 	assert.Eq(t, "handle error", err.Error())
+
+	t.Run("ErrorHandler", func(t *testing.T) {
+		h2 := newTestHandler()
+		var errs []string
+		l.AddHandler(h2)
+		l.ErrorHandler = func(err error, r *slog.Record) {
+			errs = append(errs, err.Error()+": "+r.Message)
+		}
+
+		l.Info("message2")
+		assert.Eq(t, []string{"handle error: message2"}, errs)
+		// other handlers still receive the record
+		assert.StrContains(t, h2.String(), "message2")
+	})
 }
 
 func TestLogger_option_BackupArgs(t *testing.T) {
@@ -279,3 +385,404 @@ func TestLogger_rewrite_record(t *testing.T) {
 		dump.P(h.ResetGet())
 	})
 }
+
+func TestLogger_SetLevel(t *testing.T) {
+	w := newBuffer()
+	h := handler.NewIOWriter(w, slog.AllLevels)
+	l := slog.NewWithHandlers(h)
+	assert.Eq(t, slog.TraceLevel, l.GetLevel())
+
+	l.SetLevel(slog.WarnLevel)
+	assert.Eq(t, slog.WarnLevel, l.GetLevel())
+
+	l.Info("info message")
+	l.Warn("warn message")
+	s := w.StringReset()
+	assert.NotContains(t, s, "info message")
+	assert.Contains(t, s, "warn message")
+
+	// change level on concurrent logging
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			l.Debug("debug message")
+		}()
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				l.SetLevel(slog.DebugLevel)
+			} else {
+				l.SetLevel(slog.InfoLevel)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	l.SetLevel(slog.DebugLevel)
+	l.Debug("debug message")
+	assert.Contains(t, w.StringReset(), "debug message")
+}
+
+func TestLogger_AddHandlerForChannel(t *testing.T) {
+	global := newTestHandler()
+	order := newTestHandler()
+	order.SetFormatter(newTestFormatter())
+	user := newTestHandler()
+	user.SetFormatter(newTestFormatter())
+
+	l := slog.NewWithHandlers(global)
+	l.AddHandlerForChannel("order", order)
+	l.AddHandlerForChannel("user", user)
+
+	r := l.Record()
+	r.Channel = "order"
+	r.Info("order message")
+
+	r = l.Record()
+	r.Channel = "user"
+	r.Info("user message")
+	l.Info("default message")
+
+	assert.Eq(t, "order message", order.String())
+	assert.Eq(t, "user message", user.String())
+	str := global.String()
+	assert.StrContains(t, str, "order message")
+	assert.StrContains(t, str, "user message")
+	assert.StrContains(t, str, "[application] [INFO]")
+
+	// flush and close channel handlers
+	assert.NoErr(t, l.Flush())
+	assert.Empty(t, order.String())
+	user.errOnClose = true
+	assert.Err(t, l.Close())
+
+	l.ResetHandlers()
+	assert.Eq(t, 0, l.HandlersNum())
+}
+
+func TestLogger_SetFormatter(t *testing.T) {
+	noFmt := newTestHandler()
+	explicit := newTestHandler()
+	explicit.SetFormatter(newTestFormatter())
+
+	l := slog.NewWithHandlers(noFmt, explicit)
+	l.DoNothingOnPanicFatal()
+	assert.Nil(t, l.DefaultFormatter())
+
+	jf := slog.NewJSONFormatter()
+	l.SetFormatter(jf)
+	assert.Eq(t, jf, l.DefaultFormatter())
+	assert.Eq(t, jf, noFmt.Formatter())
+
+	// added later and in the wrapper handler
+	w := newBuffer()
+	ioh := handler.NewIOWriter(w, slog.AllLevels)
+	l.AddHandler(handler.NewFilterHandler(func(r *slog.Record) bool { return true }, ioh))
+
+	l.Info("info message")
+	assert.StrContains(t, noFmt.ResetGet(), `"message":"info message"`)
+	assert.Eq(t, "info message", explicit.ResetGet())
+	assert.StrContains(t, w.StringReset(), `"message":"info message"`)
+
+	// replace the inherited formatter
+	l.SetFormatter(slog.NewTextFormatter("{{message}}\n"))
+	l.Info("text message")
+	assert.Eq(t, "text message\n", noFmt.ResetGet())
+	assert.Eq(t, "text message\n", w.StringReset())
+}
+
+func TestNewNopLogger(t *testing.T) {
+	l := slog.NewNopLogger()
+	assert.False(t, l.IsHandling(slog.ErrorLevel))
+	assert.False(t, l.IsHandling(slog.DebugLevel))
+
+	var called int
+	l.AddProcessor(slog.ProcessorFunc(func(r *slog.Record) {
+		called++
+	}))
+	l.Info("message")
+	l.Errorf("error %s", "message")
+	l.WithField("key", "val").Warn("message")
+	assert.Eq(t, 0, called)
+	assert.NoErr(t, l.Close())
+
+	// short-circuit on no handler will handle the level
+	w := newBuffer()
+	l = slog.NewWithHandlers(handler.NewIOWriter(w, []slog.Level{slog.ErrorLevel}))
+	l.AddProcessor(slog.ProcessorFunc(func(r *slog.Record) {
+		called++
+	}))
+	assert.True(t, l.IsHandling(slog.ErrorLevel))
+	assert.False(t, l.IsHandling(slog.InfoLevel))
+
+	l.Info("info message")
+	assert.Eq(t, 0, called)
+	l.Error("error message")
+	assert.Eq(t, 1, called)
+	assert.StrContains(t, w.StringReset(), "error message")
+
+	// the channel handlers
+	l.AddHandlerForChannel("order", newTestHandler())
+	assert.True(t, l.IsHandling(slog.InfoLevel))
+	l.SetLevel(slog.WarnLevel)
+	assert.False(t, l.IsHandling(slog.InfoLevel))
+}
+
+type countStringer struct{ n int }
+
+func (s *countStringer) String() string {
+	s.n++
+	return "stringer"
+}
+
+func TestLogger_IsHandling_skipFormat(t *testing.T) {
+	w := newBuffer()
+	l := slog.NewWithHandlers(handler.NewIOWriter(w, []slog.Level{slog.InfoLevel}))
+	l.DoNothingOnPanicFatal()
+
+	cs := &countStringer{}
+	l.Debug("debug", cs)
+	l.Debugf("debug %s", cs)
+	l.WithField("key", "val").Debugf("debug %s", cs)
+	l.Build(slog.DebugLevel).Str("key", "val").Msgf("debug %s", cs)
+	assert.Eq(t, 0, cs.n)
+	assert.Empty(t, w.StringReset())
+
+	l.Infof("info %s", cs)
+	assert.Eq(t, 1, cs.n)
+	assert.StrContains(t, w.StringReset(), "info stringer")
+
+	// the panic, fatal level always be formatted
+	l.Fatalf("fatal %s", cs)
+	assert.Eq(t, 2, cs.n)
+}
+
+func TestLogger_RecoverHandlers(t *testing.T) {
+	bad := newTestHandler()
+	bad.SetFormatter(slog.FormatterFunc(func(r *slog.Record) ([]byte, error) {
+		panic("formatter is broken")
+	}))
+	good1, good2 := newTestHandler(), newTestHandler()
+
+	var errs []error
+	l := slog.NewWithHandlers(good1, bad, good2)
+	l.ErrorHandler = func(err error, r *slog.Record) {
+		errs = append(errs, err)
+	}
+	assert.True(t, l.RecoverHandlers)
+
+	l.Info("info message")
+	assert.StrContains(t, good1.ResetGet(), "info message")
+	assert.StrContains(t, good2.ResetGet(), "info message")
+	assert.Len(t, errs, 1)
+	assert.StrContains(t, errs[0].Error(), "formatter is broken")
+	assert.StrContains(t, errs[0].Error(), "slog: handler *slog_test.testHandler panic")
+
+	// disable recover
+	l.RecoverHandlers = false
+	assert.Panics(t, func() {
+		l.Info("info message")
+	})
+}
+
+func TestLogger_TimeLocation(t *testing.T) {
+	w := newBuffer()
+	h := handler.NewIOWriter(w, slog.AllLevels)
+	f := slog.NewTextFormatter("{{datetime}}\n")
+	f.TimeFormat = time.RFC3339
+	h.SetFormatter(f)
+
+	loc := time.FixedZone("UTC+8", 8*3600)
+	l := slog.NewWithHandlers(h)
+	l.TimeClock = func() time.Time {
+		return time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	}
+
+	l.Info("default")
+	assert.Eq(t, "2023-01-02T03:04:05Z\n", w.StringReset())
+
+	l.TimeLocation = loc
+	l.Info("in location")
+	assert.Eq(t, "2023-01-02T11:04:05+08:00\n", w.StringReset())
+
+	l.UseUTC()
+	r := l.Record()
+	r.Time = time.Date(2023, 1, 2, 11, 4, 5, 0, loc)
+	r.Info("custom time")
+	assert.Eq(t, "2023-01-02T03:04:05Z\n", w.StringReset())
+}
+
+// a wrapper func of the logger
+func logInfoWrapper(l *slog.Logger, msg string) {
+	l.Info(msg)
+}
+
+// a wrapper func of the record
+func recordInfoWrapper(r *slog.Record, msg string) {
+	r.WithCallerSkip(1).Info(msg)
+}
+
+func TestLogger_SetCallerSkip(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := slog.NewWithConfig(func(l *slog.Logger) {
+		l.ReportCaller = true
+		l.CallerFlag = slog.CallerFlagFunc
+	})
+	h := handler.NewIOWriterHandler(buf, slog.AllLevels)
+	h.SetFormatter(slog.NewTextFormatter("{{caller}}\n"))
+	l.AddHandler(h)
+
+	logInfoWrapper(l, "message")
+	assert.Eq(t, "github.com/gookit/slog_test.logInfoWrapper\n", buf.String())
+	buf.Reset()
+
+	l.SetCallerSkip(1)
+	logInfoWrapper(l, "message")
+	assert.Eq(t, "github.com/gookit/slog_test.TestLogger_SetCallerSkip\n", buf.String())
+	buf.Reset()
+
+	// direct call on record is not affected by the logger wrapper skip
+	l.SetCallerSkip(0)
+	l.WithField("key", "val").Info("message")
+	assert.Eq(t, "github.com/gookit/slog_test.TestLogger_SetCallerSkip\n", buf.String())
+	buf.Reset()
+
+	recordInfoWrapper(l.WithField("key", "val"), "message")
+	assert.Eq(t, "github.com/gookit/slog_test.TestLogger_SetCallerSkip\n", buf.String())
+	buf.Reset()
+
+	// set CallerSkip directly, same as the older versions: 6 + depth
+	l.CallerSkip = 6 + 1
+	logInfoWrapper(l, "message")
+	assert.Eq(t, "github.com/gookit/slog_test.TestLogger_SetCallerSkip\n", buf.String())
+}
+
+func TestLogger_AddHook(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h := handler.NewSimple(buf, slog.DebugLevel)
+	h.SetFormatter(slog.NewTextFormatter("{{level}} {{message}} {{user}}\n"))
+
+	l := slog.NewWithHandlers(h)
+	l.AddProcessor(slog.ProcessorFunc(func(r *slog.Record) {
+		r.AddField("user", "inhere")
+	}))
+
+	// count per level
+	counts := make(map[slog.Level]int)
+	l.AddHook(slog.NewHook(func(r *slog.Record) error {
+		counts[r.Level]++
+		return nil
+	}))
+	// mutate the record, only on warn level
+	l.AddHook(slog.NewHook(func(r *slog.Record) error {
+		r.Message = "[hooked] " + r.Message
+		return nil
+	}, slog.WarnLevel))
+	// skip the handlers
+	l.AddHook(slog.NewHook(func(r *slog.Record) error {
+		if r.Message == "secret" {
+			return slog.ErrSkipHandle
+		}
+		return nil
+	}))
+
+	l.Info("message1")
+	l.Warn("message2")
+	l.Info("secret")
+	l.Trace("not enabled")
+	assert.Eq(t, "INFO message1 inhere\nWARN [hooked] message2 inhere\n", buf.String())
+	assert.Eq(t, map[slog.Level]int{slog.InfoLevel: 2, slog.WarnLevel: 1, slog.TraceLevel: 1}, counts)
+
+	// hook error
+	var errs []string
+	l.ErrorHandler = func(err error, r *slog.Record) {
+		errs = append(errs, err.Error()+": "+r.Message)
+	}
+	l.AddHook(slog.NewHook(func(r *slog.Record) error {
+		return errorx.Raw("hook error")
+	}, slog.NoticeLevel))
+
+	buf.Reset()
+	l.Notice("message3")
+	assert.Eq(t, []string{"hook error: message3"}, errs)
+	// the handlers still receive the record
+	assert.Eq(t, "NOTICE message3 inhere\n", buf.String())
+
+	l.ResetHooks()
+	l.Info("secret")
+	assert.StrContains(t, buf.String(), "INFO secret")
+}
+
+type frozenCheckHandler struct {
+	handler.NopFlushClose
+	slog.Processable
+	frozen bool
+	out    string
+}
+
+func (h *frozenCheckHandler) IsHandling(_ slog.Level) bool { return true }
+
+func (h *frozenCheckHandler) Handle(r *slog.Record) error {
+	h.frozen = r.Frozen()
+	h.ProcessRecord(r)
+	h.out = fmt.Sprint(r.Field("hostname"))
+	return nil
+}
+
+func TestRecord_Frozen(t *testing.T) {
+	h := &frozenCheckHandler{}
+	h.AddProcessor(slog.AddHostname())
+
+	l := slog.NewWithHandlers(h)
+	l.RecoverHandlers = true
+	l.AddProcessor(slog.ProcessorFunc(func(r *slog.Record) {
+		// can modify in processor
+		assert.False(t, r.Frozen())
+		r.AddField("from", "processor")
+	}))
+
+	var handleErr error
+	l.ErrorHandler = func(err error, _ *slog.Record) { handleErr = err }
+
+	r := l.Record().Reused()
+	r.Info("message")
+	assert.True(t, h.frozen)
+	assert.NoErr(t, handleErr)
+	assert.Eq(t, "processor", r.Field("from"))
+
+	// handler level processors still work
+	hostname, _ := os.Hostname()
+	assert.Eq(t, hostname, h.out)
+
+	// not frozen after handled
+	assert.False(t, r.Frozen())
+	r.AddField("from", "caller")
+	r.Release()
+}
+
+func TestLogger_concurrentHandlers(t *testing.T) {
+	buf1, buf2 := newBuffer(), newBuffer()
+	h1 := handler.NewIOWriterHandler(buf1, slog.AllLevels)
+	h1.SetFormatter(slog.NewJSONFormatter())
+	h2 := handler.NewAsyncHandler(handler.NewIOWriterHandler(buf2, slog.AllLevels))
+	ms := handler.NewMetricsHandler(handler.MetricsCounterFunc(func(level, channel string) {}))
+
+	l := slog.NewWithHandlers(h1, h2, ms)
+	l.DoNothingOnPanicFatal()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				l.WithFields(slog.M{"goroutine": i, "n": j}).Info("concurrent message")
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.NoErr(t, l.Close())
+	assert.Eq(t, 400, strings.Count(buf2.String(), "concurrent message"))
+}
@@ -1,5 +1,7 @@
 package slog
 
+import "fmt"
+
 //
 // ---------------------------------------------------------------------------
 // Do write log message
@@ -36,6 +38,9 @@ func (r *Record) Init(lowerLevelName bool) {
 	if r.Time.IsZero() {
 		r.Time = r.logger.TimeClock.Now()
 	}
+	if r.logger != nil && r.logger.TimeLocation != nil {
+		r.Time = r.Time.In(r.logger.TimeLocation)
+	}
 
 	// r.microSecond = r.Time.Nanosecond() / 1000
 }
@@ -44,7 +49,7 @@ func (r *Record) Init(lowerLevelName bool) {
 func (r *Record) beforeHandle(l *Logger) {
 	// log caller. will alloc 3 times
 	if l.ReportCaller {
-		caller, ok := getCaller(r.CallerSkip)
+		caller, ok := getCaller(r.CallerSkip + internalCallerSkip)
 		if ok {
 			r.Caller = &caller
 		}
@@ -60,36 +65,115 @@ func (r *Record) beforeHandle(l *Logger) {
 func (l *Logger) writeRecord(level Level, r *Record) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+
+	l.handleRecord(level, r)
+	// flush logs on level <= error level.
+	if level <= ErrorLevel {
+		l.flushAll() // has been in lock
+	}
+}
+
+// do write the record with each message to handlers, only add lock once.
+func (l *Logger) writeRecords(level Level, r *Record, msgs []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// keep the time for each message, it is reset after handle.
+	t := r.Time
+	for _, msg := range msgs {
+		r.Time, r.Message = t, msg
+		l.handleRecord(level, r)
+	}
+
+	if level <= ErrorLevel {
+		l.flushAll()
+	}
+}
+
+// handle the record by the matched handlers. should be in lock.
+func (l *Logger) handleRecord(level Level, r *Record) {
 	// reset init flag, useful for repeat use Record
 	r.inited = false
 
-	for _, handler := range l.handlers {
-		if handler.IsHandling(level) {
-			// init record, call processors
-			if !r.inited {
-				r.Init(l.LowerLevelName)
-				r.beforeHandle(l)
-			}
+	enabled := l.levelEnabled(level)
+	// fire hooks before the processors and handlers
+	if enabled && len(l.hooks) > 0 {
+		r.Init(l.LowerLevelName)
+		enabled = l.fireHooks(r)
+	}
 
-			// do write log message by handler
-			if err := handler.Handle(r); err != nil {
-				l.err = err
-				printlnStderr("slog: failed to handle log, error:", err)
+	// channel handlers first, then the global handlers. the global handlers are sorted by priority
+	var processed bool
+	for _, hs := range [2][]Handler{l.channelHandlers[r.Channel], l.handlers} {
+		for _, handler := range hs {
+			if enabled && handler.IsHandling(level) {
+				// init record, call processors
+				if !processed {
+					if !r.inited {
+						r.Init(l.LowerLevelName)
+					}
+					r.beforeHandle(l)
+					processed = true
+					// the record is read-only for the handlers
+					r.frozen = true
+				}
+
+				// do write log message by handler
+				if err := l.callHandler(handler, r); err != nil {
+					l.handleError(err, r)
+				}
 			}
 		}
 	}
 
 	// ---- after write log ----
+	r.frozen = false
 	r.Time = emptyTime
+}
 
-	// flush logs on level <= error level.
-	if level <= ErrorLevel {
-		l.flushAll() // has been in lock
+// call the handler Handle(), the panic will be recovered as error on RecoverHandlers=true
+func (l *Logger) callHandler(h Handler, r *Record) (err error) {
+	if l.RecoverHandlers {
+		defer func() {
+			if rv := recover(); rv != nil {
+				err = fmt.Errorf("slog: handler %T panic: %v", h, rv)
+			}
+		}()
 	}
+	return h.Handle(r)
+}
 
+// handle the error returned by hooks and handlers. should be in lock.
+func (l *Logger) handleError(err error, r *Record) {
+	l.err = err
+	if l.ErrorHandler != nil {
+		l.ErrorHandler(err, r)
+	} else {
+		printlnStderr("slog: failed to handle log, error:", err)
+	}
+}
+
+// release the record, then call panic or exit on the panic/fatal level.
+//
+// NOTE: must call after writeRecord(), the logs have been flushed and the lock is released.
+func (l *Logger) panicOrExit(level Level, r *Record) {
 	if level <= PanicLevel {
-		l.PanicFunc(r)
-	} else if level <= FatalLevel {
-		l.Exit(1)
+		if code, ok := l.ExitCodes[PanicLevel]; ok {
+			l.releaseRecord(r)
+			l.Exit(code)
+		} else {
+			// the record is passed to the PanicFunc, will not be reused
+			l.PanicFunc(r)
+		}
+		return
+	}
+
+	l.releaseRecord(r)
+	if level <= FatalLevel {
+		code, ok := l.ExitCodes[level]
+		if !ok {
+			code = l.ExitCode
+		}
+		l.Exit(code)
 	}
 }
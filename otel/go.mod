@@ -0,0 +1,22 @@
+module github.com/gookit/slog/otel
+
+go 1.19
+
+require (
+	github.com/gookit/goutil v0.6.14
+	github.com/gookit/slog v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel/trace v1.16.0
+)
+
+require (
+	github.com/gookit/color v1.5.4 // indirect
+	github.com/gookit/gsr v0.1.0 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
+	go.opentelemetry.io/otel v1.16.0 // indirect
+	golang.org/x/sync v0.4.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
+)
+
+replace github.com/gookit/slog => ../
@@ -0,0 +1,32 @@
+// Package otel provide the OpenTelemetry integration for the slog.
+//
+// It is a separate module, so the core module does not depend on OpenTelemetry.
+package otel
+
+import (
+	"context"
+
+	"github.com/gookit/slog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceIDs extract the trace ID and span ID of the OpenTelemetry span in ctx.
+// returns ok=false if there is no active span.
+func TraceIDs(ctx context.Context) (traceID, spanID string, ok bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return "", "", false
+	}
+	return sc.TraceID().String(), sc.SpanID().String(), true
+}
+
+// TraceProcessor add the "trace_id" and "span_id" of the OpenTelemetry span in record.Ctx to record.Fields.
+// do nothing if there is no active span.
+//
+// Usage:
+//
+//	l.AddProcessor(otel.TraceProcessor())
+//	l.WithCtx(ctx).Info("message")
+func TraceProcessor() slog.Processor {
+	return slog.AddTraceIDs(TraceIDs)
+}
@@ -0,0 +1,33 @@
+package otel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceProcessor(t *testing.T) {
+	p := otel.TraceProcessor()
+
+	// no active span
+	r := (&slog.Record{}).SetCtx(context.Background())
+	p.Process(r)
+	assert.Empty(t, r.Fields)
+
+	tid, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.NoErr(t, err)
+	sid, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	assert.NoErr(t, err)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: sid})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	r = (&slog.Record{}).SetCtx(ctx)
+	p.Process(r)
+	assert.Eq(t, "4bf92f3577b34da6a3ce929d0e0e4736", r.Fields["trace_id"])
+	assert.Eq(t, "00f067aa0ba902b7", r.Fields["span_id"])
+}
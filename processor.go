@@ -1,10 +1,16 @@
 package slog
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"os"
+	"path"
+	"regexp"
 	"runtime"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/gookit/goutil/strutil"
 )
@@ -79,6 +85,17 @@ func AddUniqueID(fieldName string) Processor {
 	})
 }
 
+// AddGID add the current goroutine ID to record.Fields, the field name is "gid".
+// it is useful for debugging concurrency issues.
+//
+// NOTICE: get the goroutine ID need call runtime.Stack() and parse it, costs about 1µs per record.
+// so it is recommended to be used only for debugging.
+func AddGID() Processor {
+	return ProcessorFunc(func(record *Record) {
+		record.AddField(FieldKeyGID, goroutineID())
+	})
+}
+
 // MemoryUsage get memory usage.
 var MemoryUsage ProcessorFunc = func(record *Record) {
 	stat := new(runtime.MemStats)
@@ -100,3 +117,542 @@ func AppendCtxKeys(keys ...string) Processor {
 		}
 	})
 }
+
+// TraceExtractFunc extract the trace ID and span ID from the context. returns ok=false if no active span.
+type TraceExtractFunc func(ctx context.Context) (traceID, spanID string, ok bool)
+
+// AddTraceIDs add the "trace_id" and "span_id" to record.Fields by the extract func,
+// do nothing if the record.Ctx is nil or has no active span.
+//
+// For OpenTelemetry, please use otel.TraceProcessor() in the module github.com/gookit/slog/otel.
+func AddTraceIDs(extract TraceExtractFunc) Processor {
+	return ProcessorFunc(func(record *Record) {
+		if record.Ctx == nil {
+			return
+		}
+
+		if traceID, spanID, ok := extract(record.Ctx); ok {
+			record.AddField("trace_id", traceID)
+			record.AddField("span_id", spanID)
+		}
+	})
+}
+
+// CallerOption for the AddCaller processor
+type CallerOption struct {
+	// CallerKey field name for the caller. default is "caller"
+	CallerKey string
+	// FuncKey field name for the func. default is "func"
+	FuncKey string
+	// FullPath use full file path on caller field. default is short filename. eg: "main.go:12"
+	FullPath bool
+	// WithFunc add the func field. eg: "github.com/gookit/slog.TestAddCaller"
+	WithFunc bool
+	// UseFlag format the caller field by Record.CallerFlag, instead of "file:line"
+	UseFlag bool
+}
+
+// AddCaller resolve the caller and add it to record.Fields. eg: caller=main.go:12
+//
+// If Logger.ReportCaller is disabled, will resolve the caller by Record.CallerSkip.
+//
+// NOTICE: should be added to Logger.processors, not to the handler.
+func AddCaller(fns ...func(opt *CallerOption)) Processor {
+	opt := &CallerOption{
+		CallerKey: FieldKeyCaller,
+		FuncKey:   "func",
+	}
+	for _, fn := range fns {
+		fn(opt)
+	}
+
+	return ProcessorFunc(func(record *Record) {
+		if record.Caller == nil {
+			// skip 2 frames: this func, ProcessorFunc.Process
+			caller, ok := getCaller(record.CallerSkip + internalCallerSkip + 2)
+			if !ok {
+				return
+			}
+			record.Caller = &caller
+		}
+
+		rf := record.Caller
+		if opt.UseFlag {
+			record.AddField(opt.CallerKey, formatCaller(rf, record.CallerFlag))
+		} else if opt.FullPath {
+			record.AddField(opt.CallerKey, rf.File+":"+strconv.Itoa(rf.Line))
+		} else {
+			record.AddField(opt.CallerKey, path.Base(rf.File)+":"+strconv.Itoa(rf.Line))
+		}
+
+		if opt.WithFunc {
+			record.AddField(opt.FuncKey, rf.Function)
+		}
+	})
+}
+
+// FlattenFields flatten the nested map values in record.Fields and record.Data to top-level keys.
+//
+// The sep is the key separator, default is ".". eg:
+//
+//	{"user": {"id": 1, "name": "inhere"}} -> {"user.id": 1, "user.name": "inhere"}
+func FlattenFields(sep string) Processor {
+	if sep == "" {
+		sep = "."
+	}
+
+	return ProcessorFunc(func(record *Record) {
+		if mp, ok := flattenMap(record.Fields, sep); ok {
+			record.Fields = mp
+		}
+		if mp, ok := flattenMap(record.Data, sep); ok {
+			record.Data = mp
+		}
+	})
+}
+
+// DropFields remove the keys from record.Fields and record.Data.
+// it is useful for stripping verbose internal fields before they hit an expensive sink.
+//
+// NOTICE: will set new maps to the record, the original maps are not modified.
+func DropFields(keys ...string) Processor {
+	set := keySet(keys)
+
+	return ProcessorFunc(func(record *Record) {
+		drop := func(key string) bool {
+			_, ok := set[key]
+			return !ok
+		}
+
+		record.Fields = filterMap(record.Fields, drop)
+		record.Data = filterMap(record.Data, drop)
+	})
+}
+
+// KeepFields remove all keys from record.Fields and record.Data except the given keys.
+//
+// NOTICE: will set new maps to the record, the original maps are not modified.
+func KeepFields(keys ...string) Processor {
+	set := keySet(keys)
+
+	return ProcessorFunc(func(record *Record) {
+		keep := func(key string) bool {
+			_, ok := set[key]
+			return ok
+		}
+
+		record.Fields = filterMap(record.Fields, keep)
+		record.Data = filterMap(record.Data, keep)
+	})
+}
+
+// OmitEmptyOption for the OmitEmptyFields processor
+type OmitEmptyOption struct {
+	// ZeroNumber treat the zero number as empty. eg: 0, 0.0. default is false
+	ZeroNumber bool
+	// ZeroBool treat the false as empty. default is false
+	ZeroBool bool
+	// IsEmpty custom check the value is empty. if set, the ZeroNumber and ZeroBool are ignored.
+	IsEmpty func(val any) bool
+}
+
+// OmitEmptyFields remove the empty values from record.Fields and record.Data.
+// it is useful for avoid the noise like "error": null in the output.
+//
+// The empty values by default: nil(include the nil pointer, error), "", and the zero-length slice, map, array.
+//
+// Usage:
+//
+//	l.AddProcessor(slog.OmitEmptyFields(func(opt *slog.OmitEmptyOption) {
+//		opt.ZeroNumber = true
+//	}))
+//
+// NOTICE: will set new maps to the record, the original maps are not modified.
+func OmitEmptyFields(fns ...func(opt *OmitEmptyOption)) Processor {
+	opt := &OmitEmptyOption{}
+	for _, fn := range fns {
+		fn(opt)
+	}
+
+	isEmpty := opt.IsEmpty
+	if isEmpty == nil {
+		isEmpty = func(val any) bool {
+			return isEmptyValue(val, opt.ZeroNumber, opt.ZeroBool)
+		}
+	}
+
+	return ProcessorFunc(func(record *Record) {
+		record.Fields = filterMap(record.Fields, func(key string) bool {
+			return !isEmpty(record.Fields[key])
+		})
+		record.Data = filterMap(record.Data, func(key string) bool {
+			return !isEmpty(record.Data[key])
+		})
+	})
+}
+
+// RenameOption for the RenameFields processor
+type RenameOption struct {
+	// Overwrite the existing key on the new name collision. default is false, will skip the rename.
+	Overwrite bool
+}
+
+// RenameFields rename the keys in record.Fields by the names map(old -> new).
+// unmatched keys are not changed. it is useful for adapting to a downstream schema.
+//
+// Usage:
+//
+//	h.AddProcessor(slog.RenameFields(map[string]string{"user": "user_id"}))
+//
+// NOTICE: will set new map to the record, the original map is not modified.
+func RenameFields(names map[string]string, fns ...func(opt *RenameOption)) Processor {
+	opt := &RenameOption{}
+	for _, fn := range fns {
+		fn(opt)
+	}
+
+	return ProcessorFunc(func(record *Record) {
+		if len(record.Fields) == 0 {
+			return
+		}
+
+		var mp M
+		for oldKey, newKey := range names {
+			val, ok := record.Fields[oldKey]
+			if !ok || oldKey == newKey {
+				continue
+			}
+			if _, exists := record.Fields[newKey]; exists && !opt.Overwrite {
+				continue
+			}
+
+			// copy on first rename
+			if mp == nil {
+				mp = make(M, len(record.Fields))
+				for k, v := range record.Fields {
+					mp[k] = v
+				}
+			}
+
+			delete(mp, oldKey)
+			mp[newKey] = val
+		}
+
+		if mp != nil {
+			record.Fields = mp
+		}
+	})
+}
+
+// KeyCollision the strategy on multiple keys are sanitized to the same key. see SanitizeKeys
+type KeyCollision uint8
+
+const (
+	// KeyCollisionLastWins keep the value of the last key, the keys are sorted. this is default.
+	// eg: "a-b", "a.b" -> "a_b" is the value of "a.b"
+	KeyCollisionLastWins KeyCollision = iota
+	// KeyCollisionSuffix add the numeric suffix to the collided keys, the keys are sorted.
+	// the unchanged keys are kept. eg: "a-b", "a.b" -> "a_b" is the value of "a-b", "a_b_2" is the value of "a.b"
+	KeyCollisionSuffix
+)
+
+// SanitizeOption for the SanitizeKeys processor
+type SanitizeOption struct {
+	// Collision the strategy on the sanitized keys are collided. default is KeyCollisionLastWins
+	Collision KeyCollision
+}
+
+// SanitizeKeys rewrite the keys in record.Fields and record.Data by the sanitize func.
+// it is useful for the sinks restrict the key chars. eg: journald, Prometheus labels, BigQuery
+//
+// The fn default is SanitizeKey, replace the chars other than "a-zA-Z0-9_" with "_".
+//
+// Usage:
+//
+//	l.AddProcessor(slog.SanitizeKeys(nil, func(opt *slog.SanitizeOption) {
+//		opt.Collision = slog.KeyCollisionSuffix
+//	}))
+//
+// NOTICE: will set new maps to the record on any key changed, the original maps are not modified.
+func SanitizeKeys(fn func(key string) string, fns ...func(opt *SanitizeOption)) Processor {
+	if fn == nil {
+		fn = SanitizeKey
+	}
+
+	opt := &SanitizeOption{}
+	for _, f := range fns {
+		f(opt)
+	}
+
+	return ProcessorFunc(func(record *Record) {
+		record.Fields = sanitizeMap(record.Fields, fn, opt.Collision)
+		record.Data = sanitizeMap(record.Data, fn, opt.Collision)
+	})
+}
+
+// SanitizeKey replace the chars other than "a-zA-Z0-9_" with "_". eg: "http.status-code" -> "http_status_code"
+func SanitizeKey(key string) string {
+	return strings.Map(func(c rune) rune {
+		if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			return c
+		}
+		return '_'
+	}, key)
+}
+
+// sanitize the map keys. returns the src if no key changed.
+func sanitizeMap(src M, fn func(string) string, collision KeyCollision) M {
+	changed := false
+	for k := range src {
+		if fn(k) != k {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return src
+	}
+
+	// sort the keys, the collision result is stable
+	keys := make([]string, 0, len(src))
+	for k := range src {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	mp := make(M, len(src))
+	if collision == KeyCollisionSuffix {
+		// the unchanged keys take precedence
+		for _, k := range keys {
+			if fn(k) == k {
+				mp[k] = src[k]
+			}
+		}
+	}
+
+	for _, k := range keys {
+		newKey := fn(k)
+		if newKey == k && collision == KeyCollisionSuffix {
+			continue
+		}
+
+		if _, exists := mp[newKey]; exists && collision == KeyCollisionSuffix {
+			base := newKey
+			for i := 2; exists; i++ {
+				newKey = base + "_" + strconv.Itoa(i)
+				_, exists = mp[newKey]
+			}
+		}
+		mp[newKey] = src[k]
+	}
+	return mp
+}
+
+// the built-in patterns for the RedactRegex processor
+var (
+	// RedactEmailRegex match the email address. eg: inhere@example.com
+	RedactEmailRegex = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// RedactCardRegex match the common card numbers: 13-19 digits, allow grouped by space or "-".
+	// eg: 4111 1111 1111 1111, 4111-1111-1111-1111
+	//
+	// NOTICE: the RedactRegex processor only mask the matched text passed the Luhn check, but it still
+	// can over-match the other digits passed by chance. eg: timestamps, IDs, phone numbers.
+	RedactCardRegex = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+)
+
+// RedactOption for the RedactRegex processor
+type RedactOption struct {
+	// Mask the replacement of the matched text. default is "[REDACTED]"
+	Mask string
+	// Levels limit the processor to the levels. default is all levels
+	Levels Levels
+}
+
+// RedactRegex replace the matched text in record.Message and the string values
+// in record.Fields, record.Data by the mask "[REDACTED]". eg: emails, card numbers in free-form messages.
+//
+// Usage:
+//
+//	logger.AddProcessor(slog.RedactRegex(slog.RedactEmailRegex, slog.RedactCardRegex))
+//
+// NOTICE: the patterns are combined into one regexp, each string is scanned once, the cost
+// is linear in the total length of the message and string values. the nested values are not scanned.
+// will set new maps to the record on matched, the original maps are not modified.
+//
+// The matched text of RedactCardRegex is only masked on it passed the Luhn check, for reduce the
+// false positives. eg: 19 digits UnixNano timestamp. but the patterns can over-match, please check them
+// with your log data.
+func RedactRegex(patterns ...*regexp.Regexp) Processor {
+	return RedactRegexWith(nil, patterns...)
+}
+
+// RedactRegexWith the RedactRegex processor with options. see RedactRegex()
+//
+// Usage:
+//
+//	logger.AddProcessor(slog.RedactRegexWith(func(opt *slog.RedactOption) {
+//		opt.Mask = "***"
+//		opt.Levels = slog.Levels{slog.ErrorLevel, slog.WarnLevel}
+//	}, slog.RedactEmailRegex))
+func RedactRegexWith(fn func(opt *RedactOption), patterns ...*regexp.Regexp) Processor {
+	opt := &RedactOption{Mask: "[REDACTED]"}
+	if fn != nil {
+		fn(opt)
+	}
+	if len(patterns) == 0 {
+		return ProcessorFunc(func(record *Record) {})
+	}
+
+	re := patterns[0]
+	if len(patterns) > 1 {
+		ss := make([]string, len(patterns))
+		for i, p := range patterns {
+			ss[i] = "(?:" + p.String() + ")"
+		}
+		re = regexp.MustCompile(strings.Join(ss, "|"))
+	}
+
+	// the other patterns for check the matched text is not a card number
+	var others []*regexp.Regexp
+	var checkCard bool
+	for _, p := range patterns {
+		if p == RedactCardRegex {
+			checkCard = true
+		} else {
+			others = append(others, p)
+		}
+	}
+
+	replace := func(m string) string {
+		if checkCard && !luhnValid(m) && !matchAny(others, m) {
+			return m
+		}
+		return opt.Mask
+	}
+
+	redact := func(s string) (string, bool) {
+		if !re.MatchString(s) {
+			return s, false
+		}
+
+		ns := re.ReplaceAllStringFunc(s, replace)
+		return ns, ns != s
+	}
+
+	return ProcessorFunc(func(record *Record) {
+		if len(opt.Levels) > 0 && !opt.Levels.Contains(record.Level) {
+			return
+		}
+
+		record.Message, _ = redact(record.Message)
+		record.Fields = redactMap(record.Fields, redact)
+		record.Data = redactMap(record.Data, redact)
+	})
+}
+
+// check the card number by the Luhn algorithm, the space and "-" are ignored.
+func luhnValid(s string) bool {
+	var sum, n int
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		if c < '0' || c > '9' {
+			return false
+		}
+
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n > 0 && sum%10 == 0
+}
+
+// check the text is fully matched by any of the patterns
+func matchAny(patterns []*regexp.Regexp, s string) bool {
+	for _, p := range patterns {
+		if p.FindString(s) == s {
+			return true
+		}
+	}
+	return false
+}
+
+// redact the string values in the map. returns a new map if has changed.
+func redactMap(src M, redact func(s string) (string, bool)) M {
+	var mp M
+	for key, val := range src {
+		str, ok := val.(string)
+		if !ok {
+			continue
+		}
+
+		if str, ok = redact(str); ok {
+			// copy on first redact
+			if mp == nil {
+				mp = make(M, len(src))
+				for k, v := range src {
+					mp[k] = v
+				}
+			}
+			mp[key] = str
+		}
+	}
+
+	if mp == nil {
+		return src
+	}
+	return mp
+}
+
+func keySet(keys []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		set[key] = struct{}{}
+	}
+	return set
+}
+
+// StackTraceOption for the AddStackTrace processor
+type StackTraceOption struct {
+	// Key name for the stack trace in the Record.Extra. default is "stacktrace"
+	Key string
+	// MaxDepth max frames for the stack trace. default is 32
+	MaxDepth int
+}
+
+// AddStackTrace capture the stack trace and add it to record.Extra on level <= minLevel.
+//
+// The slog internal frames will be skipped. format like runtime.Stack():
+//
+//	main.main
+//		/path/to/main.go:12
+//
+// Usage:
+//
+//	logger.AddProcessor(slog.AddStackTrace(slog.ErrorLevel))
+func AddStackTrace(minLevel Level, fns ...func(opt *StackTraceOption)) Processor {
+	opt := &StackTraceOption{
+		Key:      FieldKeyStacktrace,
+		MaxDepth: 32,
+	}
+	for _, fn := range fns {
+		fn(opt)
+	}
+
+	return ProcessorFunc(func(record *Record) {
+		if !minLevel.ShouldHandling(record.Level) {
+			return
+		}
+
+		if stack := getStackTrace(opt.MaxDepth); stack != "" {
+			record.SetExtraValue(opt.Key, stack)
+		}
+	})
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/gookit/goutil/byteutil"
 	"github.com/gookit/goutil/testutil/assert"
 	"github.com/gookit/slog"
+	"github.com/gookit/slog/handler"
 )
 
 func TestLogger_AddProcessor(t *testing.T) {
@@ -62,6 +65,67 @@ func TestLogger_AddProcessor(t *testing.T) {
 	assert.Contains(t, str, `"traceId":"traceId123abc456"`)
 }
 
+type traceKey struct{}
+
+func TestLogger_processorsOrder(t *testing.T) {
+	var order []string
+	mark := func(name string) slog.Processor {
+		return slog.ProcessorFunc(func(r *slog.Record) {
+			order = append(order, name)
+		})
+	}
+
+	h := newTestHandler()
+	h.SetFormatter(slog.NewTextFormatter("{{message}}\n"))
+	l := slog.NewWithHandlers(h)
+	l.DoNothingOnPanicFatal()
+	l.SetLevel(slog.InfoLevel)
+
+	l.AddProcessor(mark("mask"))
+	l.PushProcessor(mark("last"))
+	l.PrependProcessor(mark("ctx"), mark("trace"))
+	assert.Len(t, l.Processors(), 4)
+
+	l.Info("message")
+	assert.Eq(t, []string{"ctx", "trace", "mask", "last"}, order)
+
+	// not run on the level is filtered
+	order = order[:0]
+	l.Debug("debug message")
+	assert.Empty(t, order)
+
+	// the list is copied
+	ps := []slog.Processor{mark("b"), mark("a")}
+	l.SetProcessors(ps)
+	ps[0] = mark("c")
+	l.Info("message2")
+	assert.Eq(t, []string{"b", "a"}, order)
+	assert.Eq(t, "message\nmessage2\n", h.ResetGet())
+}
+
+func TestAddTraceIDs(t *testing.T) {
+	p := slog.AddTraceIDs(func(ctx context.Context) (string, string, bool) {
+		ids, ok := ctx.Value(traceKey{}).([2]string)
+		return ids[0], ids[1], ok
+	})
+
+	// no ctx
+	r := newLogRecord("message")
+	p.Process(r)
+	assert.Empty(t, r.Fields)
+
+	// no active span
+	r = newLogRecord("message").WithCtx(context.Background())
+	p.Process(r)
+	assert.Empty(t, r.Fields)
+
+	ctx := context.WithValue(context.Background(), traceKey{}, [2]string{"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"})
+	r = newLogRecord("message").WithCtx(ctx)
+	p.Process(r)
+	assert.Eq(t, "4bf92f3577b34da6a3ce929d0e0e4736", r.Fields["trace_id"])
+	assert.Eq(t, "00f067aa0ba902b7", r.Fields["span_id"])
+}
+
 func TestProcessable_AddProcessor(t *testing.T) {
 	ps := &slog.Processable{}
 	ps.AddProcessor(slog.MemoryUsage)
@@ -72,3 +136,289 @@ func TestProcessable_AddProcessor(t *testing.T) {
 	assert.NotEmpty(t, r.Extra)
 	assert.Contains(t, r.Extra, "memoryUsage")
 }
+
+func TestAddCaller(t *testing.T) {
+	buf := new(byteutil.Buffer)
+	h := handler.NewIOWriter(buf, slog.AllLevels)
+	h.SetFormatter(slog.NewJSONFormatter(func(f *slog.JSONFormatter) {
+		f.Fields = slog.NoTimeFields
+	}))
+
+	l := slog.NewWithHandlers(h)
+	l.AddProcessor(slog.AddCaller(func(opt *slog.CallerOption) {
+		opt.WithFunc = true
+	}))
+
+	_, file, line, _ := runtime.Caller(0)
+	l.Info("message") // NOTICE: must be next line of runtime.Caller(0)
+
+	str := buf.ResetAndGet()
+	assert.Contains(t, str, fmt.Sprintf(`"caller":"processor_test.go:%d"`, line+1))
+	assert.Contains(t, str, `"func":"github.com/gookit/slog_test.TestAddCaller"`)
+
+	// resolve caller on disable ReportCaller
+	l.ReportCaller = false
+	l.ResetProcessors()
+	l.AddProcessor(slog.AddCaller(func(opt *slog.CallerOption) {
+		opt.FullPath = true
+	}))
+
+	_, _, line, _ = runtime.Caller(0)
+	l.Info("message") // NOTICE: must be next line of runtime.Caller(0)
+
+	str = buf.ResetAndGet()
+	assert.Contains(t, str, fmt.Sprintf(`"caller":"%s:%d"`, file, line+1))
+	assert.NotContains(t, str, `"func"`)
+
+	// use caller flag
+	l = slog.NewWithHandlers(h)
+	l.CallerFlag = slog.CallerFlagFcName
+	l.AddProcessor(slog.AddCaller(func(opt *slog.CallerOption) {
+		opt.UseFlag = true
+	}))
+
+	l.Info("message")
+	assert.Contains(t, buf.ResetAndGet(), `"caller":"TestAddCaller"`)
+}
+
+func TestAddStackTrace(t *testing.T) {
+	buf := new(byteutil.Buffer)
+	h := handler.NewIOWriter(buf, slog.AllLevels)
+	h.SetFormatter(slog.FormatterFunc(func(r *slog.Record) ([]byte, error) {
+		stack, _ := r.Extra["trace"].(string)
+		return []byte(r.Message + "|" + stack), nil
+	}))
+
+	l := slog.NewWithHandlers(h)
+	l.DoNothingOnPanicFatal()
+	l.AddProcessor(slog.AddStackTrace(slog.ErrorLevel, func(opt *slog.StackTraceOption) {
+		opt.Key = "trace"
+		opt.MaxDepth = 2
+	}))
+
+	l.Warn("warn message")
+	assert.Eq(t, "warn message|", buf.ResetAndGet())
+
+	l.Error("error message")
+	str := buf.ResetAndGet()
+	lines := strings.Split(strings.TrimSpace(str), "\n")
+	assert.Len(t, lines, 4)
+	assert.Eq(t, "error message|github.com/gookit/slog_test.TestAddStackTrace", lines[0])
+	assert.StrContains(t, lines[1], "processor_test.go:")
+	assert.StrContains(t, lines[2], "testing.tRunner")
+}
+
+func TestAddGID(t *testing.T) {
+	p := slog.AddGID()
+
+	r := &slog.Record{}
+	p.Process(r)
+	gid, ok := r.Fields[slog.FieldKeyGID].(uint64)
+	assert.True(t, ok)
+	assert.True(t, gid > 0)
+
+	// on other goroutine
+	ch := make(chan uint64)
+	go func() {
+		r := &slog.Record{}
+		p.Process(r)
+		ch <- r.Field(slog.FieldKeyGID).(uint64)
+	}()
+
+	gid2 := <-ch
+	assert.True(t, gid2 > 0)
+	assert.Neq(t, gid, gid2)
+}
+
+func TestFlattenFields(t *testing.T) {
+	r := &slog.Record{
+		Fields: slog.M{
+			"user": slog.M{
+				"id":   1,
+				"name": "inhere",
+				"addr": map[string]any{"city": "chengdu"},
+			},
+			"tags": []string{"a"},
+		},
+		Data: slog.M{"key": "val"},
+	}
+	data := r.Data
+
+	slog.FlattenFields("").Process(r)
+	assert.Eq(t, slog.M{
+		"user.id":        1,
+		"user.name":      "inhere",
+		"user.addr.city": "chengdu",
+		"tags":           []string{"a"},
+	}, r.Fields)
+	// no nested map, not changed
+	assert.Eq(t, data, r.Data)
+
+	r.Data = slog.M{"req": slog.M{"method": "GET"}}
+	slog.FlattenFields("_").Process(r)
+	assert.Eq(t, slog.M{"req_method": "GET"}, r.Data)
+}
+
+func TestDropFields(t *testing.T) {
+	fields := slog.M{"user": "inhere", "trace": "abc", "debug": true}
+	r := &slog.Record{
+		Fields: fields,
+		Data:   slog.M{"key": "val", "debug": "info"},
+	}
+
+	slog.DropFields("debug", "trace").Process(r)
+	assert.Eq(t, slog.M{"user": "inhere"}, r.Fields)
+	assert.Eq(t, slog.M{"key": "val"}, r.Data)
+	// original map is not modified
+	assert.Len(t, fields, 3)
+
+	slog.KeepFields("user").Process(r)
+	assert.Eq(t, slog.M{"user": "inhere"}, r.Fields)
+	assert.Empty(t, r.Data)
+
+	// nil maps
+	r = &slog.Record{}
+	slog.DropFields("debug").Process(r)
+	slog.KeepFields("user").Process(r)
+	assert.Nil(t, r.Fields)
+	assert.Nil(t, r.Data)
+}
+
+func TestRenameFields(t *testing.T) {
+	fields := slog.M{"user": "inhere", "ip": "127.0.0.1", "uid": 23}
+	r := &slog.Record{Fields: fields}
+
+	slog.RenameFields(map[string]string{"ip": "client_ip", "user": "uid", "none": "other"}).Process(r)
+	assert.Eq(t, slog.M{"user": "inhere", "client_ip": "127.0.0.1", "uid": 23}, r.Fields)
+	// original map is not modified
+	assert.Eq(t, "127.0.0.1", fields["ip"])
+
+	// overwrite on collision
+	slog.RenameFields(map[string]string{"user": "uid"}, func(opt *slog.RenameOption) {
+		opt.Overwrite = true
+	}).Process(r)
+	assert.Eq(t, slog.M{"client_ip": "127.0.0.1", "uid": "inhere"}, r.Fields)
+
+	// nil map
+	r = &slog.Record{}
+	slog.RenameFields(map[string]string{"user": "uid"}).Process(r)
+	assert.Nil(t, r.Fields)
+}
+
+func TestOmitEmptyFields(t *testing.T) {
+	var nilErr error
+	var nilPtr *slog.Record
+	fields := slog.M{
+		"nil":       nil,
+		"nil_err":   nilErr,
+		"nil_ptr":   nilPtr,
+		"empty_str": "",
+		"empty_sli": []string{},
+		"nil_sli":   []int(nil),
+		"empty_map": map[string]any{},
+		"zero":      0,
+		"false":     false,
+		"name":      "inhere",
+		"tags":      []string{"a"},
+	}
+	r := &slog.Record{
+		Fields: fields,
+		Data:   slog.M{"err": nil, "ok": 1},
+	}
+
+	slog.OmitEmptyFields().Process(r)
+	assert.Eq(t, slog.M{"zero": 0, "false": false, "name": "inhere", "tags": []string{"a"}}, r.Fields)
+	assert.Eq(t, slog.M{"ok": 1}, r.Data)
+	// original map is not modified
+	assert.Len(t, fields, 11)
+
+	// zero number and false are empty
+	r.Fields = slog.M{"zero": 0, "zero_f": 0.0, "false": false, "num": 2}
+	slog.OmitEmptyFields(func(opt *slog.OmitEmptyOption) {
+		opt.ZeroNumber = true
+		opt.ZeroBool = true
+	}).Process(r)
+	assert.Eq(t, slog.M{"num": 2}, r.Fields)
+
+	// custom check func
+	r.Fields = slog.M{"a": "-", "b": ""}
+	slog.OmitEmptyFields(func(opt *slog.OmitEmptyOption) {
+		opt.IsEmpty = func(val any) bool { return val == "-" }
+	}).Process(r)
+	assert.Eq(t, slog.M{"b": ""}, r.Fields)
+}
+
+func TestSanitizeKeys(t *testing.T) {
+	fields := slog.M{"user-id": 1, "http.status": 200, "a-b": "x", "a.b": "y", "ok_key": true}
+	r := &slog.Record{
+		Fields: fields,
+		Data:   slog.M{"valid": 1},
+	}
+
+	slog.SanitizeKeys(nil).Process(r)
+	assert.Eq(t, slog.M{"user_id": 1, "http_status": 200, "a_b": "y", "ok_key": true}, r.Fields)
+	// original map is not modified
+	assert.Len(t, fields, 5)
+	assert.Eq(t, slog.M{"valid": 1}, r.Data)
+
+	// numeric suffix on collision
+	r.Fields = slog.M{"a-b": "x", "a.b": "y", "a_b": "z", "a_b_2": "w"}
+	slog.SanitizeKeys(nil, func(opt *slog.SanitizeOption) {
+		opt.Collision = slog.KeyCollisionSuffix
+	}).Process(r)
+	assert.Eq(t, slog.M{"a_b": "z", "a_b_2": "w", "a_b_3": "x", "a_b_4": "y"}, r.Fields)
+
+	r.Fields = slog.M{"a-b": "x", "a.b": "y"}
+	slog.SanitizeKeys(nil, func(opt *slog.SanitizeOption) {
+		opt.Collision = slog.KeyCollisionSuffix
+	}).Process(r)
+	assert.Eq(t, slog.M{"a_b": "x", "a_b_2": "y"}, r.Fields)
+
+	// custom sanitize func
+	r.Data = slog.M{"User Name": "inhere"}
+	slog.SanitizeKeys(strings.ToLower).Process(r)
+	assert.Eq(t, slog.M{"user name": "inhere"}, r.Data)
+	assert.Eq(t, "http_status_code", slog.SanitizeKey("http.status-code"))
+}
+
+func TestRedactRegex(t *testing.T) {
+	fields := slog.M{"email": "inhere@example.com", "age": 23, "note": "no pii"}
+	r := &slog.Record{
+		Level:   slog.InfoLevel,
+		Message: "user inhere@example.com paid by 4111 1111 1111 1111",
+		Fields:  fields,
+		Data:    slog.M{"card": "card 4111-1111-1111-1111 is used", "order": "1234"},
+	}
+
+	slog.RedactRegex(slog.RedactEmailRegex, slog.RedactCardRegex).Process(r)
+	assert.Eq(t, "user [REDACTED] paid by [REDACTED]", r.Message)
+	assert.Eq(t, slog.M{"email": "[REDACTED]", "age": 23, "note": "no pii"}, r.Fields)
+	assert.Eq(t, slog.M{"card": "card [REDACTED] is used", "order": "1234"}, r.Data)
+	// original map is not modified
+	assert.Eq(t, "inhere@example.com", fields["email"])
+
+	// the digits not passed the Luhn check are not masked. eg: UnixNano timestamp
+	r = &slog.Record{
+		Level:   slog.InfoLevel,
+		Message: "at 1700000000123456789 paid by 4111 1111 1111 1112",
+		Data:    slog.M{"ts": "1700000000123456789"},
+	}
+	slog.RedactRegex(slog.RedactEmailRegex, slog.RedactCardRegex).Process(r)
+	assert.Eq(t, "at 1700000000123456789 paid by 4111 1111 1111 1112", r.Message)
+	assert.Eq(t, slog.M{"ts": "1700000000123456789"}, r.Data)
+
+	// with options
+	p := slog.RedactRegexWith(func(opt *slog.RedactOption) {
+		opt.Mask = "***"
+		opt.Levels = slog.Levels{slog.ErrorLevel}
+	}, slog.RedactEmailRegex)
+
+	r = &slog.Record{Level: slog.InfoLevel, Message: "info inhere@example.com"}
+	p.Process(r)
+	assert.Eq(t, "info inhere@example.com", r.Message)
+
+	r.Level = slog.ErrorLevel
+	p.Process(r)
+	assert.Eq(t, "info ***", r.Message)
+	assert.Nil(t, r.Fields)
+}
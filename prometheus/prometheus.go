@@ -0,0 +1,50 @@
+// Package prometheus provide the Prometheus metrics for the slog handlers.
+//
+// It is a separate module, so the core module does not depend on the Prometheus client.
+package prometheus
+
+import (
+	"errors"
+
+	"github.com/gookit/slog/handler"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RecordsTotal the counter name of the handled records
+const RecordsTotal = "slog_records_total"
+
+// NewMetricsHandler create new handler.MetricsHandler, will increment the counter
+// slog_records_total{level="...",channel="..."} for each handled record.
+//
+// The counter is registered to the reg, default is prometheus.DefaultRegisterer.
+// if it has been registered, will reuse the registered counter.
+//
+// Usage:
+//
+//	h, err := prometheus.NewMetricsHandler(nil)
+//	slog.PushHandler(h)
+func NewMetricsHandler(reg prometheus.Registerer) (*handler.MetricsHandler, error) {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+
+	cv := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: RecordsTotal,
+		Help: "The total number of the handled log records.",
+	}, []string{"level", "channel"})
+
+	if err := reg.Register(cv); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if !errors.As(err, &are) {
+			return nil, err
+		}
+
+		if cv, _ = are.ExistingCollector.(*prometheus.CounterVec); cv == nil {
+			return nil, err
+		}
+	}
+
+	return handler.NewMetricsHandler(handler.MetricsCounterFunc(func(level, channel string) {
+		cv.WithLabelValues(level, channel).Inc()
+	})), nil
+}
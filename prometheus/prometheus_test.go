@@ -0,0 +1,46 @@
+package prometheus_test
+
+import (
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/slog"
+	"github.com/gookit/slog/prometheus"
+	client "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestNewMetricsHandler(t *testing.T) {
+	reg := client.NewRegistry()
+	h, err := prometheus.NewMetricsHandler(reg)
+	assert.NoErr(t, err)
+
+	l := slog.NewWithHandlers(h)
+	l.Info("info message")
+	l.Error("error message")
+	l.Error("error message 2")
+	assert.NoErr(t, l.Close())
+
+	assert.Eq(t, 2, testutil.CollectAndCount(reg, prometheus.RecordsTotal))
+
+	cv, err := reg.Gather()
+	assert.NoErr(t, err)
+	assert.Len(t, cv, 1)
+
+	// register again, will reuse the registered counter
+	h2, err := prometheus.NewMetricsHandler(reg)
+	assert.NoErr(t, err)
+	l = slog.NewWithHandlers(h2)
+	l.Error("error message 3")
+
+	var errCount float64
+	mfs, _ := reg.Gather()
+	for _, m := range mfs[0].GetMetric() {
+		for _, lp := range m.GetLabel() {
+			if lp.GetName() == "level" && lp.GetValue() == "error" {
+				errCount = m.GetCounter().GetValue()
+			}
+		}
+	}
+	assert.Eq(t, float64(3), errCount)
+}
@@ -21,6 +21,10 @@ type Record struct {
 	freed bool
 	// inited flag for record
 	inited bool
+	// the Message is rendered by Msgt(), will be kept on log without args.
+	tplMsg bool
+	// the record is shared read-only by the handlers. see Frozen()
+	frozen bool
 
 	// Time for record log, if is empty will use now.
 	//
@@ -41,6 +45,8 @@ type Record struct {
 	// Fields custom fields data.
 	// Contains all the fields set by the user.
 	Fields M
+	// group names for nest the subsequent fields. see WithGroup()
+	groups []string
 	// Data log context data
 	Data M
 	// Extra log extra data
@@ -104,6 +110,26 @@ func (r *Record) WithTime(t time.Time) *Record {
 	return nr
 }
 
+// WithChannel set the record channel. the channel handlers will be used. see Logger.AddHandlerForChannel()
+func (r *Record) WithChannel(name string) *Record {
+	nr := r.Copy()
+	nr.Channel = name
+	return nr
+}
+
+// WithCallerSkip add n extra frames to skip on resolve the caller. useful for log through the wrapper funcs.
+//
+// Usage:
+//
+//	func logInfo(r *slog.Record, msg string) {
+//		r.WithCallerSkip(1).Info(msg) // the caller will be the caller of logInfo()
+//	}
+func (r *Record) WithCallerSkip(n int) *Record {
+	nr := r.Copy()
+	nr.CallerSkip += n
+	return nr
+}
+
 // WithCtx on record
 func (r *Record) WithCtx(ctx context.Context) *Record { return r.WithContext(ctx) }
 
@@ -119,6 +145,24 @@ func (r *Record) WithError(err error) *Record {
 	return r.WithFields(M{FieldKeyError: err})
 }
 
+// WithSampleKey set the sampling key to Record.Extra, the sampling handlers will count the records
+// by it instead of the message. useful for the messages that embed an ID represent the same event.
+//
+// Usage:
+//
+//	r.WithSampleKey("order-created").Infof("order %d created", id)
+func (r *Record) WithSampleKey(key string) *Record {
+	nr := r.Copy()
+	nr.SetExtraValue(FieldKeySampleKey, key)
+	return nr
+}
+
+// SampleKey get the sampling key set by WithSampleKey. returns empty on not set.
+func (r *Record) SampleKey() string {
+	key, _ := r.Extra[FieldKeySampleKey].(string)
+	return key
+}
+
 // WithData on record
 func (r *Record) WithData(data M) *Record {
 	nr := r.Copy()
@@ -136,49 +180,119 @@ func (r *Record) WithField(name string, val any) *Record {
 // WithFields with new fields to record
 //
 // Note: add field need config Formatter template fields.
+// the returned record is released after one log call, use Logger.NewEntry() for a reusable child logger.
 func (r *Record) WithFields(fields M) *Record {
 	nr := r.Copy()
-	if nr.Fields == nil {
-		nr.Fields = make(M, len(fields))
-	}
+	mp := nr.groupFields(len(fields))
 
 	for k, v := range fields {
-		nr.Fields[k] = v
+		mp[k] = v
+	}
+	return nr
+}
+
+// WithGroup nest the subsequent fields under the group name. groups can be stacked.
+//
+// NOTICE: only the fields added by WithField(), WithFields() and With() will be nested,
+// the AddField() and AddFields() always add to the top level. eg: the fields added by processors.
+//
+// Usage:
+//
+//	r.WithGroup("http").WithField("status", 200)
+//	// on JSON: {"http": {"status": 200}}
+func (r *Record) WithGroup(name string) *Record {
+	nr := r.Copy()
+	if name != "" {
+		// dont share the groups with the old record
+		nr.groups = append(r.groups[:len(r.groups):len(r.groups)], name)
 	}
 	return nr
 }
 
+// get the fields map for add fields, will create the nested map by the groups.
+//
+// NOTICE: the group maps will be copied, so they are not shared with the copied records.
+func (r *Record) groupFields(size int) M {
+	if r.Fields == nil {
+		r.Fields = make(M, size)
+	}
+
+	mp := r.Fields
+	for _, name := range r.groups {
+		sub, _ := mp[name].(M)
+		nm := make(M, len(sub)+size)
+		for k, v := range sub {
+			nm[k] = v
+		}
+
+		mp[name] = nm
+		mp = nm
+	}
+	return mp
+}
+
+// With the typed fields to record. see Field
+//
+// Note: add field need config Formatter template fields.
+func (r *Record) With(fields ...Field) *Record {
+	nr := r.Copy()
+	nr.addTypedFields(fields)
+	return nr
+}
+
 // Copy new record from old record
+//
+// NOTICE: the new record will be taken from the logger pool, the empty maps will not be copied.
 func (r *Record) Copy() *Record {
-	dataCopy := make(M, len(r.Data))
-	for k, v := range r.Data {
-		dataCopy[k] = v
+	var nr *Record
+	if r.logger != nil {
+		nr = r.logger.newRecord()
+	} else {
+		nr = &Record{}
 	}
 
-	fieldsCopy := make(M, len(r.Fields))
-	for k, v := range r.Fields {
-		fieldsCopy[k] = v
+	nr.Ctx = r.Ctx
+	nr.Channel = r.Channel
+	nr.Level = r.Level
+	nr.levelName = r.levelName
+	nr.CallerFlag = r.CallerFlag
+	nr.CallerSkip = r.CallerSkip
+	nr.Message = r.Message
+	nr.Data = copyM(r.Data)
+	nr.Extra = copyM(r.Extra)
+	nr.Fields = copyM(r.Fields)
+	nr.groups = r.groups
+	return nr
+}
+
+// copy the map, returns nil on the map is empty.
+func copyM(mp M) M {
+	if len(mp) == 0 {
+		return nil
 	}
 
-	extraCopy := make(M, len(r.Extra))
-	for k, v := range r.Extra {
-		extraCopy[k] = v
+	cp := make(M, len(mp))
+	for k, v := range mp {
+		cp[k] = v
 	}
+	return cp
+}
 
-	return &Record{
-		// reuse: true, // copy record is reused
-		logger:  r.logger,
-		Channel: r.Channel,
-		// Time:       r.Time,
-		Level:      r.Level,
-		levelName:  r.levelName,
-		CallerFlag: r.CallerFlag,
-		CallerSkip: r.CallerSkip,
-		Message:    r.Message,
-		Data:       dataCopy,
-		Extra:      extraCopy,
-		Fields:     fieldsCopy,
+// Clone a full copy of the record, include time, caller, context and init status.
+//
+// Unlike Copy(), it is used for keep a handled record after write. eg: on async handler
+func (r *Record) Clone() *Record {
+	nr := r.Copy()
+	nr.Time = r.Time
+	nr.inited = r.inited
+	nr.EnableStack = r.EnableStack
+	nr.Fmt, nr.Args = r.Fmt, r.Args
+
+	if r.Caller != nil {
+		caller := *r.Caller
+		nr.Caller = &caller
 	}
+	return nr
 }
 
 //
@@ -187,6 +301,15 @@ func (r *Record) Copy() *Record {
 // ---------------------------------------------------------------------------
 //
 
+// Frozen reports the record is shared read-only by the handlers on Handler.Handle().
+//
+// The logger pass the same record to all handlers, so the handler must not modify it,
+// and must take a copy by Clone() if retains it after Handle() returned(eg: async handle).
+//
+// NOTE: it is only a marker, the record is not locked. The handler level processors
+// (see Processable) still can add fields to it.
+func (r *Record) Frozen() bool { return r.frozen }
+
 // SetCtx on record
 func (r *Record) SetCtx(ctx context.Context) *Record { return r.SetContext(ctx) }
 
@@ -196,6 +319,12 @@ func (r *Record) SetContext(ctx context.Context) *Record {
 	return r
 }
 
+// SetChannel on record
+func (r *Record) SetChannel(name string) *Record {
+	r.Channel = name
+	return r
+}
+
 // SetData on record
 func (r *Record) SetData(data M) *Record {
 	r.Data = data
@@ -303,10 +432,41 @@ func (r *Record) Field(key string) any {
 	return r.Fields[key]
 }
 
+// Msgt render the message template and merge the args into Fields, then log it by level methods without args.
+// the placeholder "{name}" is replaced by args["name"], use "{{" and "}}" for the literal braces.
+//
+// The missing placeholder is rendered literally, or as "<name>" on Logger.MsgtMissingMark is true.
+//
+// Usage:
+//
+//	r.Msgt("user {user_id} did {action}", slog.M{"user_id": 23, "action": "login"}).Info()
+//	// message: "user 23 did login", fields: {"user_id": 23, "action": "login"}
+func (r *Record) Msgt(template string, args M) *Record {
+	var missingMark bool
+	if r.logger != nil {
+		missingMark = r.logger.MsgtMissingMark
+		if r.logger.BackupArgs {
+			r.Fmt = template
+		}
+	}
+
+	r.Message = renderMsgTemplate(template, args, missingMark)
+	r.tplMsg = true
+
+	if len(args) > 0 {
+		if r.Fields == nil {
+			r.Fields = make(M, len(args))
+		}
+		for k, v := range args {
+			r.Fields[k] = v
+		}
+	}
+	return r
+}
+
 //
 // ---------------------------------------------------------------------------
-// Add log message with builder
-// TODO r.Build(InfoLevel).Str().Int().Float().Msg()
+// Add log message with builder. see Builder
 // ---------------------------------------------------------------------------
 //
 
@@ -337,19 +497,36 @@ func (r *Record) Field(key string) any {
 //
 
 func (r *Record) log(level Level, args []any) {
+	// short-circuit, skip format the message. the panic, fatal level always go on.
+	if level > FatalLevel && !r.logger.shouldWrite(level) {
+		r.logger.releaseRecord(r)
+		return
+	}
+
 	r.Level = level
 	if r.logger.BackupArgs {
 		r.Args = args
 	}
 
-	// r.Message = strutil.Byte2str(formatArgsWithSpaces(args)) // will reduce memory allocation once
-	r.Message = formatArgsWithSpaces(args)
+	// keep the message rendered by Msgt()
+	if r.tplMsg && len(args) == 0 {
+		r.tplMsg = false
+	} else {
+		// r.Message = strutil.Byte2str(formatArgsWithSpaces(args)) // will reduce memory allocation once
+		r.Message = formatArgsWithSpaces(args)
+	}
 	// do write log, then release record
-	r.logger.writeRecord(level, r)
-	r.logger.releaseRecord(r)
+	l := r.logger
+	l.writeRecord(level, r)
+	l.panicOrExit(level, r)
 }
 
 func (r *Record) logf(level Level, format string, args []any) {
+	if level > FatalLevel && !r.logger.shouldWrite(level) {
+		r.logger.releaseRecord(r)
+		return
+	}
+
 	if r.logger.BackupArgs {
 		r.Fmt, r.Args = format, args
 	}
@@ -357,13 +534,66 @@ func (r *Record) logf(level Level, format string, args []any) {
 	r.Level = level
 	r.Message = fmt.Sprintf(format, args...)
 	// do write log, then release record
-	r.logger.writeRecord(level, r)
-	r.logger.releaseRecord(r)
+	l := r.logger
+	l.writeRecord(level, r)
+	l.panicOrExit(level, r)
+}
+
+func (r *Record) logAll(level Level, msgs []string) {
+	r.Level, r.tplMsg = level, false
+	if len(msgs) == 0 || level > FatalLevel && !r.logger.shouldWrite(level) {
+		r.logger.releaseRecord(r)
+		return
+	}
+
+	// do write logs, then release record
+	l := r.logger
+	l.writeRecords(level, r, msgs)
+	l.panicOrExit(level, r)
 }
 
 // Log a message with level
 func (r *Record) Log(level Level, args ...any) { r.log(level, args) }
 
+// Timed start a timer, returns a func to log the elapsed time. the level default is PrintLevel.
+//
+// On call the returned func, will add the elapsed time.Duration as the key field(default is FieldKeyDuration),
+// then log the args as message. the message default is the key. the func should only be called once.
+//
+// Usage:
+//
+//	defer logger.WithField("op", "query").Timed("elapsed")()
+//	// or, with the level and message
+//	done := logger.Record().Timed("", slog.DebugLevel)
+//	done("query finished")
+func (r *Record) Timed(key string, level ...Level) func(args ...any) {
+	if key == "" {
+		key = FieldKeyDuration
+	}
+
+	lv := PrintLevel
+	if len(level) > 0 {
+		lv = level[0]
+	}
+
+	start := r.logger.TimeClock.Now()
+	return func(args ...any) {
+		r.AddField(key, r.logger.TimeClock.Now().Sub(start))
+		if len(args) == 0 {
+			args = []any{key}
+		}
+		r.log(lv, args)
+	}
+}
+
+// LogAll write each message as a record with level, the records share the
+// current fields, data and context. it is cheaper than call Log() in a loop.
+//
+// Usage:
+//
+//	l.WithField("batch", id).LogAll(slog.InfoLevel, messages)
+func (r *Record) LogAll(level Level, msgs []string) { r.logAll(level, msgs) }
+
 // Logf a message with level
 func (r *Record) Logf(level Level, format string, args ...any) {
 	r.logf(level, format, args)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -153,6 +154,37 @@ func TestRecord_WithTime(t *testing.T) {
 	fmt.Print(s)
 }
 
+func TestRecord_WithChannel(t *testing.T) {
+	w := newBuffer()
+	l := slog.NewWithHandlers(handler.NewIOWriter(w, slog.AllLevels))
+	l.DoNothingOnPanicFatal()
+
+	r := l.WithChannel("order")
+	assert.Eq(t, "order", r.Channel)
+
+	// the channel survives on chaining
+	nr := r.WithField("id", 23).WithFields(slog.M{"user": "inhere"}).WithData(slog.M{"key": "val"})
+	assert.Eq(t, "order", nr.Channel)
+	assert.Eq(t, "order", nr.Copy().Channel)
+	assert.Eq(t, "order", nr.Clone().Channel)
+
+	nr.Info("order message")
+	assert.Contains(t, w.StringReset(), "[order] [INFO]")
+
+	// the channel output key is configurable
+	h := handler.NewIOWriter(w, slog.AllLevels)
+	h.SetFormatter(slog.NewJSONFormatter(func(f *slog.JSONFormatter) {
+		f.FieldKeys = &slog.FieldKeys{Channel: "logger"}
+	}))
+	l.SetHandlers([]slog.Handler{h})
+	l.WithField("id", 23).WithChannel("goods").Info("goods message")
+	assert.Contains(t, w.StringReset(), `"logger":"goods"`)
+
+	r = newLogRecord("set channel")
+	r.SetChannel("user")
+	assert.Eq(t, "user", r.Channel)
+}
+
 func TestRecord_AddFields(t *testing.T) {
 	r := newLogRecord("AddFields")
 
@@ -181,6 +213,21 @@ func TestRecord_SetFields(t *testing.T) {
 	assert.NotEmpty(t, r.Time)
 }
 
+func TestRecord_Clone(t *testing.T) {
+	r := newLogRecord("clone record")
+	r.SetTime(timex.NowHourStart())
+	r.SetFields(slog.M{"f1": "hi"})
+	r.Init(true)
+
+	nr := r.Clone()
+	assert.Eq(t, r.Time, nr.Time)
+	assert.Eq(t, "info", nr.LevelName())
+	assert.Eq(t, "hi", nr.Field("f1"))
+
+	nr.AddField("f1", "changed")
+	assert.Eq(t, "hi", r.Field("f1"))
+}
+
 func TestRecord_allLevel(t *testing.T) {
 	w := newBuffer()
 	l := slog.NewWithConfig(func(l *slog.Logger) {
@@ -244,3 +291,203 @@ func TestRecord_useMultiTimes(t *testing.T) {
 		wg.Wait()
 	})
 }
+
+func TestRecord_With(t *testing.T) {
+	buf := new(byteutil.Buffer)
+	h := handler.NewIOWriter(buf, slog.AllLevels)
+	h.SetFormatter(slog.NewJSONFormatter(func(f *slog.JSONFormatter) {
+		f.Fields = []string{slog.FieldKeyMessage}
+	}).SetFieldOrder([]string{slog.FieldKeyMessage}))
+
+	l := slog.NewWithHandlers(h)
+	l.With(
+		slog.String("user", "inhere"),
+		slog.Int("age", 23),
+		slog.Int64("id", 1001),
+		slog.Uint64("size", 64),
+		slog.Float64("score", 9.5),
+		slog.Bool("admin", true),
+		slog.Duration("cost", 1500*time.Millisecond),
+		slog.Time("at", time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)),
+		slog.Err(errorx.Raw("some error")),
+		slog.Err(nil),
+		slog.Any("tags", []string{"a"}),
+	).Info("typed fields")
+
+	assert.Eq(t, `{"message":"typed fields","admin":true,"age":23,"at":"2023-01-02T03:04:05Z",`+
+		`"cost":"1.5s","error":"some error","id":1001,"score":9.5,"size":64,"tags":["a"],"user":"inhere"}`+"\n",
+		buf.ResetAndGet())
+
+	// chain with record
+	r := l.Record().With(slog.String("k1", "v1"))
+	r.With(slog.String("k2", "v2")).Info("chained")
+	assert.Eq(t, `{"message":"chained","k1":"v1","k2":"v2"}`+"\n", buf.ResetAndGet())
+}
+
+func TestRecord_Msgt(t *testing.T) {
+	buf := new(byteutil.Buffer)
+	h := handler.NewIOWriter(buf, slog.AllLevels)
+	h.SetFormatter(slog.NewJSONFormatter(func(f *slog.JSONFormatter) {
+		f.Fields = []string{slog.FieldKeyMessage}
+	}).SetFieldOrder([]string{slog.FieldKeyMessage}))
+
+	l := slog.NewWithHandlers(h)
+	l.Msgt("user {user_id} did {action}", slog.M{"user_id": 23, "action": "login"}).Info()
+	assert.Eq(t, `{"message":"user 23 did login","action":"login","user_id":23}`+"\n", buf.ResetAndGet())
+
+	// missing key and escaped braces
+	l.Msgt("{{literal}} {user} {missing}", slog.M{"user": "inhere"}).Warn()
+	assert.Eq(t, `{"message":"{literal} inhere {missing}","user":"inhere"}`+"\n", buf.ResetAndGet())
+
+	l.MsgtMissingMark = true
+	l.Record().Msgt("user {user} from {ip}", slog.M{"user": "inhere"}).Info()
+	assert.Eq(t, `{"message":"user inhere from \u003cip\u003e","user":"inhere"}`+"\n", buf.ResetAndGet())
+
+	// the log args will overwrite the template message
+	l.Msgt("user {user}", slog.M{"user": "inhere"}).Info("other message")
+	assert.Eq(t, `{"message":"other message","user":"inhere"}`+"\n", buf.ResetAndGet())
+}
+
+func TestRecord_Build(t *testing.T) {
+	buf := new(byteutil.Buffer)
+	h := handler.NewIOWriter(buf, slog.AllLevels)
+	h.SetFormatter(slog.NewJSONFormatter(func(f *slog.JSONFormatter) {
+		f.Fields = []string{slog.FieldKeyMessage, slog.FieldKeyLevel, slog.FieldKeyCaller}
+	}).SetFieldOrder([]string{slog.FieldKeyMessage, slog.FieldKeyLevel}))
+
+	l := slog.NewWithHandlers(h)
+	l.ReportCaller = true
+	l.CallerFlag = slog.CallerFlagFcName
+	l.Build(slog.WarnLevel).Str("user", "inhere").Int("age", 23).Err(nil).Msg("built message")
+	assert.Eq(t, `{"message":"built message","level":"WARN","age":23,"caller":"TestRecord_Build","user":"inhere"}`+"\n",
+		buf.ResetAndGet())
+
+	// the base record is not changed
+	base := l.WithField("svc", "order")
+	base.Build(slog.InfoLevel).With(slog.Bool("ok", true)).Msgf("built %s", "message2")
+	assert.Eq(t, `{"message":"built message2","level":"INFO","caller":"TestRecord_Build","ok":true,"svc":"order"}`+"\n",
+		buf.ResetAndGet())
+	assert.Eq(t, slog.M{"svc": "order"}, base.Fields)
+}
+
+func TestRecord_WithGroup(t *testing.T) {
+	buf := new(byteutil.Buffer)
+	h := handler.NewIOWriter(buf, slog.AllLevels)
+	h.SetFormatter(slog.NewJSONFormatter(func(f *slog.JSONFormatter) {
+		f.Fields = []string{slog.FieldKeyMessage}
+	}).SetFieldOrder([]string{slog.FieldKeyMessage}))
+
+	l := slog.NewWithHandlers(h)
+	l.WithGroup("http").WithField("status", 200).Info("grouped")
+	assert.Eq(t, `{"message":"grouped","http":{"status":200}}`+"\n", buf.ResetAndGet())
+
+	// stacked groups
+	base := l.WithField("app", "demo").WithGroup("http")
+	req := base.WithField("method", "GET").WithGroup("req").With(slog.Int("size", 12))
+	req.WithField("path", "/home").Info("stacked")
+	assert.Eq(t, `{"message":"stacked","app":"demo","http":{"method":"GET","req":{"path":"/home","size":12}}}`+"\n", buf.ResetAndGet())
+
+	// AddField always add to the top level
+	l.WithGroup("http").AddField("host", "local").Info("top level")
+	assert.Eq(t, `{"message":"top level","host":"local"}`+"\n", buf.ResetAndGet())
+
+	// the group maps are not shared with the copied records
+	base.WithField("status", 404).Info("not shared")
+	assert.Eq(t, `{"message":"not shared","app":"demo","http":{"status":404}}`+"\n", buf.ResetAndGet())
+
+	// empty group name
+	l.WithGroup("").AddFields(slog.M{"k": "v"}).Info("no group")
+	assert.Eq(t, `{"message":"no group","k":"v"}`+"\n", buf.ResetAndGet())
+
+	// the groups will be reset on new record
+	l.WithField("k", "v").Info("reset")
+	assert.Eq(t, `{"message":"reset","k":"v"}`+"\n", buf.ResetAndGet())
+}
+
+func TestRecord_pooledReset(t *testing.T) {
+	buf := new(byteutil.Buffer)
+	h := handler.NewIOWriter(buf, slog.AllLevels)
+	h.SetFormatter(slog.NewJSONFormatter(func(f *slog.JSONFormatter) {
+		f.Fields = []string{slog.FieldKeyMessage, slog.FieldKeyData, slog.FieldKeyExtra}
+	}).SetFieldOrder([]string{slog.FieldKeyMessage}))
+
+	var lastCtx context.Context
+	l := slog.NewWithHandlers(h)
+	l.AddProcessor(slog.ProcessorFunc(func(r *slog.Record) {
+		lastCtx = r.Ctx
+	}))
+
+	ctx := context.WithValue(context.Background(), "key", "value")
+	l.WithContext(ctx).WithData(slog.M{"key": "value"}).Info("message1")
+	assert.Eq(t, ctx, lastCtx)
+	assert.Eq(t, `{"message":"message1","data":{"key":"value"},"extra":{}}`+"\n", buf.ResetAndGet())
+
+	// the reused record should not keep the old values
+	for i := 0; i < 3; i++ {
+		l.Info("message2")
+		assert.Nil(t, lastCtx)
+		assert.Eq(t, `{"message":"message2","data":{},"extra":{}}`+"\n", buf.ResetAndGet())
+	}
+}
+
+func TestRecord_LogAll(t *testing.T) {
+	w := newBuffer()
+	l := slog.NewWithConfig(func(l *slog.Logger) {
+		l.ReportCaller = true
+		l.CallerFlag = slog.CallerFlagFnLine
+	})
+	h := handler.NewIOWriter(w, slog.AllLevels)
+	h.SetFormatter(slog.NewTextFormatter("{{datetime}} {{level}} {{caller}} {{message}} {{data}}\n").WithEnableColor(false))
+	l.SetHandlers([]slog.Handler{h})
+
+	tm := timex.NowHourStart()
+	l.WithData(slog.M{"batch": 1}).WithTime(tm).LogAll(slog.WarnLevel, []string{"message 1", "message 2", "message 3"})
+
+	lines := strings.Split(strings.TrimSpace(w.StringReset()), "\n")
+	assert.Len(t, lines, 3)
+	for i, line := range lines {
+		assert.StrContains(t, line, "WARN record_test.go:")
+		assert.StrContains(t, line, fmt.Sprintf("message %d {batch:1}", i+1))
+		assert.True(t, strings.HasPrefix(line, tm.Format(slog.DefaultTimeFormat)))
+	}
+
+	// logger
+	l.LogBatch(slog.InfoLevel, []string{"message a", "message b"})
+	s := w.StringReset()
+	assert.Eq(t, 2, strings.Count(s, "INFO record_test.go:"))
+	assert.StrContains(t, s, "message a")
+	assert.StrContains(t, s, "message b")
+
+	// empty
+	l.LogBatch(slog.InfoLevel, nil)
+	assert.Empty(t, w.StringReset())
+}
+
+func TestRecord_Timed(t *testing.T) {
+	w := newBuffer()
+	now := timex.NowHourStart()
+	l := slog.NewWithConfig(func(l *slog.Logger) {
+		l.ReportCaller = true
+		l.CallerFlag = slog.CallerFlagFnLine
+		l.TimeClock = func() time.Time { return now }
+	})
+	h := handler.NewIOWriter(w, slog.AllLevels)
+	h.SetFormatter(slog.NewTextFormatter("{{level}} {{caller}} {{message}} op={{op}} elapsed={{elapsed}} {{duration}}\n"))
+	l.SetHandlers([]slog.Handler{h})
+
+	done := l.WithField("op", "query").Timed("elapsed")
+	now = now.Add(1500 * time.Millisecond)
+	done()
+	s := w.StringReset()
+	assert.True(t, strings.HasPrefix(s, "INFO record_test.go:"))
+	// the time.Duration is rendered as nanoseconds by text formatter
+	assert.StrContains(t, s, " elapsed op=query elapsed=1500000000 duration\n")
+
+	// with level and message
+	done = l.Timed("", slog.DebugLevel)
+	now = now.Add(20 * time.Millisecond)
+	done("query", "finished")
+	s = w.StringReset()
+	assert.True(t, strings.HasPrefix(s, "DEBUG record_test.go:"))
+	assert.StrContains(t, s, " query finished op=op elapsed=elapsed 20000000\n")
+}
@@ -58,22 +58,38 @@ func (rt RotateTime) FirstCheckTime(now time.Time) int64 {
 	case levelMin:
 		// eg: minutes=5
 		minutes := int(interval / 60)
-		nextMin := now.Minute() + minutes
+		// eg: now.Minute()=37, will get nextMin=40
+		nextMin := (now.Minute()/minutes + 1) * minutes
 
-		// eg: now.Minute()=57, nextMin=62.
+		// eg: now.Minute()=57, nextMin=60.
 		// will rotate at next hour start.
 		if nextMin >= 60 {
 			return timex.HourStart(now).Add(timex.OneHour).Unix()
 		}
-
-		// eg: now.Minute()=37, nextMin=42, will get nextDur=40
-		nextDur := time.Duration(nextMin).Round(time.Duration(minutes))
-		return timex.HourStart(now).Add(nextDur).Unix()
+		return timex.HourStart(now).Add(time.Duration(nextMin) * time.Minute).Unix()
 	default: // levelSec
 		return now.Unix() + interval
 	}
 }
 
+// get the end of the period by the due check time, it is the start of next period.
+// the hour, day check time is the last second of period(eg: 15:59:59), the minute, second check time is the end.
+func (rt RotateTime) periodEnd(dueTime time.Time) time.Time {
+	if rt.level() <= levelHour {
+		return dueTime.Add(time.Second)
+	}
+	return dueTime
+}
+
+// get the start of the period by the due check time. eg: 15:59:59 => 15:00:00, 15:40:00 => 15:35:00(Every5Min)
+func (rt RotateTime) periodStart(dueTime time.Time) time.Time {
+	if rt.level() <= levelHour {
+		// format by the suffix is enough, avoid the DST issue on the day level
+		return dueTime
+	}
+	return dueTime.Add(-time.Duration(rt.Interval()) * time.Second)
+}
+
 // level for rotate time
 func (rt RotateTime) level() rotateLevel {
 	switch {
@@ -139,6 +155,13 @@ func (fn ClockFn) Now() time.Time {
 	return fn()
 }
 
+// ClockInLocation create a clock, the current time will be in the location.
+func ClockInLocation(loc *time.Location) ClockFn {
+	return func() time.Time {
+		return time.Now().In(loc)
+	}
+}
+
 // ConfigFn for setting config
 type ConfigFn func(c *Config)
 
@@ -150,6 +173,14 @@ type Config struct {
 	// FilePerm for create log file. default DefaultFilePerm
 	FilePerm os.FileMode `json:"file_perm" yaml:"file_perm"`
 
+	// SymlinkPath create a symlink to the current active log file, will be updated after each rotation.
+	// eg: "logs/current.log", then can use `tail -F logs/current.log`
+	//
+	// if the platform not support symlink(eg: Windows without privilege), will print a warning and ignore it.
+	//
+	// NOTICE: the name should not match the backup files pattern "Filepath.*", it will be cleaned.
+	SymlinkPath string `json:"symlink_path" yaml:"symlink_path"`
+
 	// RotateMode for rotate file. default ModeRename
 	RotateMode RotateMode `json:"rotate_mode" yaml:"rotate_mode"`
 
@@ -165,6 +196,18 @@ type Config struct {
 	// default: EveryHour
 	RotateTime RotateTime `json:"rotate_time" yaml:"rotate_time"`
 
+	// BufferSize wrap the file by a bufio.Writer with the size, unit is bytes.
+	// the buffer is flushed on full, rotating, Flush() and Close().
+	//
+	// the buffered bytes are counted for rotate by size. 0 is not buffered, default is 0.
+	BufferSize uint `json:"buffer_size" yaml:"buffer_size"`
+
+	// FlushInterval flush the buffer and sync the file to disk on each interval in a background goroutine.
+	// it bounds the data loss on crash to the interval. the goroutine is stopped on Close().
+	//
+	// NOTICE: only works on CloseLock=false, the flush is in the lock. 0 is disabled, default is 0.
+	FlushInterval time.Duration `json:"flush_interval" yaml:"flush_interval"`
+
 	// CloseLock use sync lock on write contents, rotating file.
 	//
 	// default: false
@@ -180,10 +223,40 @@ type Config struct {
 	// 0 is not limit, default is DefaultBackTime
 	BackupTime uint `json:"backup_time" yaml:"backup_time"`
 
+	// MaxTotalSize max total size for keep old files, unit is MB.
+	// will remove the oldest files until the total size is under the limit.
+	// the current active file is not counted.
+	//
+	// 0 is not limit.
+	MaxTotalSize uint `json:"max_total_size" yaml:"max_total_size"`
+
 	// Compress determines if the rotated log files should be compressed using gzip.
 	// The default is not to perform compression.
 	Compress bool `json:"compress" yaml:"compress"`
 
+	// CompressLevel the gzip compression level for compress rotated files.
+	// allow: gzip.BestSpeed(1) - gzip.BestCompression(9), gzip.HuffmanOnly(-2)
+	//
+	// 0 is use gzip.DefaultCompression.
+	CompressLevel int `json:"compress_level" yaml:"compress_level"`
+
+	// AsyncCompress compress the rotated file in a background goroutine right after rotating,
+	// so the rotate completes quickly. the Close() will wait for the in-flight compression.
+	//
+	// NOTICE: only works on Compress=true
+	AsyncCompress bool `json:"async_compress" yaml:"async_compress"`
+
+	// CompressAge the Writer.CompressBacklog() only compress the backups older than it, unit is hours.
+	//
+	// 0 is compress all uncompressed backups.
+	CompressAge uint `json:"compress_age" yaml:"compress_age"`
+
+	// CompressKeepUncompressed keep the newest N backups uncompressed on Clean(), only compress the older backups.
+	// it is useful for fast access to the recent logs. the AsyncCompress will not compress the file right after rotating.
+	//
+	// NOTICE: only works on Compress=true. 0 is compress all backups.
+	CompressKeepUncompressed uint `json:"compress_keep_uncompressed" yaml:"compress_keep_uncompressed"`
+
 	// RenameFunc you can custom-build filename for rotate file by size.
 	//
 	// default see DefaultFilenameFn
@@ -97,3 +97,31 @@ func TestRotateTime_FirstCheckTime_Round(t *testing.T) {
 	dur = time.Duration(nowMin + logMin)
 	assert.Eq(t, time.Duration(45), dur.Round(time.Duration(logMin)))
 }
+
+func TestRotateTime_FirstCheckTime(t *testing.T) {
+	now := time.Date(2024, 1, 2, 14, 37, 20, 0, time.Local)
+	tests := map[rotatefile.RotateTime]time.Time{
+		rotatefile.EveryMinute * 5: time.Date(2024, 1, 2, 14, 40, 0, 0, time.Local),
+		rotatefile.Every15Min:      time.Date(2024, 1, 2, 14, 45, 0, 0, time.Local),
+		rotatefile.Every30Min:      time.Date(2024, 1, 2, 15, 0, 0, 0, time.Local),
+		rotatefile.EveryHour:       time.Date(2024, 1, 2, 14, 59, 59, 0, time.Local),
+		rotatefile.EveryDay:        time.Date(2024, 1, 2, 23, 59, 59, 0, time.Local),
+	}
+	for rt, want := range tests {
+		assert.Eq(t, want.Unix(), rt.FirstCheckTime(now), rt.String())
+	}
+
+	// on the boundary, is the next boundary
+	now = time.Date(2024, 1, 2, 14, 40, 0, 0, time.Local)
+	assert.Eq(t, time.Date(2024, 1, 2, 14, 45, 0, 0, time.Local).Unix(), (rotatefile.EveryMinute * 5).FirstCheckTime(now))
+}
+
+func TestClockInLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	c := rotatefile.NewConfigWith(func(c *rotatefile.Config) {
+		c.TimeClock = rotatefile.ClockInLocation(loc)
+	})
+
+	assert.Eq(t, loc, c.TimeClock.Now().Location())
+	assert.Eq(t, time.Local, rotatefile.DefaultTimeClockFn.Now().Location())
+}
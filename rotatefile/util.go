@@ -5,41 +5,88 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"strconv"
 
 	"github.com/gookit/goutil/fsutil"
 )
 
 const compressSuffix = ".gz"
 
-func compressFile(srcPath, dstPath string) error {
+// the temp file suffix on compressing. eg: error.log.001.gz.tmp
+const compressTmpSuffix = compressSuffix + ".tmp"
+
+// max tries for increase the rotateNum to find a free backup filename
+const maxRenameTries = 100
+
+// check the backup file is exists, includes the compressed file.
+func backupExists(bakFile string) bool {
+	return fsutil.PathExists(bakFile) || fsutil.PathExists(bakFile+compressSuffix)
+}
+
+// uniqueBakFile returns a not exists backup filename, will append a counter on the file exists.
+//
+// eg: /tmp/error.log.20220423_1600 => /tmp/error.log.20220423_1600_1
+func uniqueBakFile(bakFile string) string {
+	if !backupExists(bakFile) {
+		return bakFile
+	}
+
+	for i := 1; ; i++ {
+		name := bakFile + "_" + strconv.Itoa(i)
+		if !backupExists(name) {
+			return name
+		}
+	}
+}
+
+// compress the file by gzip. level 0 is use gzip.DefaultCompression
+func compressFile(srcPath, dstPath string, level int) error {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	// check the level before create the gz file
+	zw, err := gzip.NewWriterLevel(nil, level)
+	if err != nil {
+		return err
+	}
+
 	srcFile, err := os.OpenFile(srcPath, os.O_RDONLY, 0)
 	if err != nil {
 		return err
 	}
 	defer srcFile.Close()
 
-	// create and open a gz file
-	gzFile, err := fsutil.OpenTruncFile(dstPath)
+	srcSt, err := srcFile.Stat()
 	if err != nil {
 		return err
 	}
-	defer gzFile.Close()
 
-	srcSt, err := srcFile.Stat()
+	// write to a temp file, then rename it. avoid leaving a broken gz file on failed.
+	tmpPath := dstPath + ".tmp"
+	gzFile, err := fsutil.OpenTruncFile(tmpPath)
 	if err != nil {
 		return err
 	}
 
-	zw := gzip.NewWriter(gzFile)
+	zw.Reset(gzFile)
 	zw.Name = srcSt.Name()
 	zw.ModTime = srcSt.ModTime()
 
 	// do copy
-	if _, err = io.Copy(zw, srcFile); err != nil {
-		_ = zw.Close()
+	_, err = io.Copy(zw, srcFile)
+	if cErr := zw.Close(); err == nil {
+		err = cErr
+	}
+	if cErr := gzFile.Close(); err == nil {
+		err = cErr
+	}
+
+	if err != nil {
+		_ = os.Remove(tmpPath)
 		return err
 	}
-	return zw.Close()
+	return os.Rename(tmpPath, dstPath)
 }
 
 // TODO replace to fsutil.FileInfo
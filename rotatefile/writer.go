@@ -1,13 +1,17 @@
 package rotatefile
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path"
+	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gookit/goutil/errorx"
@@ -24,6 +28,10 @@ type Writer struct {
 	// current opened logfile
 	file *os.File
 	path string
+	// the buffer of the file, is nil on Config.BufferSize=0
+	buf *bufio.Writer
+	// the current active file name, for exclude it on async clean.
+	activeName atomic.Value
 	// logfile dir path for the Config.Filepath
 	fileDir string
 
@@ -32,15 +40,46 @@ type Writer struct {
 	// oldFiles []string
 	cleanCh chan struct{}
 	stopCh  chan struct{}
+	// in-flight async compress files, key is the file name
+	compressing sync.Map
+	compressWg  sync.WaitGroup
+	// stop the flush daemon. see Config.FlushInterval
+	flushStopCh chan struct{}
+	flushWg     sync.WaitGroup
 
 	// context use for rotating file by size
-	written   uint64 // written size
+	written   uint64 // written size of the current file
 	rotateNum uint   // rotate times number
 
 	// context use for rotating file by time
 	suffixFormat   string // the rotating file name suffix. eg: "20210102", "20210102_1500"
 	checkInterval  int64  // check interval seconds.
 	nextRotatingAt int64
+	// the mod time of the exists logfile on startup, if it is from a previous period.
+	// will rotate it on the first write. only for ModeRename.
+	staleTime time.Time
+
+	// the stats counters, can be read by Stats() without lock
+	statSize      atomic.Uint64
+	statWritten   atomic.Uint64
+	statRotations atomic.Uint64
+	statBackups   atomic.Int64
+	statRotatedAt atomic.Int64 // unix nano
+}
+
+// Stats of the Writer. see Writer.Stats()
+type Stats struct {
+	// Size of the current logfile
+	Size uint64
+	// Written bytes number since the writer created
+	Written uint64
+	// Rotations number since the writer created
+	Rotations uint64
+	// Backups number of the backup files. it is counted on startup,
+	// then updated on rotating and cleaning, the files removed by others are not observed.
+	Backups int64
+	// LastRotated the last rotating time. is zero if not rotated
+	LastRotated time.Time
 }
 
 // NewWriter create rotate write with config and init it.
@@ -83,7 +122,40 @@ func (d *Writer) init() error {
 	}
 
 	// open the logfile
-	return d.openFile(logfile)
+	if err := d.openFile(logfile); err != nil {
+		return err
+	}
+	d.statBackups.Store(d.countBackups())
+
+	if d.cfg.FlushInterval > 0 && !d.cfg.CloseLock {
+		d.flushStopCh = make(chan struct{})
+		d.flushWg.Add(1)
+		go d.flushDaemon()
+	}
+
+	if d.checkInterval > 0 && d.cfg.RotateMode == ModeRename {
+		return d.checkStaleFile()
+	}
+	return nil
+}
+
+// check the exists logfile is from a previous period. eg: the process is down across midnight.
+func (d *Writer) checkStaleFile() error {
+	if d.written == 0 {
+		return nil
+	}
+
+	fi, err := d.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	now := d.cfg.TimeClock.Now()
+	modTime := fi.ModTime().In(now.Location())
+	if d.cfg.RotateTime.FirstCheckTime(modTime) <= now.Unix() {
+		d.staleTime = modTime
+	}
+	return nil
 }
 
 // Config get the config
@@ -91,24 +163,59 @@ func (d *Writer) Config() Config {
 	return *d.cfg
 }
 
-// Flush sync data to disk. alias of Sync()
+// Stats get the stats of the writer. it is safe to call concurrently with writing.
+//
+// The counters are maintained on writing and rotating, will not stat the filesystem.
+func (d *Writer) Stats() Stats {
+	st := Stats{
+		Size:      d.statSize.Load(),
+		Written:   d.statWritten.Load(),
+		Rotations: d.statRotations.Load(),
+		Backups:   d.statBackups.Load(),
+	}
+
+	if ns := d.statRotatedAt.Load(); ns > 0 {
+		st.LastRotated = time.Unix(0, ns)
+	}
+	return st
+}
+
+// Flush the buffered data and sync data to disk. alias of Sync()
 func (d *Writer) Flush() error {
-	return d.file.Sync()
+	return d.Sync()
 }
 
-// Sync data to disk.
+// Sync the buffered data and sync data to disk.
 func (d *Writer) Sync() error {
+	if d.buf != nil {
+		if !d.cfg.CloseLock {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+		}
+
+		if err := d.buf.Flush(); err != nil {
+			return err
+		}
+	}
 	return d.file.Sync()
 }
 
 // Close the writer. will sync data to disk, then close the file handle.
-// and will stop the async clean backups.
+// and will stop the async clean backups and the flush daemon, wait for the in-flight compression.
 func (d *Writer) Close() error {
-	return d.close(true)
+	if d.flushStopCh != nil {
+		close(d.flushStopCh)
+		d.flushWg.Wait()
+		d.flushStopCh = nil
+	}
+
+	err := d.close(true)
+	d.compressWg.Wait()
+	return err
 }
 
 func (d *Writer) close(closeStopCh bool) error {
-	if err := d.file.Sync(); err != nil {
+	if err := d.flushSync(); err != nil {
 		return err
 	}
 
@@ -121,6 +228,35 @@ func (d *Writer) close(closeStopCh bool) error {
 	return d.file.Close()
 }
 
+// flush the buffer and sync the file. should be in lock
+func (d *Writer) flushSync() error {
+	if d.buf != nil {
+		if err := d.buf.Flush(); err != nil {
+			return err
+		}
+	}
+	return d.file.Sync()
+}
+
+// flush and sync the file on each Config.FlushInterval, until Close()
+func (d *Writer) flushDaemon() {
+	defer d.flushWg.Done()
+	tk := time.NewTicker(d.cfg.FlushInterval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			d.mu.Lock()
+			err := d.flushSync()
+			d.mu.Unlock()
+			printErrln("rotatefile: flush daemon error:", err)
+		case <-d.flushStopCh:
+			return
+		}
+	}
+}
+
 //
 // ---------------------------------------------------------------------------
 // write and rotate file
@@ -140,13 +276,41 @@ func (d *Writer) Write(p []byte) (n int, err error) {
 		defer d.mu.Unlock()
 	}
 
-	n, err = d.file.Write(p)
+	// rotate the logfile from a previous period before the first write.
+	if !d.staleTime.IsZero() {
+		if err = d.rotatingStale(); err != nil {
+			return
+		}
+	}
+
+	// rotate before write on the time boundary is passed, the data belongs to the new period.
+	if d.checkInterval > 0 && d.written > 0 && d.periodPassed() {
+		if err = d.rotatingByTime(); err != nil {
+			return
+		}
+	}
+
+	// rotate before write, avoid the file size exceeds the MaxSize.
+	// a single write larger than MaxSize will be written to a fresh file.
+	if d.cfg.MaxSize > 0 && d.written > 0 && d.written+uint64(len(p)) > d.cfg.MaxSize {
+		if err = d.rotatingBySize(); err != nil {
+			return
+		}
+	}
+
+	if d.buf != nil {
+		n, err = d.buf.Write(p)
+	} else {
+		n, err = d.file.Write(p)
+	}
 	if err != nil {
 		return
 	}
 
-	// update written size
+	// update written size, include the buffered bytes
 	d.written += uint64(n)
+	d.statWritten.Add(uint64(n))
+	d.statSize.Store(d.written)
 
 	// rotate file
 	err = d.doRotate()
@@ -156,19 +320,74 @@ func (d *Writer) Write(p []byte) (n int, err error) {
 // Rotate the file by config and async clean backups
 func (d *Writer) Rotate() error { return d.doRotate() }
 
-// do rotate the logfile by config and async clean backups
-func (d *Writer) doRotate() (err error) {
-	// do rotate file by size
-	if d.cfg.MaxSize > 0 && d.written >= d.cfg.MaxSize {
-		err = d.rotatingBySize()
-		if err != nil {
-			return
+// Reopen sync and close the current file handle, then reopen the logfile.
+//
+// Useful for the logfile has been renamed or moved by external tools(eg: logrotate).
+// Can be wired to a signal handler, eg:
+//
+//	sigCh := make(chan os.Signal, 1)
+//	signal.Notify(sigCh, syscall.SIGHUP)
+//	go func() {
+//		for range sigCh {
+//			_ = w.Reopen()
+//		}
+//	}()
+func (d *Writer) Reopen() error {
+	if !d.cfg.CloseLock {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+	}
+
+	if err := d.close(false); err != nil {
+		return err
+	}
+
+	return d.openFile(d.path)
+}
+
+// Truncate flush the buffered data, then truncate the current logfile to zero and reset the size counter.
+// it is different to Rotate(), no backup file is created. useful for reset the logs in tests or CI.
+//
+// NOTICE: it is guarded by the lock on CloseLock=false, should not call it concurrently with Write() on
+// CloseLock=true. the other processes or readers(eg: tail -f) holding the logfile will see the truncated file.
+func (d *Writer) Truncate() error {
+	if !d.cfg.CloseLock {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+	}
+
+	if d.buf != nil {
+		if err := d.buf.Flush(); err != nil {
+			return err
 		}
 	}
+	if err := d.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := d.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	d.written = 0
+	d.statSize.Store(0)
+	return nil
+}
 
+// do rotate the logfile by config and async clean backups.
+//
+// on both MaxSize and RotateTime are set, will rotate on EITHER is hit.
+// the time boundary takes precedence, the file is fresh after rotated by time.
+func (d *Writer) doRotate() (err error) {
 	// do rotate file by time
 	if d.checkInterval > 0 && d.written > 0 {
-		err = d.rotatingByTime()
+		if err = d.rotatingByTime(); err != nil {
+			return
+		}
+	}
+
+	// do rotate file by size
+	if d.cfg.MaxSize > 0 && d.written >= d.cfg.MaxSize {
+		err = d.rotatingBySize()
 	}
 
 	// async clean backup files. TODO only call on file rotated.
@@ -183,35 +402,81 @@ func (d *Writer) rotatingByTime() error {
 		return nil
 	}
 
-	// generate new file path.
-	// eg: /tmp/error.log => /tmp/error.log.20220423_1600
-	file := d.cfg.Filepath + "." + now.Format(d.suffixFormat)
+	// generate new file path. the suffix is by the due check time, not the write time.
+	// so the name is correct even if the first write is late after the time boundary.
+	//
+	// ModeRename: the backup file of the finished period, named by the period start.
+	// eg: /tmp/error.log => /tmp/error.log.20220423_1500
+	// ModeCreate: the file of the new period. eg: /tmp/error.log.20220423_1600
+	dueTime := time.Unix(d.nextRotatingAt, 0).In(now.Location())
+	var file string
+	if d.cfg.RotateMode == ModeRename {
+		file = uniqueBakFile(d.cfg.Filepath + "." + d.cfg.RotateTime.periodStart(dueTime).Format(d.suffixFormat))
+	} else {
+		if periodAt := d.cfg.RotateTime.periodEnd(dueTime); now.Before(periodAt) {
+			now = periodAt
+		}
+		file = d.cfg.Filepath + "." + now.Format(d.suffixFormat)
+	}
 	err := d.rotatingFile(file, false)
 
-	// storage next rotating time
-	d.nextRotatingAt = now.Unix() + d.checkInterval
+	// storage next rotating time, it is aligned to the time boundary.
+	// the rotating by size in the period does not change it.
+	d.nextRotatingAt = d.cfg.RotateTime.FirstCheckTime(now)
+	if d.nextRotatingAt <= now.Unix() {
+		d.nextRotatingAt = d.cfg.RotateTime.FirstCheckTime(now.Add(time.Duration(d.checkInterval) * time.Second))
+	}
+	// the size backups are numbered in each period.
+	d.rotateNum = 0
 	return err
 }
 
+// check the current time has passed the end of the period of the current file.
+func (d *Writer) periodPassed() bool {
+	now := d.cfg.TimeClock.Now()
+	dueTime := time.Unix(d.nextRotatingAt, 0).In(now.Location())
+	return !now.Before(d.cfg.RotateTime.periodEnd(dueTime))
+}
+
+// rotate the stale logfile, the backup file name is by the file mod time.
+// eg: /tmp/error.log => /tmp/error.log.20220422
+func (d *Writer) rotatingStale() error {
+	file := d.cfg.Filepath + "." + d.staleTime.Format(d.suffixFormat)
+	d.staleTime = time.Time{}
+	return d.rotatingFile(uniqueBakFile(file), false)
+}
+
 func (d *Writer) rotatingBySize() error {
+	bakFile := d.sizeBakFile()
+
+	// avoid overwriting the exists backup. eg: rotate multi times in same second, or restart the app.
+	for i := 0; backupExists(bakFile) && i < maxRenameTries; i++ {
+		bakFile = d.sizeBakFile()
+	}
+
+	// always rename current to new file
+	return d.rotatingFile(uniqueBakFile(bakFile), true)
+}
+
+// increase the rotateNum and build backup filename for rotating by size
+func (d *Writer) sizeBakFile() string {
 	d.rotateNum++
 
-	var bakFile string
 	if d.cfg.IsMode(ModeCreate) {
 		// eg: /tmp/error.log.20220423_1600 => /tmp/error.log.20220423_1600_001
-		bakFile = fmt.Sprintf("%s_%03d", d.path, d.rotateNum)
-	} else {
-		// rename current to new file
-		// eg: /tmp/error.log => /tmp/error.log.163021_001
-		bakFile = d.cfg.RenameFunc(d.cfg.Filepath, d.rotateNum)
+		return fmt.Sprintf("%s_%03d", d.path, d.rotateNum)
 	}
 
-	// always rename current to new file
-	return d.rotatingFile(bakFile, true)
+	// rename current to new file
+	// eg: /tmp/error.log => /tmp/error.log.163021_001
+	return d.cfg.RenameFunc(d.cfg.Filepath, d.rotateNum)
 }
 
 // rotateFile closes the syncBuffer's file and starts a new one.
 func (d *Writer) rotatingFile(bakFile string, rename bool) error {
+	// the finished file after rotated
+	doneFile := d.path
+
 	// close the current file
 	if err := d.close(false); err != nil {
 		return err
@@ -225,12 +490,16 @@ func (d *Writer) rotatingFile(bakFile string, rename bool) error {
 		if err := os.Rename(d.path, bakFile); err != nil {
 			return err
 		}
+		doneFile = bakFile
 	}
 
 	// filepath for reopen
 	logfile := d.path
 	if d.cfg.RotateMode == ModeRename {
 		logfile = d.cfg.Filepath
+	} else if !rename {
+		// on ModeCreate and rotate by time, the bakFile is the new period file.
+		logfile = bakFile
 	}
 
 	// reopen log file
@@ -238,23 +507,71 @@ func (d *Writer) rotatingFile(bakFile string, rename bool) error {
 		return err
 	}
 
-	// reset written
-	d.written = 0
+	// the done file is a backup now
+	d.statRotations.Add(1)
+	d.statBackups.Add(1)
+	d.statRotatedAt.Store(d.cfg.TimeClock.Now().UnixNano())
+
+	// the newest backups are kept uncompressed, the older backups will be compressed on Clean()
+	if d.cfg.Compress && d.cfg.AsyncCompress && d.cfg.CompressKeepUncompressed == 0 {
+		d.asyncCompress(doneFile)
+	}
 	return nil
 }
 
-// open the log file. and set the d.file, d.path
+// open the log file. and set the d.file, d.path, d.written
 func (d *Writer) openFile(logfile string) error {
 	file, err := fsutil.OpenFile(logfile, DefaultFileFlags, d.cfg.FilePerm)
 	if err != nil {
 		return err
 	}
 
+	// init written size by the exists file contents
+	fi, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
 	d.path = logfile
 	d.file = file
+	d.written = uint64(fi.Size())
+	if d.cfg.BufferSize > 0 {
+		if d.buf == nil {
+			d.buf = bufio.NewWriterSize(file, int(d.cfg.BufferSize))
+		} else {
+			d.buf.Reset(file)
+		}
+	}
+	d.statSize.Store(d.written)
+	d.activeName.Store(path.Base(logfile))
+
+	if d.cfg.SymlinkPath != "" {
+		printErrln("rotatefile: update symlink error:", d.updateSymlink())
+	}
 	return nil
 }
 
+// update the symlink to current log file. create a temp link then rename it, for atomic replace.
+func (d *Writer) updateSymlink() error {
+	target, err := filepath.Abs(d.path)
+	if err != nil {
+		return err
+	}
+
+	// already points to the current file
+	if old, err := os.Readlink(d.cfg.SymlinkPath); err == nil && old == target {
+		return nil
+	}
+
+	tmpLink := d.cfg.SymlinkPath + ".tmp"
+	_ = os.Remove(tmpLink)
+	if err = os.Symlink(target, tmpLink); err != nil {
+		return err
+	}
+	return os.Rename(tmpLink, d.cfg.SymlinkPath)
+}
+
 //
 // ---------------------------------------------------------------------------
 // clean backup files
@@ -263,7 +580,7 @@ func (d *Writer) openFile(logfile string) error {
 
 // async clean old files by config. should be in lock.
 func (d *Writer) asyncClean() {
-	if d.cfg.BackupNum == 0 && d.cfg.BackupTime == 0 {
+	if d.cfg.BackupNum == 0 && d.cfg.BackupTime == 0 && d.cfg.MaxTotalSize == 0 {
 		return
 	}
 
@@ -304,8 +621,8 @@ func (d *Writer) asyncClean() {
 
 // Clean old files by config
 func (d *Writer) Clean() (err error) {
-	if d.cfg.BackupNum == 0 && d.cfg.BackupTime == 0 {
-		return errorx.Err("clean: backupNum and backupTime are both 0")
+	if d.cfg.BackupNum == 0 && d.cfg.BackupTime == 0 && d.cfg.MaxTotalSize == 0 {
+		return errorx.Err("clean: backupNum, backupTime and maxTotalSize are all 0")
 	}
 
 	// oldFiles: xx.log.yy files, no gz file
@@ -329,7 +646,10 @@ func (d *Writer) Clean() (err error) {
 
 	gzNum := len(gzFiles)
 	oldNum := len(oldFiles)
-	remNum := gzNum + oldNum - int(d.cfg.BackupNum)
+	var remNum int
+	if d.cfg.BackupNum > 0 {
+		remNum = gzNum + oldNum - int(d.cfg.BackupNum)
+	}
 	d.cfg.Debug("clean old files, gzNum:", gzNum, "oldNum:", oldNum, "remNum:", remNum)
 
 	if remNum > 0 {
@@ -338,17 +658,20 @@ func (d *Writer) Clean() (err error) {
 			sort.Sort(modTimeFInfos(gzFiles)) // sort by mod-time
 			d.cfg.Debug("remove old gz files ...")
 
-			for idx := 0; idx < gzNum; idx++ {
-				if err = os.Remove(gzFiles[idx].filePath); err != nil {
+			var removed int
+			for _, fi := range gzFiles {
+				if err = os.Remove(fi.filePath); err != nil {
 					break
 				}
 
+				removed++
 				remNum--
 				if remNum == 0 {
 					break
 				}
 			}
 
+			gzFiles = gzFiles[removed:]
 			if err != nil {
 				return errorx.Wrap(err, "remove old gz file error")
 			}
@@ -379,24 +702,151 @@ func (d *Writer) Clean() (err error) {
 		}
 	}
 
+	// remove the oldest files by max total size
+	remains := len(gzFiles) + len(oldFiles)
+	if d.cfg.MaxTotalSize > 0 {
+		var removed int
+		if oldFiles, removed, err = d.cleanByTotalSize(gzFiles, oldFiles); err != nil {
+			return errorx.Wrap(err, "remove old file by total size error")
+		}
+		remains -= removed
+	}
+	d.statBackups.Store(int64(remains + d.compressingNum()))
+
+	// keep the newest N backups uncompressed
+	if keepNum := int(d.cfg.CompressKeepUncompressed); d.cfg.Compress && keepNum > 0 {
+		if len(oldFiles) <= keepNum {
+			return
+		}
+
+		sort.Sort(modTimeFInfos(oldFiles)) // oldest at first
+		oldFiles = oldFiles[:len(oldFiles)-keepNum]
+	}
+
 	if d.cfg.Compress && len(oldFiles) > 0 {
 		d.cfg.Debug("compress old normal files to gz files")
-		err = d.compressFiles(oldFiles)
+		if d.cfg.AsyncCompress {
+			for _, fi := range oldFiles {
+				d.asyncCompress(fi.filePath)
+			}
+		} else {
+			err = d.compressFiles(oldFiles)
+		}
 	}
 	return
 }
 
-func (d *Writer) buildFilterFns(fileName string) []fsutil.FilterFunc {
-	filterFns := []fsutil.FilterFunc{
-		fsutil.OnlyFindFile,
-		// filter by name. match pattern like: error.log.*
-		// eg: error.log.xx, error.log.xx.gz
-		func(fPath string, ent fs.DirEntry) bool {
-			ok, _ := path.Match(fileName+".*", ent.Name())
-			return ok
-		},
+// CompressBacklog compress the uncompressed backups older than Config.CompressAge, then remove the originals.
+//
+// Useful for the backups rotated before enable Compress. the current active file and
+// the compressing files will be skipped. it works without Config.Compress.
+func (d *Writer) CompressBacklog() error {
+	fileDir, fileName := path.Split(d.cfg.Filepath)
+	filterFns := d.backupFilterFns(fileName)
+
+	// exclude the gz files and the files newer than CompressAge
+	cutTime := d.cfg.TimeClock.Now().Add(-time.Duration(d.cfg.CompressAge) * time.Hour)
+	filterFns = append(filterFns, func(fPath string, ent fs.DirEntry) bool {
+		if strings.HasSuffix(ent.Name(), compressSuffix) {
+			return false
+		}
+
+		fi, err := ent.Info()
+		return err == nil && !fi.ModTime().After(cutTime)
+	})
+
+	var files []string
+	err := fsutil.FindInDir(fileDir, func(fPath string, _ fs.DirEntry) error {
+		files = append(files, fPath)
+		return nil
+	}, filterFns...)
+	if err != nil {
+		return err
+	}
+
+	for _, fPath := range files {
+		// skip on the file is compressing by async compress
+		name := path.Base(fPath)
+		if _, loaded := d.compressing.LoadOrStore(name, true); loaded {
+			continue
+		}
+
+		d.cfg.Debug("compress backlog file:", fPath)
+		err = d.compressFile(fPath)
+		d.compressing.Delete(name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove the oldest files until the total size is under MaxTotalSize.
+// returns the remaining old normal files and the removed files number.
+func (d *Writer) cleanByTotalSize(gzFiles, oldFiles []fileInfo) ([]fileInfo, int, error) {
+	files := make([]fileInfo, 0, len(gzFiles)+len(oldFiles))
+	files = append(files, gzFiles...)
+	files = append(files, oldFiles...)
+
+	var total int64
+	for _, fi := range files {
+		total += fi.Size()
+	}
+
+	maxSize := int64(d.cfg.MaxTotalSize) * int64(OneMByte)
+	d.cfg.Debug("clean old files by total size, total:", total, "maxSize:", maxSize)
+	if total <= maxSize {
+		return oldFiles, 0, nil
+	}
+
+	sort.Sort(modTimeFInfos(files)) // oldest at first
+
+	removed := make(map[string]bool)
+	for _, fi := range files {
+		if total <= maxSize {
+			break
+		}
+		if err := os.Remove(fi.filePath); err != nil {
+			return oldFiles, len(removed), err
+		}
+
+		total -= fi.Size()
+		removed[fi.filePath] = true
 	}
 
+	remains := oldFiles[:0]
+	for _, fi := range oldFiles {
+		if !removed[fi.filePath] {
+			remains = append(remains, fi)
+		}
+	}
+	return remains, len(removed), nil
+}
+
+// count the backup files, include the compressing files.
+func (d *Writer) countBackups() int64 {
+	fileDir, fileName := path.Split(d.cfg.Filepath)
+
+	var num int
+	_ = fsutil.FindInDir(fileDir, func(_ string, _ fs.DirEntry) error {
+		num++
+		return nil
+	}, d.backupFilterFns(fileName)...)
+	return int64(num + d.compressingNum())
+}
+
+// the in-flight compress files number
+func (d *Writer) compressingNum() (num int) {
+	d.compressing.Range(func(_, _ any) bool {
+		num++
+		return true
+	})
+	return
+}
+
+func (d *Writer) buildFilterFns(fileName string) []fsutil.FilterFunc {
+	filterFns := d.backupFilterFns(fileName)
+
 	// filter by mod-time, clear expired files
 	if d.cfg.BackupTime > 0 {
 		cutTime := d.cfg.TimeClock.Now().Add(-d.backupDur)
@@ -420,17 +870,67 @@ func (d *Writer) buildFilterFns(fileName string) []fsutil.FilterFunc {
 	return filterFns
 }
 
+// filter the backup files, exclude the current active file and the compressing files.
+func (d *Writer) backupFilterFns(fileName string) []fsutil.FilterFunc {
+	return []fsutil.FilterFunc{
+		fsutil.OnlyFindFile,
+		// filter by name. match pattern like: error.log.*
+		// eg: error.log.xx, error.log.xx.gz
+		func(fPath string, ent fs.DirEntry) bool {
+			ok, _ := path.Match(fileName+".*", ent.Name())
+			return ok
+		},
+		// exclude the current active file. eg: on ModeCreate
+		func(fPath string, ent fs.DirEntry) bool {
+			return ent.Name() != d.activeName.Load()
+		},
+		// exclude the compressing files
+		func(fPath string, ent fs.DirEntry) bool {
+			if strings.HasSuffix(ent.Name(), compressTmpSuffix) {
+				return false
+			}
+			_, ok := d.compressing.Load(ent.Name())
+			return !ok
+		},
+	}
+}
+
 func (d *Writer) compressFiles(oldFiles []fileInfo) error {
 	for _, fi := range oldFiles {
-		err := compressFile(fi.filePath, fi.filePath+compressSuffix)
-		if err != nil {
-			return errorx.Wrap(err, "compress old file error")
+		if err := d.compressFile(fi.filePath); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		// remove old log file
-		if err = os.Remove(fi.filePath); err != nil {
-			return errorx.Wrap(err, "remove file error after compress")
-		}
+// compress the file to gz file, the source file will be removed only on compress success.
+func (d *Writer) compressFile(fPath string) error {
+	err := compressFile(fPath, fPath+compressSuffix, d.cfg.CompressLevel)
+	if err != nil {
+		return errorx.Wrap(err, "compress old file error")
+	}
+
+	// remove old log file
+	if err = os.Remove(fPath); err != nil {
+		return errorx.Wrap(err, "remove file error after compress")
 	}
 	return nil
 }
+
+// compress the file in a background goroutine. skip on the file is compressing.
+func (d *Writer) asyncCompress(fPath string) {
+	name := path.Base(fPath)
+	if _, loaded := d.compressing.LoadOrStore(name, true); loaded {
+		return
+	}
+
+	d.compressWg.Add(1)
+	go func() {
+		defer d.compressWg.Done()
+		defer d.compressing.Delete(name)
+
+		d.cfg.Debug("async compress file:", fPath)
+		printErrln("rotatefile: async compress file error:", d.compressFile(fPath))
+	}()
+}
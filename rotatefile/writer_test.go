@@ -1,7 +1,15 @@
 package rotatefile_test
 
 import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,6 +44,30 @@ func TestNewWriter(t *testing.T) {
 	assert.Eq(t, w.Config().Filepath, testFile)
 }
 
+func TestWriter_Reopen(t *testing.T) {
+	logfile := "testdata/writer_reopen.log"
+	movedFile := logfile + ".moved"
+	assert.NoErr(t, fsutil.DeleteIfExist(logfile))
+	assert.NoErr(t, fsutil.DeleteIfExist(movedFile))
+
+	w, err := rotatefile.NewConfig(logfile).Create()
+	assert.NoErr(t, err)
+	_, err = w.WriteString("before reopen\n")
+	assert.NoErr(t, err)
+
+	// rename by external tool. eg: logrotate
+	assert.NoErr(t, os.Rename(logfile, movedFile))
+	assert.NoErr(t, w.Reopen())
+	assert.True(t, fsutil.IsFile(logfile))
+
+	_, err = w.WriteString("after reopen\n")
+	assert.NoErr(t, err)
+	assert.NoErr(t, w.Close())
+
+	assert.Eq(t, "before reopen\n", string(fsutil.ReadFile(movedFile)))
+	assert.Eq(t, "after reopen\n", string(fsutil.ReadFile(logfile)))
+}
+
 func TestWriter_Rotate_modeCreate(t *testing.T) {
 	logfile := "testdata/mode_create.log"
 
@@ -91,6 +123,86 @@ func TestWriter_rotateByTime(t *testing.T) {
 
 }
 
+func TestWriter_rotateStaleFile(t *testing.T) {
+	logfile := "testdata/stale_file.log"
+	files, _ := filepath.Glob(logfile + "*")
+	for _, file := range files {
+		assert.NoErr(t, os.Remove(file))
+	}
+
+	// a pre-existing file from yesterday
+	assert.NoErr(t, os.WriteFile(logfile, []byte("[INFO] yesterday message\n"), 0664))
+	yesterday := time.Now().AddDate(0, 0, -1)
+	assert.NoErr(t, os.Chtimes(logfile, yesterday, yesterday))
+
+	c := rotatefile.NewConfig(logfile).With(func(c *rotatefile.Config) {
+		c.RotateTime = rotatefile.EveryDay
+		c.BackupNum = 0
+		c.BackupTime = 0
+	})
+
+	w, err := c.Create()
+	assert.NoErr(t, err)
+	_, err = w.WriteString("[INFO] today message\n")
+	assert.NoErr(t, err)
+	assert.NoErr(t, w.Close())
+
+	bakFile := logfile + "." + yesterday.Format("20060102")
+	assert.True(t, fsutil.IsFile(bakFile))
+	assert.Eq(t, "[INFO] yesterday message\n", fsutil.ReadString(bakFile))
+	assert.Eq(t, "[INFO] today message\n", fsutil.ReadString(logfile))
+
+	// the file of current period is not rotated
+	w, err = c.Create()
+	assert.NoErr(t, err)
+	_, err = w.WriteString("[INFO] today message2\n")
+	assert.NoErr(t, err)
+	assert.NoErr(t, w.Close())
+	assert.Len(t, fsutil.Glob(logfile+".*"), 1)
+	assert.StrContains(t, fsutil.ReadString(logfile), "today message2")
+}
+
+func TestWriter_CompressBacklog(t *testing.T) {
+	logfile := "testdata/compress_backlog.log"
+	files, _ := filepath.Glob(logfile + "*")
+	for _, file := range files {
+		assert.NoErr(t, os.Remove(file))
+	}
+
+	c := rotatefile.NewConfig(logfile).With(func(c *rotatefile.Config) {
+		c.CompressAge = 24
+		c.BackupNum = 0
+		c.BackupTime = 0
+	})
+
+	w, err := c.Create()
+	assert.NoErr(t, err)
+	_, err = w.WriteString("[INFO] active message\n")
+	assert.NoErr(t, err)
+
+	// the backups rotated before enable compress
+	oldTime := time.Now().Add(-48 * time.Hour)
+	for _, name := range []string{".20220101", ".20220102"} {
+		assert.NoErr(t, os.WriteFile(logfile+name, []byte("[INFO] old message\n"), 0664))
+		assert.NoErr(t, os.Chtimes(logfile+name, oldTime, oldTime))
+	}
+	assert.NoErr(t, os.WriteFile(logfile+".20220103", []byte("[INFO] new message\n"), 0664))
+	// the active file is old
+	assert.NoErr(t, os.Chtimes(logfile, oldTime, oldTime))
+
+	assert.NoErr(t, w.CompressBacklog())
+	assert.NoErr(t, w.Close())
+
+	assert.True(t, fsutil.IsFile(logfile))
+	assert.True(t, fsutil.IsFile(logfile+".20220101.gz"))
+	assert.True(t, fsutil.IsFile(logfile+".20220102.gz"))
+	assert.False(t, fsutil.IsFile(logfile+".20220101"))
+	assert.False(t, fsutil.IsFile(logfile+".20220102"))
+	// newer than CompressAge
+	assert.True(t, fsutil.IsFile(logfile+".20220103"))
+	assert.False(t, fsutil.IsFile(logfile+".20220103.gz"))
+}
+
 func TestWriter_Clean(t *testing.T) {
 	logfile := "testdata/writer_clean.log"
 
@@ -130,3 +242,640 @@ func TestWriter_Clean(t *testing.T) {
 		assert.NoErr(t, err)
 	})
 }
+
+func TestWriter_Clean_compressLevel(t *testing.T) {
+	logfile := "testdata/compress_level.log"
+	c := rotatefile.NewConfig(logfile).With(func(c *rotatefile.Config) {
+		c.BackupNum = 5
+		c.Compress = true
+		c.CompressLevel = gzip.BestSpeed
+	})
+
+	wr, err := c.Create()
+	assert.NoErr(t, err)
+	defer func() {
+		_ = wr.Close()
+	}()
+
+	bakFile := logfile + ".001"
+	assert.NoErr(t, os.WriteFile(bakFile, []byte("backup contents\n"), 0664))
+	assert.NoErr(t, wr.Clean())
+	assert.False(t, fsutil.IsFile(bakFile))
+
+	gf, err := os.Open(bakFile + ".gz")
+	assert.NoErr(t, err)
+	defer gf.Close()
+
+	zr, err := gzip.NewReader(gf)
+	assert.NoErr(t, err)
+	bs, err := io.ReadAll(zr)
+	assert.NoErr(t, err)
+	assert.Eq(t, "backup contents\n", string(bs))
+
+	// invalid level
+	c.CompressLevel = 100
+	bakFile = logfile + ".002"
+	assert.NoErr(t, os.WriteFile(bakFile, []byte("backup contents\n"), 0664))
+	assert.Err(t, wr.Clean())
+	assert.False(t, fsutil.IsFile(bakFile+".gz"))
+}
+
+func TestWriter_Clean_keepUncompressed(t *testing.T) {
+	logfile := "testdata/keep_uncompressed.log"
+	c := rotatefile.NewConfig(logfile).With(func(c *rotatefile.Config) {
+		c.BackupNum = 10
+		c.Compress = true
+		c.CompressKeepUncompressed = 2
+	})
+
+	wr, err := c.Create()
+	assert.NoErr(t, err)
+	defer func() {
+		_ = wr.Close()
+	}()
+
+	// 5 backups, the .005 is the newest
+	now := time.Now()
+	writeBackup := func(i int) {
+		bakFile := logfile + ".00" + strconv.Itoa(i)
+		assert.NoErr(t, os.WriteFile(bakFile, []byte("backup contents\n"), 0664))
+		mt := now.Add(time.Duration(i-10) * time.Minute)
+		assert.NoErr(t, os.Chtimes(bakFile, mt, mt))
+	}
+	for i := 1; i <= 5; i++ {
+		writeBackup(i)
+	}
+
+	assert.NoErr(t, wr.Clean())
+	for i := 1; i <= 3; i++ {
+		bakFile := logfile + ".00" + strconv.Itoa(i)
+		assert.False(t, fsutil.IsFile(bakFile))
+		assert.True(t, fsutil.IsFile(bakFile+".gz"))
+	}
+	assert.True(t, fsutil.IsFile(logfile+".004"))
+	assert.True(t, fsutil.IsFile(logfile+".005"))
+	assert.False(t, fsutil.IsFile(logfile+".005.gz"))
+
+	// new backup, the .004 becomes old
+	writeBackup(6)
+	assert.NoErr(t, wr.Clean())
+	assert.True(t, fsutil.IsFile(logfile+".004.gz"))
+	assert.True(t, fsutil.IsFile(logfile+".005"))
+	assert.True(t, fsutil.IsFile(logfile+".006"))
+}
+
+func TestWriter_Clean_maxTotalSize(t *testing.T) {
+	logfile := "testdata/max_total_size.log"
+	c := rotatefile.NewConfig(logfile).With(func(c *rotatefile.Config) {
+		c.BackupNum = 0
+		c.BackupTime = 0
+		c.MaxTotalSize = 1 // 1 MB
+	})
+
+	wr, err := c.Create()
+	assert.NoErr(t, err)
+	defer func() {
+		_ = wr.Close()
+	}()
+	_, err = wr.WriteString("active file contents\n")
+	assert.NoErr(t, err)
+
+	// 4 backups, 400KB each
+	now := time.Now()
+	contents := bytes.Repeat([]byte("a"), 400*1024)
+	for i := 1; i <= 4; i++ {
+		bakFile := logfile + ".00" + strconv.Itoa(i)
+		assert.NoErr(t, os.WriteFile(bakFile, contents, 0664))
+		mt := now.Add(time.Duration(i-5) * time.Minute)
+		assert.NoErr(t, os.Chtimes(bakFile, mt, mt))
+	}
+
+	assert.NoErr(t, wr.Clean())
+	assert.False(t, fsutil.IsFile(logfile+".001"))
+	assert.False(t, fsutil.IsFile(logfile+".002"))
+	assert.True(t, fsutil.IsFile(logfile+".003"))
+	assert.True(t, fsutil.IsFile(logfile+".004"))
+	assert.True(t, fsutil.IsFile(logfile))
+
+	// the tightest constraint wins
+	c.BackupNum = 1
+	assert.NoErr(t, wr.Clean())
+	assert.False(t, fsutil.IsFile(logfile+".003"))
+	assert.True(t, fsutil.IsFile(logfile+".004"))
+}
+
+func TestWriter_rotateBySize_collision(t *testing.T) {
+	logfile := "testdata/rename_collision.log"
+	files, _ := filepath.Glob(logfile + "*")
+	for _, file := range files {
+		assert.NoErr(t, os.Remove(file))
+	}
+
+	newWriter := func(fns ...rotatefile.ConfigFn) *rotatefile.Writer {
+		c := rotatefile.NewConfig(logfile).With(func(c *rotatefile.Config) {
+			c.MaxSize = 10
+			c.BackupNum = 0
+			c.BackupTime = 0
+		}).With(fns...)
+
+		wr, err := c.Create()
+		assert.NoErr(t, err)
+		return wr
+	}
+
+	// rotate 3 times, then restart and rotate 3 times again. the rotateNum will restart from 1
+	written := make(map[string]bool)
+	for n := 0; n < 2; n++ {
+		wr := newWriter()
+		for i := 0; i < 3; i++ {
+			line := fmt.Sprintf("run%d-line%d\n", n, i)
+			written[line] = true
+			_, err := wr.WriteString(line)
+			assert.NoErr(t, err)
+		}
+		assert.NoErr(t, wr.Close())
+	}
+
+	// the RenameFunc always returns same name
+	wr := newWriter(func(c *rotatefile.Config) {
+		c.RenameFunc = func(filepath string, _ uint) string {
+			return filepath + ".bak"
+		}
+	})
+	for i := 0; i < 3; i++ {
+		line := fmt.Sprintf("same-name-line%d\n", i)
+		written[line] = true
+		_, err := wr.WriteString(line)
+		assert.NoErr(t, err)
+	}
+	assert.NoErr(t, wr.Close())
+
+	assert.True(t, fsutil.IsFile(logfile+".bak"))
+	assert.True(t, fsutil.IsFile(logfile+".bak_1"))
+	assert.True(t, fsutil.IsFile(logfile+".bak_2"))
+
+	// all lines are kept in the backups
+	files, _ = filepath.Glob(logfile + ".*")
+	assert.Len(t, files, 9)
+	for _, file := range files {
+		bs, err := os.ReadFile(file)
+		assert.NoErr(t, err)
+		delete(written, string(bs))
+	}
+	assert.Empty(t, written)
+}
+
+func TestWriter_SymlinkPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skip symlink test on windows")
+	}
+
+	logfile := "testdata/symlink_test.log"
+	link := "testdata/symlink_current"
+	_ = os.Remove(link)
+
+	c := rotatefile.NewConfig(logfile).With(func(c *rotatefile.Config) {
+		c.RotateMode = rotatefile.ModeCreate
+		c.RotateTime = rotatefile.EverySecond
+		c.MaxSize = 0
+		c.SymlinkPath = link
+	})
+
+	wr, err := c.Create()
+	assert.NoErr(t, err)
+	defer wr.Close()
+
+	_, err = wr.WriteString("first line\n")
+	assert.NoErr(t, err)
+
+	target, err := os.Readlink(link)
+	assert.NoErr(t, err)
+	first := target
+	assert.StrContains(t, target, "symlink_test.log.")
+
+	bs, err := os.ReadFile(link)
+	assert.NoErr(t, err)
+	assert.Eq(t, "first line\n", string(bs))
+
+	// rotate to new file
+	time.Sleep(time.Second + 10*time.Millisecond)
+	_, err = wr.WriteString("second line\n")
+	assert.NoErr(t, err)
+	assert.NoErr(t, wr.Rotate())
+
+	target, err = os.Readlink(link)
+	assert.NoErr(t, err)
+	assert.Neq(t, first, target)
+	assert.False(t, fsutil.IsFile(link+".tmp"))
+}
+
+func TestWriter_AsyncCompress(t *testing.T) {
+	logfile := "testdata/async_compress.log"
+	files, _ := filepath.Glob(logfile + "*")
+	for _, file := range files {
+		assert.NoErr(t, os.Remove(file))
+	}
+
+	c := rotatefile.NewConfig(logfile).With(func(c *rotatefile.Config) {
+		c.MaxSize = 64
+		c.BackupNum = 0
+		c.BackupTime = 0
+		c.Compress = true
+		c.AsyncCompress = true
+	})
+
+	wr, err := c.Create()
+	assert.NoErr(t, err)
+	for i := 0; i < 6; i++ {
+		_, err = wr.WriteString(fmt.Sprintf("[INFO] this is a log message for async compress, idx=%d\n", i))
+		assert.NoErr(t, err)
+	}
+	// will wait the in-flight compression
+	assert.NoErr(t, wr.Close())
+
+	gzFiles := fsutil.Glob(logfile + ".*.gz")
+	assert.NotEmpty(t, gzFiles)
+	// the sources are removed, no temp files left
+	assert.Len(t, fsutil.Glob(logfile+".*"), len(gzFiles))
+
+	gf, err := os.Open(gzFiles[0])
+	assert.NoErr(t, err)
+	defer gf.Close()
+
+	zr, err := gzip.NewReader(gf)
+	assert.NoErr(t, err)
+	bs, err := io.ReadAll(zr)
+	assert.NoErr(t, err)
+	assert.StrContains(t, string(bs), "async compress, idx=")
+
+	// compress failed: keep the source file
+	c.CompressLevel = 100
+	wr, err = c.Create()
+	assert.NoErr(t, err)
+	// the exists file is not empty, will rotate before write
+	_, err = wr.WriteString("[INFO] this is a log message for async compress, idx=0\n")
+	assert.NoErr(t, err)
+	assert.NoErr(t, wr.Close())
+	assert.Len(t, fsutil.Glob(logfile+".*"), len(gzFiles)+1)
+	assert.Empty(t, fsutil.Glob(logfile+".*.tmp"))
+}
+
+func TestWriter_MaxSize(t *testing.T) {
+	logfile := "testdata/max_size.log"
+	files, _ := filepath.Glob(logfile + "*")
+	for _, file := range files {
+		assert.NoErr(t, os.Remove(file))
+	}
+
+	c := rotatefile.NewConfig(logfile).With(func(c *rotatefile.Config) {
+		c.MaxSize = 100
+		c.BackupNum = 0
+		c.BackupTime = 0
+	})
+
+	wr, err := c.Create()
+	assert.NoErr(t, err)
+	for i := 0; i < 30; i++ {
+		_, err = wr.WriteString(fmt.Sprintf("[INFO] log message, idx=%d\n", i*i))
+		assert.NoErr(t, err)
+	}
+
+	// a single write larger than MaxSize, write to a fresh file
+	bigMsg := strings.Repeat("a", 150) + "\n"
+	_, err = wr.WriteString(bigMsg)
+	assert.NoErr(t, err)
+	_, err = wr.WriteString("[INFO] log message after big\n")
+	assert.NoErr(t, err)
+	assert.NoErr(t, wr.Close())
+
+	files = fsutil.Glob(logfile + "*")
+	assert.Gt(t, len(files), 5)
+
+	var bigNum int
+	for _, file := range files {
+		bs, err := os.ReadFile(file)
+		assert.NoErr(t, err)
+		if string(bs) == bigMsg {
+			bigNum++
+			continue
+		}
+		assert.Lte(t, len(bs), 100, file)
+	}
+	assert.Eq(t, 1, bigNum)
+
+	// the exists file size is counted on reopen
+	wr, err = c.Create()
+	assert.NoErr(t, err)
+	_, err = wr.WriteString(strings.Repeat("b", 79) + "\n")
+	assert.NoErr(t, err)
+	assert.NoErr(t, wr.Close())
+	assert.Len(t, fsutil.Glob(logfile+"*"), len(files)+1)
+}
+
+func TestWriter_Stats(t *testing.T) {
+	logfile := "testdata/writer_stats.log"
+	files, _ := filepath.Glob(logfile + "*")
+	for _, file := range files {
+		assert.NoErr(t, os.Remove(file))
+	}
+
+	// an exists backup
+	_, err := fsutil.PutContents(logfile+".old", "old contents\n")
+	assert.NoErr(t, err)
+
+	c := rotatefile.NewConfig(logfile).With(func(c *rotatefile.Config) {
+		c.MaxSize = 100
+		c.BackupNum = 0
+		c.BackupTime = 0
+	})
+
+	wr, err := c.Create()
+	assert.NoErr(t, err)
+	st := wr.Stats()
+	assert.Eq(t, int64(1), st.Backups)
+	assert.Eq(t, uint64(0), st.Size)
+	assert.True(t, st.LastRotated.IsZero())
+
+	line := strings.Repeat("a", 39) + "\n"
+	for i := 0; i < 5; i++ {
+		_, err = wr.WriteString(line)
+		assert.NoErr(t, err)
+	}
+
+	// rotated on the 3rd and 5th writes
+	st = wr.Stats()
+	assert.Eq(t, uint64(200), st.Written)
+	assert.Eq(t, uint64(40), st.Size)
+	assert.Eq(t, uint64(2), st.Rotations)
+	assert.Eq(t, int64(3), st.Backups)
+	assert.False(t, st.LastRotated.IsZero())
+	assert.Len(t, fsutil.Glob(logfile+".*"), 3)
+
+	// updated on clean
+	c.BackupNum = 1
+	assert.NoErr(t, wr.Clean())
+	assert.Eq(t, int64(1), wr.Stats().Backups)
+	assert.NoErr(t, wr.Close())
+}
+
+func TestWriter_Truncate(t *testing.T) {
+	logfile := "testdata/writer_truncate.log"
+	files, _ := filepath.Glob(logfile + "*")
+	for _, file := range files {
+		assert.NoErr(t, os.Remove(file))
+	}
+
+	w, err := rotatefile.NewConfig(logfile).With(func(c *rotatefile.Config) {
+		c.MaxSize = 100
+		c.RotateTime = 0
+		c.BufferSize = 64
+	}).Create()
+	assert.NoErr(t, err)
+
+	_, err = w.WriteString("old contents\n")
+	assert.NoErr(t, err)
+	assert.Eq(t, uint64(13), w.Stats().Size)
+
+	assert.NoErr(t, w.Truncate())
+	assert.Eq(t, "", fsutil.ReadString(logfile))
+	assert.Eq(t, uint64(0), w.Stats().Size)
+	// no backup file
+	assert.Empty(t, fsutil.Glob(logfile+".*"))
+
+	// the size counter is reset, will not rotate
+	line := strings.Repeat("a", 79) + "\n"
+	_, err = w.WriteString(line)
+	assert.NoErr(t, err)
+	assert.NoErr(t, w.Close())
+	assert.Eq(t, line, fsutil.ReadString(logfile))
+	assert.Empty(t, fsutil.Glob(logfile+".*"))
+}
+
+func TestWriter_FlushInterval(t *testing.T) {
+	logfile := "testdata/writer_flush_interval.log"
+	files, _ := filepath.Glob(logfile + "*")
+	for _, file := range files {
+		assert.NoErr(t, os.Remove(file))
+	}
+
+	w, err := rotatefile.NewConfig(logfile).With(func(c *rotatefile.Config) {
+		c.RotateTime = 0
+		c.BufferSize = 1024
+		c.FlushInterval = 10 * time.Millisecond
+		c.BackupNum = 0
+		c.BackupTime = 0
+	}).Create()
+	assert.NoErr(t, err)
+
+	_, err = w.WriteString("buffered contents\n")
+	assert.NoErr(t, err)
+	assert.Eq(t, "", fsutil.ReadString(logfile))
+
+	// flushed in background
+	time.Sleep(50 * time.Millisecond)
+	assert.Eq(t, "buffered contents\n", fsutil.ReadString(logfile))
+
+	_, err = w.WriteString("more contents\n")
+	assert.NoErr(t, err)
+	assert.NoErr(t, w.Close())
+	assert.Eq(t, "buffered contents\nmore contents\n", fsutil.ReadString(logfile))
+}
+
+func TestWriter_BufferSize(t *testing.T) {
+	logfile := "testdata/writer_buffered.log"
+	files, _ := filepath.Glob(logfile + "*")
+	for _, file := range files {
+		assert.NoErr(t, os.Remove(file))
+	}
+
+	w, err := rotatefile.NewConfig(logfile).With(func(c *rotatefile.Config) {
+		c.MaxSize = 100
+		c.RotateTime = 0
+		c.BufferSize = 64
+		c.BackupNum = 0
+		c.BackupTime = 0
+	}).Create()
+	assert.NoErr(t, err)
+
+	line := strings.Repeat("a", 39) + "\n"
+	_, err = w.WriteString(line)
+	assert.NoErr(t, err)
+	// in the buffer, but counted for rotate by size
+	assert.Eq(t, "", fsutil.ReadString(logfile))
+	assert.Eq(t, uint64(40), w.Stats().Size)
+
+	// rotated on the 3rd write, the buffer is flushed to the backup
+	for i := 0; i < 2; i++ {
+		_, err = w.WriteString(line)
+		assert.NoErr(t, err)
+	}
+	backups := fsutil.Glob(logfile + ".*")
+	assert.Len(t, backups, 1)
+	assert.Eq(t, strings.Repeat(line, 2), fsutil.ReadString(backups[0]))
+
+	assert.NoErr(t, w.Flush())
+	assert.Eq(t, line, fsutil.ReadString(logfile))
+
+	_, err = w.WriteString(line)
+	assert.NoErr(t, err)
+	assert.NoErr(t, w.Close())
+	assert.Eq(t, strings.Repeat(line, 2), fsutil.ReadString(logfile))
+}
+
+func TestWriter_rotateBySizeAndTime(t *testing.T) {
+	logfile := "testdata/size_and_time.log"
+	files, _ := filepath.Glob(logfile + "*")
+	for _, file := range files {
+		assert.NoErr(t, os.Remove(file))
+	}
+
+	now := time.Date(2024, 1, 2, 14, 30, 0, 0, time.Local)
+	c := rotatefile.NewConfig(logfile).With(func(c *rotatefile.Config) {
+		c.MaxSize = 100
+		c.RotateTime = rotatefile.EveryHour
+		c.BackupNum = 0
+		c.BackupTime = 0
+		c.TimeClock = rotatefile.ClockFn(func() time.Time { return now })
+	})
+
+	w, err := c.Create()
+	assert.NoErr(t, err)
+	msg := strings.Repeat("a", 39) + "\n"
+
+	// rotate by size in the period 14:00
+	for i := 0; i < 5; i++ {
+		_, err = w.WriteString(msg)
+		assert.NoErr(t, err)
+	}
+	sizeFiles := len(fsutil.Glob(logfile + ".*"))
+	assert.Eq(t, 2, sizeFiles)
+
+	// cross the time boundary, the first write is late. the backup is named by the finished period,
+	// and only contains the logs of 14:00
+	now = time.Date(2024, 1, 2, 15, 10, 0, 0, time.Local)
+	_, err = w.WriteString("[INFO] message in 15:10\n")
+	assert.NoErr(t, err)
+	bakFile := logfile + ".20240102_1400"
+	assert.True(t, fsutil.IsFile(bakFile))
+	assert.Eq(t, msg, fsutil.ReadString(bakFile))
+	assert.Eq(t, "[INFO] message in 15:10\n", fsutil.ReadString(logfile))
+
+	// rotate by size in the period 15:00, the next time boundary still fires
+	for i := 0; i < 3; i++ {
+		_, err = w.WriteString(msg)
+		assert.NoErr(t, err)
+	}
+	assert.Eq(t, sizeFiles+2, len(fsutil.Glob(logfile+".*")))
+
+	// the write exceeds MaxSize, rotate by size first, then by time at the end of period
+	now = time.Date(2024, 1, 2, 15, 59, 59, 600, time.Local)
+	_, err = w.WriteString("[INFO] message in 15:59\n")
+	assert.NoErr(t, err)
+	bakFile = logfile + ".20240102_1500"
+	assert.True(t, fsutil.IsFile(bakFile))
+	assert.Eq(t, "[INFO] message in 15:59\n", fsutil.ReadString(bakFile))
+	assert.Eq(t, sizeFiles+4, len(fsutil.Glob(logfile+".*")))
+
+	// not rotate again in the period 16:00
+	now = time.Date(2024, 1, 2, 16, 30, 0, 0, time.Local)
+	_, err = w.WriteString("[INFO] message in 16:30\n")
+	assert.NoErr(t, err)
+	assert.NoErr(t, w.Close())
+	assert.Eq(t, "[INFO] message in 16:30\n", fsutil.ReadString(logfile))
+	assert.Eq(t, sizeFiles+4, len(fsutil.Glob(logfile+".*")))
+}
+
+func TestWriter_rotateByTime_minuteName(t *testing.T) {
+	logfile := "testdata/minute_name.log"
+	files, _ := filepath.Glob(logfile + "*")
+	for _, file := range files {
+		assert.NoErr(t, os.Remove(file))
+	}
+
+	now := time.Date(2024, 1, 2, 14, 36, 0, 0, time.Local)
+	c := rotatefile.NewConfig(logfile).With(func(c *rotatefile.Config) {
+		c.RotateTime = rotatefile.EveryMinute * 5
+		c.BackupNum = 0
+		c.BackupTime = 0
+		c.TimeClock = rotatefile.ClockFn(func() time.Time { return now })
+	})
+
+	w, err := c.Create()
+	assert.NoErr(t, err)
+	_, err = w.WriteString("[INFO] message in 14:36\n")
+	assert.NoErr(t, err)
+
+	// the backup is named by the period start, same as the hour and day level
+	now = time.Date(2024, 1, 2, 14, 41, 0, 0, time.Local)
+	_, err = w.WriteString("[INFO] message in 14:41\n")
+	assert.NoErr(t, err)
+	assert.NoErr(t, w.Close())
+
+	assert.Eq(t, "[INFO] message in 14:36\n", fsutil.ReadString(logfile+".20240102_1435"))
+	assert.Eq(t, "[INFO] message in 14:41\n", fsutil.ReadString(logfile))
+}
+
+func TestWriter_rotateBySizeAndTime_modeCreate(t *testing.T) {
+	logfile := "testdata/size_and_time_create.log"
+	files, _ := filepath.Glob(logfile + "*")
+	for _, file := range files {
+		assert.NoErr(t, os.Remove(file))
+	}
+
+	now := time.Date(2024, 1, 2, 14, 30, 0, 0, time.Local)
+	c := rotatefile.NewConfig(logfile).With(func(c *rotatefile.Config) {
+		c.MaxSize = 100
+		c.RotateTime = rotatefile.EveryHour
+		c.RotateMode = rotatefile.ModeCreate
+		c.BackupNum = 0
+		c.BackupTime = 0
+		c.TimeClock = rotatefile.ClockFn(func() time.Time { return now })
+	})
+
+	w, err := c.Create()
+	assert.NoErr(t, err)
+	msg := strings.Repeat("a", 39) + "\n"
+	for i := 0; i < 3; i++ {
+		_, err = w.WriteString(msg)
+		assert.NoErr(t, err)
+	}
+
+	// rotate at the end of period, the new file is for next period
+	now = time.Date(2024, 1, 2, 14, 59, 59, 600, time.Local)
+	for i := 0; i < 3; i++ {
+		_, err = w.WriteString(msg)
+		assert.NoErr(t, err)
+	}
+	assert.NoErr(t, w.Close())
+
+	assert.Eq(t, strings.Repeat(msg, 2), fsutil.ReadString(logfile+".20240102_1400_001"))
+	// the write at 14:59:59 is in the period 14:00
+	assert.Eq(t, strings.Repeat(msg, 2), fsutil.ReadString(logfile+".20240102_1400"))
+	assert.Eq(t, strings.Repeat(msg, 2), fsutil.ReadString(logfile+".20240102_1500"))
+	assert.Len(t, fsutil.Glob(logfile+".*"), 3)
+}
+
+func BenchmarkWriter_BufferSize(b *testing.B) {
+	line := []byte(strings.Repeat("a", 99) + "\n")
+
+	for _, size := range []uint{0, 32 * 1024} {
+		b.Run("BufferSize="+strconv.Itoa(int(size)), func(b *testing.B) {
+			logfile := filepath.Join(b.TempDir(), "bench.log")
+			w, err := rotatefile.NewConfig(logfile).With(func(c *rotatefile.Config) {
+				c.BufferSize = size
+				c.RotateTime = 0
+			}).Create()
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.SetBytes(int64(len(line)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = w.Write(line)
+			}
+			b.StopTimer()
+			_ = w.Close()
+		})
+	}
+}
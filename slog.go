@@ -26,6 +26,7 @@ package slog
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/gookit/goutil"
@@ -49,32 +50,63 @@ type LoggerFn func(l *Logger)
 //
 
 // std logger is an SugaredLogger.
-// It is directly available without any additional configuration
-var std = NewStdLogger()
+// It is directly available without any additional configuration.
+// can be replaced by SetDefault(), so it is stored as atomic pointer.
+var std atomic.Pointer[SugaredLogger]
+
+func init() { std.Store(NewStdLogger()) }
 
 // Std get std logger
-func Std() *SugaredLogger { return std }
+func Std() *SugaredLogger { return std.Load() }
+
+// Default get the logger of the std logger, the package-level funcs(eg: Info, Error) log by it.
+func Default() *Logger { return std.Load().Logger }
+
+// SetDefault replace the std logger by the logger, returns a func to restore the previous std logger.
+// the package-level funcs(eg: Info, Error) will log by the logger. it is safe for concurrent use.
+//
+// NOTICE: after SetDefault, SetLogLevel() and SetFormatter() apply to the logger and its handlers,
+// but the Output, Formatter, Level fields of the SugaredLogger in Configure() are not used.
+//
+// Usage:
+//
+//	restore := slog.SetDefault(testLogger)
+//	defer restore()
+func SetDefault(l *Logger) (restore func()) {
+	sl := &SugaredLogger{
+		Logger:    l,
+		Formatter: l.DefaultFormatter(),
+		Level:     l.GetLevel(),
+		wrapped:   true,
+	}
+
+	prev := std.Swap(sl)
+	return func() { std.Store(prev) }
+}
 
 // Reset the std logger and reset exit handlers
 func Reset() {
 	ResetExitHandlers(true)
 	// new std
-	std = NewStdLogger()
+	std.Store(NewStdLogger())
 }
 
-// Configure the std logger
-func Configure(fn func(l *SugaredLogger)) { std.Config(fn) }
+// Configure the std logger.
+//
+// NOTICE: after SetDefault(), the Output, Formatter, Level fields of the SugaredLogger are not used,
+// please config the logger by the methods. eg: sl.SetLevel(), sl.AddHandler()
+func Configure(fn func(l *SugaredLogger)) { Std().Config(fn) }
 
 // SetExitFunc to the std logger
-func SetExitFunc(fn func(code int)) { std.ExitFunc = fn }
+func SetExitFunc(fn func(code int)) { Std().ExitFunc = fn }
 
 // Exit runs all exit handlers and then terminates the program using os.Exit(code)
-func Exit(code int) { std.Exit(code) }
+func Exit(code int) { Std().Exit(code) }
 
 // Close logger, flush and close all handlers.
 //
 // IMPORTANT: please call Close() before app exit.
-func Close() error { return std.Close() }
+func Close() error { return Std().Close() }
 
 // MustClose logger, flush and close all handlers.
 //
@@ -82,169 +114,220 @@ func Close() error { return std.Close() }
 func MustClose() { goutil.PanicErr(Close()) }
 
 // Flush log messages
-func Flush() error { return std.Flush() }
+func Flush() error { return Std().Flush() }
 
 // MustFlush log messages
 func MustFlush() { goutil.PanicErr(Flush()) }
 
 // FlushTimeout flush logs with timeout.
-func FlushTimeout(timeout time.Duration) { std.FlushTimeout(timeout) }
+func FlushTimeout(timeout time.Duration) { Std().FlushTimeout(timeout) }
 
 // FlushDaemon run flush handle on daemon.
 //
 // Usage please see slog_test.ExampleFlushDaemon()
 func FlushDaemon(onStops ...func()) {
-	std.FlushDaemon(onStops...)
+	Std().FlushDaemon(onStops...)
 }
 
 // StopDaemon stop flush daemon
-func StopDaemon() { std.StopDaemon() }
-
-// SetLogLevel max level for the std logger
-func SetLogLevel(l Level) { std.Level = l }
-
-// SetFormatter to std logger
-func SetFormatter(f Formatter) { std.Formatter = f }
+func StopDaemon() { Std().StopDaemon() }
+
+// SetLogLevel max level for the std logger. after SetDefault(), will set the level of the logger.
+func SetLogLevel(l Level) { Std().SetLevel(l) }
+
+// SetFormatter to std logger. after SetDefault(), will set the default formatter of the logger handlers.
+// see Logger.SetFormatter()
+func SetFormatter(f Formatter) {
+	sl := Std()
+	sl.Formatter = f
+	if sl.wrapped {
+		sl.Logger.SetFormatter(f)
+	}
+}
 
 // GetFormatter of the std logger
-func GetFormatter() Formatter { return std.Formatter }
+func GetFormatter() Formatter { return Std().Formatter }
 
 // AddHandler to the std logger
-func AddHandler(h Handler) { std.AddHandler(h) }
+func AddHandler(h Handler) { Std().AddHandler(h) }
+
+// AddHandlerWithPriority to the std logger. lower priority is run earlier. see Logger.AddHandlerWithPriority()
+func AddHandlerWithPriority(h Handler, priority int) { Std().AddHandlerWithPriority(h, priority) }
 
 // PushHandler to the std logger
-func PushHandler(h Handler) { std.AddHandler(h) }
+func PushHandler(h Handler) { Std().AddHandler(h) }
 
 // AddHandlers to the std logger
-func AddHandlers(hs ...Handler) { std.AddHandlers(hs...) }
+func AddHandlers(hs ...Handler) { Std().AddHandlers(hs...) }
 
 // PushHandlers to the std logger
-func PushHandlers(hs ...Handler) { std.PushHandlers(hs...) }
+func PushHandlers(hs ...Handler) { Std().PushHandlers(hs...) }
 
 // AddProcessor to the logger
-func AddProcessor(p Processor) { std.AddProcessor(p) }
+func AddProcessor(p Processor) { Std().AddProcessor(p) }
 
 // AddProcessors to the logger
-func AddProcessors(ps ...Processor) { std.AddProcessors(ps...) }
+func AddProcessors(ps ...Processor) { Std().AddProcessors(ps...) }
+
+// PrependProcessor add the processors to the front of the std logger processors
+func PrependProcessor(ps ...Processor) { Std().PrependProcessor(ps...) }
 
 // -------------------------- New record with log data, fields -----------------------------
 
 // WithExtra new record with extra data
 func WithExtra(ext M) *Record {
-	return std.WithExtra(ext)
+	return Std().WithExtra(ext)
+}
+
+// WithChannel new record with the channel name
+func WithChannel(name string) *Record {
+	return Std().WithChannel(name)
+}
+
+// NewEntry new reusable child logger with the fields. see Entry
+func NewEntry(fields M) *Entry {
+	return Std().NewEntry(fields)
 }
 
 // WithData new record with data
 func WithData(data M) *Record {
-	return std.WithData(data)
+	return Std().WithData(data)
 }
 
 // WithValue new record with data value
 func WithValue(key string, value any) *Record {
-	return std.WithValue(key, value)
+	return Std().WithValue(key, value)
+}
+
+// WithSampleKey new record with the sampling key. see Record.WithSampleKey
+func WithSampleKey(key string) *Record {
+	return Std().WithSampleKey(key)
 }
 
 // WithField new record with field.
 //
 // TIP: add field need config Formatter template fields.
 func WithField(name string, value any) *Record {
-	return std.WithField(name, value)
+	return Std().WithField(name, value)
 }
 
 // WithFields new record with fields
 //
 // TIP: add field need config Formatter template fields.
+// the returned record is released after one log call, use NewEntry() for a reusable child logger.
 func WithFields(fields M) *Record {
-	return std.WithFields(fields)
+	return Std().WithFields(fields)
+}
+
+// With new record with typed fields
+//
+// TIP: add field need config Formatter template fields.
+func With(fields ...Field) *Record {
+	return Std().With(fields...)
+}
+
+// WithGroup new record, the subsequent fields will be nested under the group name.
+func WithGroup(name string) *Record {
+	return Std().WithGroup(name)
+}
+
+// Build new Builder with the level. see Builder
+func Build(level Level) *Builder {
+	return Std().Build(level)
+}
+
+// Msgt new record with the message template. see Record.Msgt()
+func Msgt(template string, args M) *Record {
+	return Std().Msgt(template, args)
 }
 
 // WithContext new record with context
 func WithContext(ctx context.Context) *Record {
-	return std.WithContext(ctx)
+	return Std().WithContext(ctx)
 }
 
 // -------------------------- Add log messages with level -----------------------------
 
 // Print logs a message at level PrintLevel
-func Print(args ...any) { std.log(PrintLevel, args) }
+func Print(args ...any) { Std().log(PrintLevel, args) }
 
 // Println logs a message at level PrintLevel
-func Println(args ...any) { std.log(PrintLevel, args) }
+func Println(args ...any) { Std().log(PrintLevel, args) }
 
 // Printf logs a message at level PrintLevel
-func Printf(format string, args ...any) { std.logf(PrintLevel, format, args) }
+func Printf(format string, args ...any) { Std().logf(PrintLevel, format, args) }
 
 // Trace logs a message at level Trace
-func Trace(args ...any) { std.log(TraceLevel, args) }
+func Trace(args ...any) { Std().log(TraceLevel, args) }
 
 // Tracef logs a message at level Trace
-func Tracef(format string, args ...any) { std.logf(TraceLevel, format, args) }
+func Tracef(format string, args ...any) { Std().logf(TraceLevel, format, args) }
 
 // Info logs a message at level Info
-func Info(args ...any) { std.log(InfoLevel, args) }
+func Info(args ...any) { Std().log(InfoLevel, args) }
 
 // Infof logs a message at level Info
-func Infof(format string, args ...any) { std.logf(InfoLevel, format, args) }
+func Infof(format string, args ...any) { Std().logf(InfoLevel, format, args) }
 
 // Notice logs a message at level Notice
-func Notice(args ...any) { std.log(NoticeLevel, args) }
+func Notice(args ...any) { Std().log(NoticeLevel, args) }
 
 // Noticef logs a message at level Notice
-func Noticef(format string, args ...any) { std.logf(NoticeLevel, format, args) }
+func Noticef(format string, args ...any) { Std().logf(NoticeLevel, format, args) }
 
 // Warn logs a message at level Warn
-func Warn(args ...any) { std.log(WarnLevel, args) }
+func Warn(args ...any) { Std().log(WarnLevel, args) }
 
 // Warnf logs a message at level Warn
-func Warnf(format string, args ...any) { std.logf(WarnLevel, format, args) }
+func Warnf(format string, args ...any) { Std().logf(WarnLevel, format, args) }
 
 // Error logs a message at level Error
-func Error(args ...any) { std.log(ErrorLevel, args) }
+func Error(args ...any) { Std().log(ErrorLevel, args) }
 
 // Errorf logs a message at level Error
-func Errorf(format string, args ...any) { std.logf(ErrorLevel, format, args) }
+func Errorf(format string, args ...any) { Std().logf(ErrorLevel, format, args) }
 
 // ErrorT logs a error type at level Error
 func ErrorT(err error) {
 	if err != nil {
-		std.log(ErrorLevel, []any{err})
+		Std().log(ErrorLevel, []any{err})
 	}
 }
 
 // EStack logs a error message and with call stack.
 // func EStack(args ...any) {
-// 	std.WithExtra(map[string]any{"stack": goinfo.GetCallerInfo(2)}).
+// 	Std().WithExtra(map[string]any{"stack": goinfo.GetCallerInfo(2)}).
 // 		log(ErrorLevel, args)
 // }
 
 // Debug logs a message at level Debug
-func Debug(args ...any) { std.log(DebugLevel, args) }
+func Debug(args ...any) { Std().log(DebugLevel, args) }
 
 // Debugf logs a message at level Debug
-func Debugf(format string, args ...any) { std.logf(DebugLevel, format, args) }
+func Debugf(format string, args ...any) { Std().logf(DebugLevel, format, args) }
 
 // Fatal logs a message at level Fatal
-func Fatal(args ...any) { std.log(FatalLevel, args) }
+func Fatal(args ...any) { Std().log(FatalLevel, args) }
 
 // Fatalf logs a message at level Fatal
-func Fatalf(format string, args ...any) { std.logf(FatalLevel, format, args) }
+func Fatalf(format string, args ...any) { Std().logf(FatalLevel, format, args) }
 
 // FatalErr logs a message at level Fatal on err is not nil
 func FatalErr(err error) {
 	if err != nil {
-		std.log(FatalLevel, []any{err})
+		Std().log(FatalLevel, []any{err})
 	}
 }
 
 // Panic logs a message at level Panic
-func Panic(args ...any) { std.log(PanicLevel, args) }
+func Panic(args ...any) { Std().log(PanicLevel, args) }
 
 // Panicf logs a message at level Panic
-func Panicf(format string, args ...any) { std.logf(PanicLevel, format, args) }
+func Panicf(format string, args ...any) { Std().logf(PanicLevel, format, args) }
 
 // PanicErr logs a message at level Panic on err is not nil
 func PanicErr(err error) {
 	if err != nil {
-		std.log(PanicLevel, []any{err})
+		Std().log(PanicLevel, []any{err})
 	}
 }
@@ -32,6 +32,7 @@ func TestStd(t *testing.T) {
 
 	slog.SetLogLevel(slog.WarnLevel)
 	slog.SetFormatter(slog.NewJSONFormatter())
+	assert.Eq(t, slog.WarnLevel, slog.Std().GetLevel())
 
 	assert.True(t, slog.Std().IsHandling(slog.WarnLevel))
 	assert.True(t, slog.Std().IsHandling(slog.ErrorLevel))
@@ -49,6 +50,59 @@ func TestStd(t *testing.T) {
 	assert.Eq(t, "Exited,34", buf.String())
 }
 
+func TestSetDefault(t *testing.T) {
+	std := slog.Std()
+	th := newTestHandler()
+	l := slog.NewWithHandlers(th)
+	l.DoNothingOnPanicFatal()
+
+	restore := slog.SetDefault(l)
+	assert.Eq(t, l, slog.Default())
+	assert.NotEq(t, std, slog.Std())
+
+	slog.Info("info message")
+	slog.Std().Warnf("warn %s", "message")
+	s := th.ResetGet()
+	assert.StrContains(t, s, "INFO")
+	assert.StrContains(t, s, "info message")
+	assert.StrContains(t, s, "warn message")
+
+	restore()
+	assert.Eq(t, std, slog.Std())
+	assert.Eq(t, std.Logger, slog.Default())
+
+	slog.Info("not logged")
+	assert.Empty(t, th.ResetGet())
+}
+
+func TestSetDefault_stdSetters(t *testing.T) {
+	th := newTestHandler()
+	l := slog.NewWithHandlers(th)
+	restore := slog.SetDefault(l)
+	defer restore()
+
+	// SetLogLevel apply to the logger
+	slog.SetLogLevel(slog.WarnLevel)
+	assert.Eq(t, slog.WarnLevel, l.GetLevel())
+	slog.Info("info message")
+	slog.Warn("warn message")
+	s := th.ResetGet()
+	assert.NotContains(t, s, "info message")
+	assert.StrContains(t, s, "warn message")
+
+	// SetFormatter apply to the logger handlers
+	slog.SetFormatter(slog.NewJSONFormatter())
+	slog.Warn("json message")
+	assert.StrContains(t, th.ResetGet(), `"message":"json message"`)
+
+	// Configure with the methods
+	slog.Configure(func(sl *slog.SugaredLogger) {
+		sl.SetLevel(slog.ErrorLevel)
+	})
+	slog.Warn("warn message")
+	assert.Empty(t, th.ResetGet())
+}
+
 func TestTextFormatNoColor(t *testing.T) {
 	defer slog.Reset()
 	slog.Configure(func(l *slog.SugaredLogger) {
@@ -316,20 +370,36 @@ func TestName2Level(t *testing.T) {
 	}
 
 	// special names
-	tests := map[slog.Level]string{
-		slog.WarnLevel:  "warn",
-		slog.ErrorLevel: "err",
-		slog.InfoLevel:  "",
+	tests := map[string]slog.Level{
+		"warn":     slog.WarnLevel,
+		"WARN":     slog.WarnLevel,
+		"warning":  slog.WarnLevel,
+		"err":      slog.ErrorLevel,
+		"Error":    slog.ErrorLevel,
+		"fatal":    slog.FatalLevel,
+		"crit":     slog.FatalLevel,
+		"critical": slog.FatalLevel,
+		"note":     slog.NoticeLevel,
+		" debug ":  slog.DebugLevel,
+		"":         slog.InfoLevel,
+		// numeric
+		"4":   slog.WarnLevel,
+		"1":   slog.PanicLevel,
+		"8":   slog.TraceLevel,
+		"600": slog.InfoLevel,
 	}
-	for wantLevel, name := range tests {
+	for name, wantLevel := range tests {
 		level, err := slog.Name2Level(name)
-		assert.NoErr(t, err)
-		assert.Eq(t, wantLevel, level)
+		assert.NoErr(t, err, "name: "+name)
+		assert.Eq(t, wantLevel, level, "name: "+name)
 	}
 
-	level, err := slog.Name2Level("unknown")
-	assert.Err(t, err)
-	assert.Eq(t, slog.Level(0), level)
+	for _, name := range []string{"unknown", "0", "9", "450", "-4", "4.0"} {
+		level, err := slog.Name2Level(name)
+		assert.Err(t, err)
+		assert.ErrMsg(t, err, "invalid log level name: "+name)
+		assert.Eq(t, slog.Level(0), level)
+	}
 }
 
 func TestPrependExitHandler(t *testing.T) {
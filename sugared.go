@@ -3,8 +3,7 @@ package slog
 import (
 	"io"
 	"os"
-
-	"github.com/gookit/color"
+	"sync/atomic"
 )
 
 // SugaredLoggerFn func type.
@@ -19,7 +18,12 @@ type SugaredLogger struct {
 	// Output writer
 	Output io.Writer
 	// Level for log handling. if log record level <= Level, it will be record.
+	//
+	// NOTICE: please use SetLevel() for change it on runtime.
 	Level Level
+	// the Logger is wrapped by SetDefault(), self is not a handler of the Logger.
+	// the Formatter, Output, Level are not used, SetLevel() will apply to the Logger.
+	wrapped bool
 }
 
 // NewStd logger instance, alias of NewStdLogger()
@@ -35,7 +39,7 @@ func NewStdLogger(fns ...SugaredLoggerFn) *SugaredLogger {
 			// sl.CallerSkip += 1
 			sl.ReportCaller = true
 			// auto enable console color
-			sl.Formatter.(*TextFormatter).EnableColor = color.SupportColor()
+			sl.Formatter.(*TextFormatter).SetColorMode(ColorModeAuto, os.Stdout)
 		},
 	}
 
@@ -82,14 +86,36 @@ func (sl *SugaredLogger) Config(fns ...SugaredLoggerFn) *SugaredLogger {
 	return sl
 }
 
+// SetFormatter set the formatter for the logger output, and as the default
+// formatter of the other handlers. see Logger.SetFormatter()
+func (sl *SugaredLogger) SetFormatter(f Formatter) {
+	sl.Formatter = f
+	sl.Logger.SetFormatter(f)
+}
+
 // Reset the logger
 func (sl *SugaredLogger) Reset() {
 	*sl = *NewSugaredLogger(os.Stdout, DebugLevel)
 }
 
+// SetLevel set the max level for log handling. it is safe for concurrent use.
+//
+// if the logger is set by SetDefault(), will set the level of the wrapped Logger.
+func (sl *SugaredLogger) SetLevel(level Level) {
+	atomic.StoreUint32((*uint32)(&sl.Level), uint32(level))
+	if sl.wrapped {
+		sl.Logger.SetLevel(level)
+	}
+}
+
+// GetLevel get the max level for log handling. it is safe for concurrent use.
+func (sl *SugaredLogger) GetLevel() Level {
+	return Level(atomic.LoadUint32((*uint32)(&sl.Level)))
+}
+
 // IsHandling Check if the current level can be handling
 func (sl *SugaredLogger) IsHandling(level Level) bool {
-	return sl.Level.ShouldHandling(level)
+	return sl.GetLevel().ShouldHandling(level)
 }
 
 // Handle log record
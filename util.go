@@ -1,12 +1,18 @@
 package slog
 
 import (
+	"bytes"
+	"encoding"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path"
+	"reflect"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gookit/goutil/byteutil"
 	"github.com/gookit/goutil/strutil"
@@ -31,6 +37,168 @@ func buildLowerLevelName() map[Level]string {
 	return mp
 }
 
+// filterMap returns a new map with the keys that keep() returns true.
+// returns the src if all keys are kept, the src will not be modified.
+func filterMap(src M, keep func(key string) bool) M {
+	var dropped int
+	for key := range src {
+		if !keep(key) {
+			dropped++
+		}
+	}
+	if dropped == 0 {
+		return src
+	}
+
+	mp := make(M, len(src)-dropped)
+	for key, val := range src {
+		if keep(key) {
+			mp[key] = val
+		}
+	}
+	return mp
+}
+
+// isEmptyValue check the value is empty: nil, "", zero-length slice, map, array.
+// the zero number and false are empty on zeroNum, zeroBool is true.
+func isEmptyValue(val any, zeroNum, zeroBool bool) bool {
+	if val == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		// the nil slice, map has zero length
+		return rv.Len() == 0
+	case reflect.Pointer, reflect.Interface, reflect.Func, reflect.Chan:
+		return rv.IsNil()
+	case reflect.Bool:
+		return zeroBool && !rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return zeroNum && rv.IsZero()
+	}
+	return false
+}
+
+// flattenMap flatten the nested M or map[string]any values to top-level keys.
+// returns false if there is no nested map, the src will not be modified.
+func flattenMap(src map[string]any, sep string) (map[string]any, bool) {
+	var nested bool
+	for _, v := range src {
+		if _, ok := asStringMap(v); ok {
+			nested = true
+			break
+		}
+	}
+	if !nested {
+		return src, false
+	}
+
+	dst := make(map[string]any, len(src)*2)
+	flattenInto(dst, "", src, sep)
+	return dst, true
+}
+
+func flattenInto(dst map[string]any, prefix string, src map[string]any, sep string) {
+	for k, v := range src {
+		if prefix != "" {
+			k = prefix + sep + k
+		}
+
+		if sub, ok := asStringMap(v); ok {
+			flattenInto(dst, k, sub, sep)
+		} else {
+			dst[k] = v
+		}
+	}
+}
+
+func asStringMap(v any) (map[string]any, bool) {
+	switch typVal := v.(type) {
+	case M:
+		return typVal, true
+	case map[string]any:
+		return typVal, true
+	}
+	return nil, false
+}
+
+// readonly empty map, for render nil map as "{}"
+var emptyM = M{}
+
+func orEmptyM(mp M) M {
+	if mp == nil {
+		return emptyM
+	}
+	return mp
+}
+
+// isTerminal check the writer is a terminal(char device).
+func isTerminal(w io.Writer) bool {
+	st, ok := w.(interface{ Stat() (os.FileInfo, error) })
+	if !ok {
+		return false
+	}
+
+	fi, err := st.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// slogPkgPrefix the package func name prefix. eg: "github.com/gookit/slog."
+// goroutineID parse the current goroutine ID from runtime.Stack(). eg: "goroutine 18 [running]:"
+func goroutineID() uint64 {
+	var buf [64]byte
+	bs := buf[:runtime.Stack(buf[:], false)]
+	bs = bytes.TrimPrefix(bs, []byte("goroutine "))
+	if i := bytes.IndexByte(bs, ' '); i > 0 {
+		bs = bs[:i]
+	}
+
+	id, _ := strconv.ParseUint(string(bs), 10, 64)
+	return id
+}
+
+var slogPkgPrefix = reflect.TypeOf(Record{}).PkgPath() + "."
+
+// getStackTrace build the stack trace string, skip the leading runtime and slog frames.
+func getStackTrace(maxDepth int) string {
+	pcs := make([]uintptr, maxDepth+16)
+	num := runtime.Callers(2, pcs)
+	if num < 1 {
+		return ""
+	}
+
+	var depth int
+	var sb strings.Builder
+	frames := runtime.CallersFrames(pcs[:num])
+	for {
+		f, more := frames.Next()
+		// skip leading internal frames
+		if sb.Len() == 0 && (strings.HasPrefix(f.Function, slogPkgPrefix) || strings.HasPrefix(f.Function, "runtime.")) {
+			if !more {
+				break
+			}
+			continue
+		}
+
+		sb.WriteString(f.Function)
+		sb.WriteString("\n\t")
+		sb.WriteString(f.File)
+		sb.WriteByte(':')
+		sb.WriteString(strconv.Itoa(f.Line))
+		sb.WriteByte('\n')
+
+		depth++
+		if !more || depth >= maxDepth {
+			break
+		}
+	}
+	return sb.String()
+}
+
 // getCaller retrieves the name of the first non-slog calling function
 func getCaller(callerSkip int) (fr runtime.Frame, ok bool) {
 	pcs := make([]uintptr, 1) // alloc 1 times
@@ -68,6 +236,8 @@ func formatCaller(rf *runtime.Frame, flag uint8) (cs string) {
 	case CallerFlagFcName:
 		ss := strings.Split(rf.Function, ".")
 		return ss[len(ss)-1]
+	case CallerFlagPkgFile:
+		return path.Base(path.Dir(rf.File)) + "/" + path.Base(rf.File) + ":" + lineNum
 	default: // CallerFlagFpLine
 		return rf.File + ":" + lineNum
 	}
@@ -104,14 +274,102 @@ func formatArgsWithSpaces(vs []any) string {
 	// return byteutil.String(bb.B) // perf: Reduce one memory allocation
 }
 
-// EncodeToString data to string
+// EncodeToString data to string.
+// the fmt.Stringer and encoding.TextMarshaler value is encoded by String(), MarshalText().
 func EncodeToString(v any) string {
 	if mp, ok := v.(map[string]any); ok {
 		return mapToString(mp)
 	}
+	return anyToString(v)
+}
+
+// anyToString convert the value to string, use the MarshalText() for the encoding.TextMarshaler value.
+// the fmt.Stringer value is preferred, it is handled by strutil.SafeString
+func anyToString(v any) string {
+	if _, ok := v.(fmt.Stringer); !ok {
+		if tm, ok := v.(encoding.TextMarshaler); ok && !isNilPointer(v) {
+			if bs, err := tm.MarshalText(); err == nil {
+				return string(bs)
+			}
+		}
+	}
 	return strutil.SafeString(v)
 }
 
+func isNilPointer(v any) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+// enumValue convert enum-like value to the String() name or the number value.
+//
+// enum-like value: the kind is int, uint or float, and implements fmt.Stringer
+func enumValue(v any, asNumber bool) (any, bool) {
+	sv, ok := v.(fmt.Stringer)
+	if !ok {
+		return v, false
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if asNumber {
+			return rv.Int(), true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if asNumber {
+			return rv.Uint(), true
+		}
+	case reflect.Float32, reflect.Float64:
+		if asNumber {
+			return rv.Float(), true
+		}
+	default:
+		return v, false
+	}
+
+	// keep custom JSON marshal logic
+	if _, ok = v.(json.Marshaler); ok {
+		return v, false
+	}
+	return sv.String(), true
+}
+
+// convEnums convert enum-like values in the map. returns a new map if has changed.
+func convEnums(mp map[string]any, asNumber bool) (map[string]any, bool) {
+	var nmp map[string]any
+	for k, v := range mp {
+		var nv any
+		var changed bool
+
+		switch tv := v.(type) {
+		case M:
+			nv, changed = convEnums(tv, asNumber)
+			nv = M(nv.(map[string]any))
+		case map[string]any:
+			nv, changed = convEnums(tv, asNumber)
+		default:
+			nv, changed = enumValue(v, asNumber)
+		}
+
+		if !changed {
+			continue
+		}
+		if nmp == nil {
+			nmp = make(map[string]any, len(mp))
+			for k1, v1 := range mp {
+				nmp[k1] = v1
+			}
+		}
+		nmp[k] = nv
+	}
+
+	if nmp == nil {
+		return mp, false
+	}
+	return nmp, true
+}
+
 func mapToString(mp map[string]any) string {
 	ln := len(mp)
 	if ln == 0 {
@@ -126,8 +384,7 @@ func mapToString(mp map[string]any) string {
 		buf = append(buf, k...)
 		buf = append(buf, ':')
 
-		str, _ := strutil.AnyToString(val, false)
-		buf = append(buf, str...)
+		buf = append(buf, anyToString(val)...)
 		buf = append(buf, ',', ' ')
 	}
 
@@ -156,6 +413,84 @@ func parseTemplateToFields(tplStr string) []string {
 	return vars
 }
 
+// append the formatted time by layout. see TimeFormatUnix, TimeFormatUnixMilli
+func appendTime(b []byte, t time.Time, layout string) []byte {
+	switch layout {
+	case TimeFormatUnix:
+		return strconv.AppendInt(b, t.Unix(), 10)
+	case TimeFormatUnixMilli:
+		return strconv.AppendInt(b, t.UnixMilli(), 10)
+	}
+	return t.AppendFormat(b, layout)
+}
+
+// get the formatted time value by layout. the unix timestamp is returned as int64.
+func timeValue(t time.Time, layout string) any {
+	switch layout {
+	case TimeFormatUnix:
+		return t.Unix()
+	case TimeFormatUnixMilli:
+		return t.UnixMilli()
+	}
+	return t.Format(layout)
+}
+
+// replace the trailing newline("\n" or "\r\n") of the bs by the lineEnding, empty lineEnding is "\n".
+// remove the trailing newline on noEnding is true. the bs without trailing newline is not changed.
+func replaceLineEnding(bs []byte, lineEnding string, noEnding bool) []byte {
+	if !bytes.HasSuffix(bs, []byte{'\n'}) {
+		return bs
+	}
+	if !noEnding && (lineEnding == "" || lineEnding == "\n") {
+		return bs
+	}
+
+	bs = bytes.TrimSuffix(bs[:len(bs)-1], []byte{'\r'})
+	if noEnding {
+		return bs
+	}
+	return append(bs, lineEnding...)
+}
+
+// render the message template, the placeholder "{name}" is replaced by args["name"].
+// "{{" and "}}" are the escaped braces.
+func renderMsgTemplate(tpl string, args M, missingMark bool) string {
+	if strings.IndexByte(tpl, '{') < 0 && strings.IndexByte(tpl, '}') < 0 {
+		return tpl
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(tpl) + 16)
+	for i := 0; i < len(tpl); i++ {
+		c := tpl[i]
+		if (c == '{' || c == '}') && i+1 < len(tpl) && tpl[i+1] == c {
+			sb.WriteByte(c)
+			i++
+			continue
+		}
+
+		end := -1
+		if c == '{' {
+			end = strings.IndexByte(tpl[i+1:], '}')
+		}
+		if end <= 0 {
+			sb.WriteByte(c)
+			continue
+		}
+
+		name := tpl[i+1 : i+1+end]
+		if val, ok := args[name]; ok {
+			sb.WriteString(anyToString(val))
+		} else if missingMark {
+			sb.WriteString("<" + name + ">")
+		} else {
+			sb.WriteString(tpl[i : i+end+2])
+		}
+		i += end + 1
+	}
+	return sb.String()
+}
+
 func printlnStderr(args ...any) {
 	_, _ = fmt.Fprintln(os.Stderr, args...)
 }
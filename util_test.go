@@ -1,6 +1,7 @@
 package slog
 
 import (
+	"runtime"
 	"strings"
 	"testing"
 
@@ -9,6 +10,28 @@ import (
 	"github.com/gookit/goutil/timex"
 )
 
+// SnapshotLevels backup the level globals, returns the func for restore them. for the tests of RegisterLevel()
+func SnapshotLevels() (restore func()) {
+	all := append(Levels(nil), AllLevels...)
+	names := make(map[Level]string, len(LevelNames))
+	for lv, name := range LevelNames {
+		names[lv] = name
+	}
+	lowers := make(map[Level]string, len(lowerLevelNames))
+	for lv, name := range lowerLevelNames {
+		lowers[lv] = name
+	}
+	customs := make(map[string]Level, len(customLevels))
+	for name, lv := range customLevels {
+		customs[name] = lv
+	}
+
+	return func() {
+		AllLevels, LevelNames = all, names
+		lowerLevelNames, customLevels = lowers, customs
+	}
+}
+
 func revertTemplateString(ss []string) string {
 	var sb strings.Builder
 	for _, s := range ss {
@@ -74,3 +97,27 @@ func TestUtil_formatArgsWithSpaces(t *testing.T) {
 
 	assert.NotEmpty(t, formatArgsWithSpaces([]any{timex.Now().T()}))
 }
+
+func TestUtil_formatCaller(t *testing.T) {
+	rf := &runtime.Frame{
+		Function: "github.com/gookit/slog_test.TestLogger_ReportCaller",
+		File:     "/work/go/gookit/slog/logger_test.go",
+		Line:     48,
+	}
+
+	tests := map[uint8]string{
+		CallerFlagFnlFcn:  "logger_test.go:48,TestLogger_ReportCaller",
+		CallerFlagFull:    "github.com/gookit/slog_test.TestLogger_ReportCaller,logger_test.go:48",
+		CallerFlagFunc:    "github.com/gookit/slog_test.TestLogger_ReportCaller",
+		CallerFlagFcLine:  "github.com/gookit/slog_test.TestLogger_ReportCaller:48",
+		CallerFlagPkg:     "github.com/gookit/slog_test",
+		CallerFlagPkgFnl:  "github.com/gookit/slog_test,logger_test.go:48",
+		CallerFlagFpLine:  "/work/go/gookit/slog/logger_test.go:48",
+		CallerFlagFnLine:  "logger_test.go:48",
+		CallerFlagFcName:  "TestLogger_ReportCaller",
+		CallerFlagPkgFile: "slog/logger_test.go:48",
+	}
+	for flag, want := range tests {
+		assert.Eq(t, want, formatCaller(rf, flag))
+	}
+}